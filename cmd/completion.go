@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate a shell completion script",
+	Long: `Generate a shell completion script for gosible.
+
+To load completions in the current bash session:
+
+  source <(gosible completion bash)
+
+For zsh, fish and powershell, write the output to the shell's completion directory.`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+	RunE:      generateCompletion,
+}
+
+func generateCompletion(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	switch args[0] {
+	case "bash":
+		return cmd.Root().GenBashCompletionV2(out, true)
+	case "zsh":
+		return cmd.Root().GenZshCompletion(out)
+	case "fish":
+		return cmd.Root().GenFishCompletion(out, true)
+	case "powershell":
+		return cmd.Root().GenPowerShellCompletionWithDesc(out)
+	}
+	return cmd.Help()
+}
+
+// completeYAMLFiles suggests *.yml/*.yaml files and directories matching
+// the word being completed
+func completeYAMLFiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	matches, err := filepath.Glob(toComplete + "*")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var suggestions []string
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			suggestions = append(suggestions, match+string(filepath.Separator))
+			continue
+		}
+		if ext := strings.ToLower(filepath.Ext(match)); ext == ".yml" || ext == ".yaml" {
+			suggestions = append(suggestions, match)
+		}
+	}
+	// NoSpace lets the user keep completing inside a suggested directory
+	return suggestions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
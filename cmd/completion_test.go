@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// ✅ Test that the completion command emits a bash script
+func TestCompletion_Bash(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"completion", "bash"})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Completion failed: %v", err)
+	}
+	if out.Len() == 0 {
+		t.Fatal("Expected a bash completion script, got no output")
+	}
+	if !strings.Contains(out.String(), "bash completion") {
+		t.Errorf("Output doesn't look like a bash completion script:\n%.200s", out.String())
+	}
+}
+
+// ✅ Test that only YAML files and directories are suggested
+func TestCompleteYAMLFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"site.yml", "web.yaml", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "roles"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, _ := completeYAMLFiles(runCmd, nil, dir+string(filepath.Separator))
+	sort.Strings(suggestions)
+
+	expected := []string{
+		filepath.Join(dir, "roles") + string(filepath.Separator),
+		filepath.Join(dir, "site.yml"),
+		filepath.Join(dir, "web.yaml"),
+	}
+	if strings.Join(suggestions, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, suggestions)
+	}
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bxtal-lsn/gosible/internal/executor"
+	"github.com/bxtal-lsn/gosible/internal/inventory"
+)
+
+// ✅ Explain in plain words what a run would do, for --describe
+// Only reads the inventory; nothing is executed
+func describeRun(inventoryFile string, playbooks []string) string {
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		b.WriteString("   " + fmt.Sprintf(format, args...) + "\n")
+	}
+
+	b.WriteString("📋 This run would:\n")
+	line("Run %d playbook(s) in order: %s", len(playbooks), strings.Join(playbooks, ", "))
+	line("Against inventory %s (%s)", inventoryFile, describeHosts(inventoryFile))
+	line("Mode: %s", describeMode())
+
+	if runOpts.limit != "" {
+		line("Only on hosts matching: %s", runOpts.limit)
+	}
+	if runOpts.limitFailed {
+		line("Only on the hosts in each playbook's .retry file")
+	}
+	if runOpts.tags != "" {
+		line("Only tasks tagged: %s", runOpts.tags)
+	}
+	if len(runOpts.extraVars) > 0 {
+		line("With extra vars: %s", strings.Join(runOpts.extraVars, " "))
+	}
+	if runOpts.become {
+		line("Becoming %s with %s", orDefault(runOpts.becomeUser, "root"), orDefault(runOpts.becomeMethod, "sudo"))
+	}
+	if runOpts.remoteUser != "" {
+		line("Connecting as %s", runOpts.remoteUser)
+	}
+	switch hostKeyChecking() {
+	case executor.HostKeyCheckingOn:
+		line("Checking SSH host keys")
+	case executor.HostKeyCheckingOff:
+		line("Not checking SSH host keys")
+	}
+	if runOpts.forks > 0 {
+		line("With %d parallel forks", runOpts.forks)
+	}
+	if runOpts.serial != "" {
+		line("In rolling batches of %s (plays using gosible_serial)", runOpts.serial)
+	}
+	if runOpts.maxFailSet {
+		line("Aborting a play once more than %d%% of its hosts fail (plays using gosible_max_fail_percentage)", runOpts.maxFailPct)
+	}
+	if runOpts.keepGoing {
+		line("Running every playbook even if one fails")
+	} else if len(playbooks) > 1 {
+		line("Stopping at the first playbook that fails")
+	}
+	if len(runOpts.passthrough) > 0 {
+		line("Passing to ansible-playbook: %s", strings.Join(runOpts.passthrough, " "))
+	}
+	b.WriteString("🛑 Nothing was run (--describe).\n")
+	return b.String()
+}
+
+// ✅ Count and name the inventory's hosts
+func describeHosts(inventoryFile string) string {
+	if inventory.IsExecutable(inventoryFile) {
+		return "a dynamic inventory script, hosts are listed when it runs"
+	}
+	inv, err := inventory.LoadInventory(inventoryFile)
+	if err != nil {
+		return fmt.Sprintf("could not be read: %v", err)
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	for _, host := range inv.Hosts {
+		if !seen[host.Host] {
+			seen[host.Host] = true
+			names = append(names, host.Host)
+		}
+	}
+	if len(names) == 0 {
+		return "no hosts"
+	}
+	return fmt.Sprintf("%d host(s): %s", len(names), strings.Join(names, ", "))
+}
+
+// ✅ Whether the run would change anything, following the same rules as runPlaybooks
+func describeMode() string {
+	switch {
+	case !runOpts.applyAllowed():
+		return "dry run only (--require-confirm-apply without --apply)"
+	case runOpts.checkAndApply:
+		return "dry run every playbook, then apply them if every check passes"
+	case runOpts.apply:
+		return "apply changes"
+	case runOpts.dryRun:
+		return "dry run (check mode, nothing is changed)"
+	case runOpts.nonInteractive():
+		return "apply changes"
+	default:
+		return "you'd be asked whether to dry run first"
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ✅ Test that --describe lists the hosts and options and runs nothing
+func TestRunPlaybooks_Describe(t *testing.T) {
+	calls := stubExecutor(t)
+	path := filepath.Join(t.TempDir(), "inv.yml")
+	content := "all:\n  hosts:\n    web1:\n  children:\n    web:\n      hosts:\n        web1:\n        web2:\n    db:\n      hosts:\n        db1:\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runOpts = runOptions{inventory: path, playbooks: []string{"site.yml", "db.yml"}, tags: "deploy", limit: "web", become: true, dryRun: true, describe: true}
+	defer func() { runOpts = runOptions{} }()
+
+	description := describeRun(path, runOpts.playbooks)
+	for _, expected := range []string{
+		"Run 2 playbook(s) in order: site.yml, db.yml",
+		"(3 host(s): web1, web2, db1)",
+		"Mode: dry run",
+		"Only on hosts matching: web",
+		"Only tasks tagged: deploy",
+		"Becoming root with sudo",
+		"Stopping at the first playbook that fails",
+	} {
+		if !strings.Contains(description, expected) {
+			t.Errorf("Expected %q in the description:\n%s", expected, description)
+		}
+	}
+
+	if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err != nil {
+		t.Fatalf("runPlaybooks returned error: %v", err)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("Expected nothing to run, got %+v", *calls)
+	}
+}
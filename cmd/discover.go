@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/bxtal-lsn/gosible/internal/inventory"
+	"github.com/bxtal-lsn/gosible/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "List running Multipass, Docker, Vagrant and LXD instances without prompting",
+	Args:  cobra.NoArgs,
+	Run:   showDiscovered,
+}
+
+// discoverOptions holds the flags accepted by the discover command
+type discoverOptions struct {
+	json   bool
+	verify bool
+}
+
+var discoverOpts discoverOptions
+
+// ✅ Allow overriding discovery for testing
+var discoverByProvider = inventory.DiscoverByProvider
+
+func showDiscovered(cmd *cobra.Command, args []string) {
+	var instances []inventory.Instance
+	failed := false
+	for _, status := range discoverByProvider() {
+		if status.Err != nil {
+			output.Warnf("⚠️ %v\n", status.Err)
+			failed = true
+		}
+		instances = append(instances, status.Instances...)
+	}
+	if discoverOpts.verify {
+		instances = inventory.VerifyInstances(instances)
+	}
+
+	if discoverOpts.json {
+		if instances == nil {
+			instances = []inventory.Instance{} // [] rather than null for jq
+		}
+		data, _ := json.MarshalIndent(instances, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	} else if len(instances) > 0 {
+		printInstances(cmd, instances)
+	} else {
+		output.Warnf("⚠️ No running instances found.\n")
+	}
+
+	// Partial results are still worth printing, but nothing found and a
+	// broken provider shouldn't look like an empty fleet
+	if failed && len(instances) == 0 {
+		os.Exit(1)
+	}
+}
+
+// ✅ Print instances as an aligned table, with - for missing values
+func printInstances(cmd *cobra.Command, instances []inventory.Instance) {
+	tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Name\tAddress\tSource\tState")
+	for _, instance := range instances {
+		state := orDefault(instance.State, "-")
+		if instance.Unreachable != "" {
+			state += " (unreachable: " + instance.Unreachable + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", instance.Name, orDefault(instance.Address, "-"), instance.Source, state)
+	}
+	tw.Flush()
+}
+
+func init() {
+	flags := discoverCmd.Flags()
+	flags.BoolVar(&discoverOpts.json, "json", false, "Print the instances as a JSON array of name, address, source and state")
+	flags.BoolVar(&discoverOpts.verify, "verify", false, "Check that the instances are reachable and mark the ones that aren't")
+	rootCmd.AddCommand(discoverCmd)
+}
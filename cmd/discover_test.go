@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/bxtal-lsn/gosible/internal/inventory"
+)
+
+// ✅ Stub discovery with fixed provider results
+func stubDiscovery(t *testing.T, statuses []inventory.ProviderStatus) {
+	t.Helper()
+	oldDiscover := discoverByProvider
+	discoverByProvider = func() []inventory.ProviderStatus { return statuses }
+	t.Cleanup(func() { discoverByProvider = oldDiscover })
+}
+
+// ✅ Run discover with args, returning what it printed to stdout
+func runDiscover(t *testing.T, args ...string) string {
+	t.Helper()
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs(append([]string{"discover"}, args...))
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		discoverOpts = discoverOptions{}
+	}()
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("discover failed: %v", err)
+	}
+	return out.String()
+}
+
+// ✅ Test that --json prints every discovered instance with its fields
+func TestDiscover_JSON(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	stubDiscovery(t, []inventory.ProviderStatus{
+		{Provider: inventory.SourceMultipass, Installed: true, Instances: []inventory.Instance{
+			{Name: "web-1", Source: inventory.SourceMultipass, State: "Running", Address: "10.0.0.5"},
+		}},
+		{Provider: inventory.SourceDocker, Installed: true, Err: errors.New("docker: daemon not running")},
+	})
+
+	var instances []map[string]string
+	var out string
+	captureStderr(t, func() { out = runDiscover(t, "--json") })
+	if err := json.Unmarshal([]byte(out), &instances); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out, err)
+	}
+
+	expected := map[string]string{"name": "web-1", "address": "10.0.0.5", "source": "multipass", "state": "Running"}
+	if len(instances) != 1 {
+		t.Fatalf("Expected one instance, got %v", instances)
+	}
+	for key, value := range expected {
+		if instances[0][key] != value {
+			t.Errorf("Expected %s=%q, got %v", key, value, instances[0])
+		}
+	}
+}
+
+// ✅ Test that no instances print an empty JSON array, and a table otherwise
+func TestDiscover_EmptyAndTable(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	stubDiscovery(t, nil)
+	if out := runDiscover(t, "--json"); out != "[]\n" {
+		t.Errorf("Expected an empty array, got %q", out)
+	}
+
+	stubDiscovery(t, []inventory.ProviderStatus{
+		{Provider: inventory.SourceDocker, Installed: true, Instances: []inventory.Instance{
+			{Name: "app", Source: inventory.SourceDocker, State: "running", ConnectionType: inventory.ConnectionDocker},
+		}},
+	})
+	expected := "Name  Address  Source  State\napp   -        docker  running\n"
+	if out := runDiscover(t); out != expected {
+		t.Errorf("Expected table:\n%s\ngot:\n%s", expected, out)
+	}
+}
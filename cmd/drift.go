@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/bxtal-lsn/gosible/internal/executor"
+	"github.com/bxtal-lsn/gosible/internal/output"
+)
+
+// ✅ changeTracker remembers the check-mode runs whose recap reported changes
+type changeTracker struct {
+	changed []string
+}
+
+// ✅ Wrap a playbook executor so changes reported by dry runs are tracked
+// Applying runs are expected to change things and aren't tracked
+func (c *changeTracker) track(run func(executor.Options) error) func(executor.Options) error {
+	return func(opts executor.Options) error {
+		if opts.DryRun && !opts.SyntaxCheck {
+			playbook, onRecap := opts.Playbook, opts.OnRecap
+			opts.OnRecap = func(recap executor.Recap) {
+				if recap.Changed() > 0 {
+					c.changed = append(c.changed, playbook)
+				}
+				if onRecap != nil {
+					onRecap(recap)
+				}
+			}
+		}
+		return run(opts)
+	}
+}
+
+// ✅ An error naming the playbooks that would change something, if any
+func (c *changeTracker) err() error {
+	if len(c.changed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("check mode reported changes in %s (--fail-on-change)", strings.Join(c.changed, ", "))
+}
+
+// ✅ Run the playbooks, failing when --fail-on-change is set and a dry run
+// reported changed tasks
+func runPlaybooksFailOnChange(reader *bufio.Reader) error {
+	if !runOpts.failOnChange {
+		return runPlaybooksWithReport(reader)
+	}
+
+	tracker := &changeTracker{}
+	oldExecutePlaybook := executePlaybook
+	executePlaybook = tracker.track(oldExecutePlaybook)
+	defer func() { executePlaybook = oldExecutePlaybook }()
+
+	if err := runPlaybooksWithReport(reader); err != nil {
+		return err
+	}
+	if err := tracker.err(); err != nil {
+		output.Errorf("❌ %v\n", err)
+		return err
+	}
+	return nil
+}
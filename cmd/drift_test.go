@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/bxtal-lsn/gosible/internal/executor"
+)
+
+// ✅ Test that --fail-on-change fails a dry run reporting changes and passes a clean one
+func TestRunPlaybooksFailOnChange(t *testing.T) {
+	recaps := map[string]string{
+		"clean.yml":   "PLAY RECAP ***\nweb1 : ok=3 changed=0 unreachable=0 failed=0\n",
+		"drifted.yml": "PLAY RECAP ***\nweb1 : ok=3 changed=2 unreachable=0 failed=0\n",
+	}
+	stubExecutorWith(t, func(opts executor.Options) error {
+		opts.OnRecap(executor.ParseRecap(recaps[opts.Playbook]))
+		return nil
+	})
+	defer func() { runOpts = runOptions{} }()
+
+	tests := []struct {
+		playbook string
+		dryRun   bool
+		wantErr  bool
+	}{
+		{"clean.yml", true, false},
+		{"drifted.yml", true, true},
+		{"drifted.yml", false, false}, // Applying is expected to change things
+	}
+	for _, tt := range tests {
+		runOpts = runOptions{inventory: "inv.yml", playbooks: []string{tt.playbook}, dryRun: tt.dryRun, yes: true, failOnChange: true}
+		var err error
+		captureStderr(t, func() {
+			err = runPlaybooksFailOnChange(bufio.NewReader(strings.NewReader("")))
+		})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s (dry run %t): expected error %t, got %v", tt.playbook, tt.dryRun, tt.wantErr, err)
+		}
+	}
+}
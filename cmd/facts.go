@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bxtal-lsn/gosible/internal/executor"
+	"github.com/bxtal-lsn/gosible/internal/inventory"
+	"github.com/bxtal-lsn/gosible/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var factsCmd = &cobra.Command{
+	Use:   "facts",
+	Short: "Gather and display ansible facts for hosts",
+	Args:  cobra.NoArgs,
+	Run:   showFacts,
+}
+
+// factsOptions holds the flags accepted by the facts command
+type factsOptions struct {
+	inventory string
+	host      string
+	filter    string
+	raw       bool
+}
+
+var factsOpts factsOptions
+
+// ✅ Allow overriding fact gathering for testing
+var gatherFacts = executor.GatherFacts
+
+func showFacts(cmd *cobra.Command, args []string) {
+	out, err := gatherFacts(factsOpts.inventory, factsOpts.host, factsOpts.filter)
+	if err != nil {
+		output.Errorf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	failures := executor.FactFailures(out)
+	facts, err := executor.ParseFacts(out)
+	if err != nil && len(failures) == 0 {
+		// Show whatever ansible printed rather than nothing
+		fmt.Fprint(cmd.OutOrStdout(), string(out))
+		output.Errorf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(facts) > 0 {
+		if factsOpts.raw {
+			data, _ := json.MarshalIndent(facts, "", "  ")
+			fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		} else {
+			printFacts(facts)
+		}
+	}
+	if len(failures) > 0 {
+		printFactFailures(failures)
+		os.Exit(1)
+	}
+}
+
+// ✅ Report the hosts that returned no facts and why, on stderr
+func printFactFailures(failures []executor.PingResult) {
+	for _, failure := range failures {
+		details, _, _ := strings.Cut(failure.Message, "\n")
+		output.Errorf("❌ %s: %s (%s)\n", failure.Host, failure.Reason, orDefault(details, "no details"))
+	}
+	output.Errorf("❌ No facts from %d host(s)\n", len(failures))
+}
+
+// ✅ Print facts per host as sorted `name: value` lines
+func printFacts(facts map[string]map[string]any) {
+	hosts := make([]string, 0, len(facts))
+	for host := range facts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	for _, host := range hosts {
+		output.Printf("\n🖥️ %s\n", host)
+		names := make([]string, 0, len(facts[host]))
+		for name := range facts[host] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			output.Printf("  %s: %s\n", name, formatFact(facts[host][name]))
+		}
+	}
+}
+
+// ✅ Strings are printed as-is, everything else as compact JSON
+func formatFact(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func init() {
+	flags := factsCmd.Flags()
+	flags.StringVarP(&factsOpts.inventory, "inventory", "i", inventory.DefaultInventoryFilename, "Inventory file or dynamic inventory script")
+	flags.StringVar(&factsOpts.host, "host", "all", "Host or pattern to gather facts from")
+	flags.StringVar(&factsOpts.filter, "filter", "", "Only show facts matching this pattern, e.g. ansible_distribution*")
+	flags.BoolVar(&factsOpts.raw, "raw", false, "Print the facts as JSON")
+	factsCmd.RegisterFlagCompletionFunc("inventory", completeYAMLFiles)
+	rootCmd.AddCommand(factsCmd)
+}
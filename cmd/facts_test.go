@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// ✅ Test that facts are formatted for display
+func TestFormatFact(t *testing.T) {
+	var value any
+	json.Unmarshal([]byte(`{"address": "10.0.0.5", "mtu": 1500}`), &value)
+
+	for _, tc := range []struct {
+		value    any
+		expected string
+	}{
+		{"Ubuntu", "Ubuntu"},
+		{float64(2), "2"},
+		{[]any{"a", "b"}, `["a","b"]`},
+		{value, `{"address":"10.0.0.5","mtu":1500}`},
+	} {
+		if got := formatFact(tc.value); got != tc.expected {
+			t.Errorf("formatFact(%v) = %q, expected %q", tc.value, got, tc.expected)
+		}
+	}
+}
+
+// ✅ Test that --raw prints the parsed facts as JSON
+func TestFacts_Raw(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	var gotArgs []string
+	oldGatherFacts := gatherFacts
+	gatherFacts = func(inventory string, host string, filter string) ([]byte, error) {
+		gotArgs = []string{inventory, host, filter}
+		return []byte("web1 | SUCCESS => {\n    \"ansible_facts\": {\"ansible_distribution\": \"Ubuntu\"}\n}\n"), nil
+	}
+	defer func() { gatherFacts = oldGatherFacts }()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"facts", "-i", "hosts.yml", "--host", "web1", "--filter", "ansible_distribution*", "--raw"})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		factsOpts = factsOptions{}
+	}()
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("facts failed: %v", err)
+	}
+
+	if strings.Join(gotArgs, " ") != "hosts.yml web1 ansible_distribution*" {
+		t.Errorf("Unexpected gather arguments %q", gotArgs)
+	}
+	var facts map[string]map[string]string
+	if err := json.Unmarshal(out.Bytes(), &facts); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out.String(), err)
+	}
+	if facts["web1"]["ansible_distribution"] != "Ubuntu" {
+		t.Errorf("Unexpected facts %v", facts)
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/bxtal-lsn/gosible/internal/executor"
+	"github.com/spf13/cobra"
+)
+
+var galaxyCmd = &cobra.Command{
+	Use:   "galaxy",
+	Short: "Manage role and collection dependencies with ansible-galaxy",
+}
+
+var galaxyInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the roles or collections listed in a requirements file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runGalaxyInstall(galaxyRequirements, galaxyCollections); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var (
+	galaxyRequirements string
+	galaxyCollections  bool
+)
+
+// ✅ Allow overriding the galaxy runner for testing
+var runGalaxyInstall = executor.RunGalaxyInstall
+
+func init() {
+	flags := galaxyInstallCmd.Flags()
+	flags.StringVarP(&galaxyRequirements, "requirements", "r", executor.DefaultRequirementsFile, "Requirements file to install from")
+	flags.BoolVar(&galaxyCollections, "collections", false, "Install collections (ansible-galaxy collection install) instead of roles")
+
+	galaxyCmd.AddCommand(galaxyInstallCmd)
+	rootCmd.AddCommand(galaxyCmd)
+}
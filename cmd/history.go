@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/bxtal-lsn/gosible/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Manage the history of previous runs",
+}
+
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove history entries whose inventory or playbooks no longer exist",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		removed, err := pruneHistory()
+		if err != nil {
+			output.Errorf("❌ Could not prune command history: %v\n", err)
+			os.Exit(1)
+		}
+		output.Printf("✅ Removed %d stale history entries\n", removed)
+	},
+}
+
+// ✅ Report whether an entry references a file that has since been deleted
+func (e CommandHistoryEntry) stale() bool {
+	if _, err := os.Stat(e.InventoryFile); err != nil {
+		return true
+	}
+	for _, pb := range e.Playbooks {
+		if _, err := os.Stat(pb); err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ✅ Drop stale entries from the history file and return how many were removed
+// The file is left untouched when nothing is stale
+func pruneHistory() (int, error) {
+	entries, err := loadHistory()
+	if err != nil {
+		return 0, err
+	}
+
+	kept := make([]CommandHistoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.stale() {
+			kept = append(kept, entry)
+		}
+	}
+	removed := len(entries) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, saveHistory(kept)
+}
+
+func init() {
+	historyCmd.AddCommand(historyPruneCmd)
+	rootCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ✅ Test that pruning removes only entries referencing missing files
+func TestPruneHistory(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GOSIBLE_HISTORY_FILE", filepath.Join(dir, "history"))
+	inv := filepath.Join(dir, "inv.yml")
+	site := filepath.Join(dir, "site.yml")
+	for _, path := range []string{inv, site} {
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	present := CommandHistoryEntry{InventoryFile: inv, Playbooks: []string{site}}
+	entries := []CommandHistoryEntry{
+		{InventoryFile: filepath.Join(dir, "deleted.yml"), Playbooks: []string{site}},
+		present,
+		{InventoryFile: inv, Playbooks: []string{site, filepath.Join(dir, "gone.yml")}},
+	}
+	if err := saveHistory(entries); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := pruneHistory()
+	if err != nil {
+		t.Fatalf("pruneHistory returned error: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Expected 2 stale entries removed, got %d", removed)
+	}
+	remaining, err := loadHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(remaining, []CommandHistoryEntry{present}) {
+		t.Errorf("Expected only %+v to remain, got %+v", present, remaining)
+	}
+}
+
+// ✅ Test that a history file past --log-max-size is rotated to a gzipped copy
+func TestSaveHistory_Rotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	t.Setenv("GOSIBLE_HISTORY_FILE", path)
+	runOpts = runOptions{logMaxSize: "1"}
+	defer func() { runOpts = runOptions{} }()
+
+	first := []CommandHistoryEntry{{InventoryFile: "inv.yml", Playbooks: []string{"site.yml"}}}
+	second := append(first, CommandHistoryEntry{InventoryFile: "inv.yml", Playbooks: []string{"db.yml"}})
+	for _, entries := range [][]CommandHistoryEntry{first, second} {
+		if err := saveHistory(entries); err != nil {
+			t.Fatalf("saveHistory returned error: %v", err)
+		}
+	}
+
+	file, err := os.Open(path + ".1.gz")
+	if err != nil {
+		t.Fatalf("Expected a rotated history: %v", err)
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Expected the rotated history to be gzipped: %v", err)
+	}
+	rotated, _ := io.ReadAll(zr)
+	if !strings.Contains(string(rotated), "site.yml") || strings.Contains(string(rotated), "db.yml") {
+		t.Errorf("Expected the first history in the rotated copy, got %q", rotated)
+	}
+	if entries, err := loadHistory(); err != nil || !reflect.DeepEqual(entries, second) {
+		t.Errorf("Expected the fresh file to hold the saved entries, got %+v, %v", entries, err)
+	}
+}
+
+// ✅ Test that the history and its rotated copies are private to the user and
+// never hold secret-looking extra vars
+func TestSaveHistory_Private(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	t.Setenv("GOSIBLE_HISTORY_FILE", path)
+	if err := os.WriteFile(path, []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { runOpts = runOptions{} }()
+
+	runOpts = runOptions{extraVars: []string{"app=web db_password=hunter2", "@vars.yml", "api_token=abc"}, logMaxSize: "1"}
+	saveNewHistoryEntry("inv.yml", []string{"site.yml"}, true)
+	saveNewHistoryEntry("inv.yml", []string{"site.yml"}, true)
+
+	for _, file := range []string{path, path + ".1.gz"} {
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatalf("Expected %s to exist: %v", file, err)
+		}
+		if mode := info.Mode().Perm(); mode != 0o600 {
+			t.Errorf("Expected %s to be 0600, got %o", file, mode)
+		}
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "hunter2") || strings.Contains(string(data), "abc") {
+		t.Errorf("Expected secrets to stay out of history, got %s", data)
+	}
+	entries, err := loadHistory()
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("Expected saved entries, got %+v, %v", entries, err)
+	}
+	if got := entries[len(entries)-1].ExtraVars; !reflect.DeepEqual(got, []string{"app=web", "@vars.yml"}) {
+		t.Errorf("Expected only the non-secret vars to be kept, got %q", got)
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bxtal-lsn/gosible/internal/inventory"
+	"github.com/bxtal-lsn/gosible/internal/output"
+	"github.com/bxtal-lsn/gosible/internal/playbook"
+	"github.com/spf13/cobra"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init [directory]",
+	Short: "Scaffold an Ansible project layout with a starter playbook and inventory",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   initProject,
+}
+
+var initForce bool
+
+// ✅ Directories created by init
+var scaffoldDirs = []string{"inventories", playbook.DefaultDir, "group_vars", "host_vars"}
+
+const starterPlaybook = `---
+# Entry point: import the playbooks that make up your site
+- name: Check connectivity
+  hosts: all
+  gather_facts: false
+  tasks:
+    - name: Ping hosts
+      ansible.builtin.ping:
+`
+
+func initProject(cmd *cobra.Command, args []string) {
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	created, err := scaffoldProject(dir, initForce)
+	if err != nil {
+		output.Errorf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, path := range created {
+		output.Printf("📁 Created %s\n", path)
+	}
+	output.Printf("\n✅ Project ready. Try: gosible run -i %s -p %s --dry-run\n",
+		filepath.Join(dir, "inventories", inventory.DefaultInventoryFilename),
+		filepath.Join(dir, "site.yml"))
+}
+
+// ✅ Create the project layout in dir, returning the files written
+// Existing files are left alone unless force is set; nothing is written when
+// any of them would be replaced
+func scaffoldProject(dir string, force bool) ([]string, error) {
+	exampleInventory, err := inventory.RenderInventory([]inventory.HostConfig{
+		{Host: "localhost", Connection: inventory.ConnectionLocal},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		path    string
+		content string
+	}{
+		{filepath.Join(dir, "site.yml"), starterPlaybook},
+		{filepath.Join(dir, "inventories", inventory.DefaultInventoryFilename), exampleInventory},
+	}
+
+	if !force {
+		var existing []string
+		for _, file := range files {
+			if _, err := os.Stat(file.path); err == nil {
+				existing = append(existing, file.path)
+			}
+		}
+		if len(existing) > 0 {
+			return nil, fmt.Errorf("refusing to overwrite %s (use --force)", strings.Join(existing, ", "))
+		}
+	}
+
+	var created []string
+	for _, name := range scaffoldDirs {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			return created, fmt.Errorf("error creating directory: %w", err)
+		}
+		created = append(created, path+string(filepath.Separator))
+	}
+	for _, file := range files {
+		if err := os.WriteFile(file.path, []byte(file.content), 0o644); err != nil {
+			return created, fmt.Errorf("error writing %s: %w", file.path, err)
+		}
+		created = append(created, file.path)
+	}
+	return created, nil
+}
+
+func init() {
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite existing site.yml and inventory files")
+	rootCmd.AddCommand(initCmd)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bxtal-lsn/gosible/internal/inventory"
+)
+
+// ✅ Test that init creates the directories and starter files
+func TestScaffoldProject(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := scaffoldProject(dir, false); err != nil {
+		t.Fatalf("scaffoldProject returned error: %v", err)
+	}
+
+	for _, name := range scaffoldDirs {
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || !info.IsDir() {
+			t.Errorf("Expected directory %s to be created", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "site.yml")); err != nil {
+		t.Errorf("Expected site.yml to be created: %v", err)
+	}
+
+	inv, err := inventory.LoadInventory(filepath.Join(dir, "inventories", "inv.yml"))
+	if err != nil {
+		t.Fatalf("Expected a loadable example inventory: %v", err)
+	}
+	if len(inv.Hosts) != 1 || inv.Hosts[0].Host != "localhost" {
+		t.Errorf("Expected a localhost example host, got %+v", inv.Hosts)
+	}
+}
+
+// ✅ Test that existing files are only replaced with --force
+func TestScaffoldProject_Existing(t *testing.T) {
+	dir := t.TempDir()
+	site := filepath.Join(dir, "site.yml")
+	if err := os.WriteFile(site, []byte("# mine\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := scaffoldProject(dir, false)
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("Expected a refusal mentioning --force, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "playbooks")); err == nil {
+		t.Error("Expected nothing to be created after a refusal")
+	}
+
+	if _, err := scaffoldProject(dir, true); err != nil {
+		t.Fatalf("scaffoldProject with force returned error: %v", err)
+	}
+	if data, _ := os.ReadFile(site); string(data) != starterPlaybook {
+		t.Errorf("Expected site.yml to be replaced, got %q", data)
+	}
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bxtal-lsn/gosible/internal/inventory"
+	"github.com/bxtal-lsn/gosible/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Edit inventory files or print a sample one",
+}
+
+var inventoryAddCmd = &cobra.Command{
+	Use:   "add <host>...",
+	Short: "Add hosts to an existing inventory file",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   addInventoryHosts,
+}
+
+var inventoryRemoveCmd = &cobra.Command{
+	Use:   "remove <host>",
+	Short: "Remove a host from every group of an inventory file",
+	Args:  cobra.ExactArgs(1),
+	Run:   removeInventoryHost,
+}
+
+var inventoryExampleCmd = &cobra.Command{
+	Use:   "example",
+	Short: "Print a commented sample inventory showing groups, host vars and connection types",
+	Args:  cobra.NoArgs,
+	Run:   printInventoryExample,
+}
+
+// Inventory file edited by the inventory subcommands
+var editInventoryFile string
+
+// Settings applied to every host given to `inventory add`
+var addHost inventory.HostConfig
+
+// Extra host variables given to `inventory add` as key=value
+var addVars []string
+
+// Print the edited inventory instead of writing it
+var inventoryDryRun bool
+
+// Show the edit as a diff, and confirm it unless inventoryYes is set
+var inventoryDiff, inventoryYes bool
+
+// File `inventory example` writes the sample to instead of stdout
+var exampleOutput string
+
+func addInventoryHosts(cmd *cobra.Command, args []string) {
+	vars, err := parseHostVars(addVars)
+	if err != nil {
+		output.Errorf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	hosts := make([]inventory.HostConfig, 0, len(args))
+	for _, name := range args {
+		host := addHost
+		host.Host = name
+		host.Vars = vars
+		host.SSHKeyFile = inventory.ExpandHome(host.SSHKeyFile)
+		hosts = append(hosts, host)
+	}
+
+	content, err := inventory.RenderAppendHosts(editInventoryFile, hosts)
+	if writeInventoryEdit(cmd, content, err) {
+		output.Printf("✅ Added %d host(s) to %s\n", len(hosts), editInventoryFile)
+	}
+}
+
+// ✅ Turn key=value pairs into host variables
+func parseHostVars(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid --var %q: expected key=value", pair)
+		}
+		vars[strings.TrimSpace(key)] = value
+	}
+	return vars, nil
+}
+
+func removeInventoryHost(cmd *cobra.Command, args []string) {
+	content, err := inventory.RenderRemoveHost(editInventoryFile, args[0])
+	if writeInventoryEdit(cmd, content, err) {
+		output.Printf("✅ Removed %s from %s\n", args[0], editInventoryFile)
+	}
+}
+
+// ✅ Write the edited inventory content, reporting whether the file was written
+// A dry run prints the content instead, or with --diff only the changes. Without
+// --dry-run, --diff shows the changes and asks before writing unless --yes is set.
+// Printed content goes to stdout unaffected by --quiet so it can be redirected.
+func writeInventoryEdit(cmd *cobra.Command, content string, err error) bool {
+	if err != nil {
+		output.Errorf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	if inventoryDiff {
+		before, _ := os.ReadFile(editInventoryFile) // Already read to render the edit
+		diff := inventory.Diff(string(before), content, editInventoryFile, editInventoryFile+" (edited)")
+		if diff == "" {
+			output.Printf("✨ No changes to %s\n", editInventoryFile)
+			return false
+		}
+		fmt.Fprint(cmd.OutOrStdout(), colorDiff(diff))
+	}
+	if inventoryDryRun {
+		if !inventoryDiff {
+			fmt.Fprint(cmd.OutOrStdout(), content)
+		}
+		output.Warnf("🔍 Dry run, %s was not changed\n", editInventoryFile)
+		return false
+	}
+	if inventoryDiff && !inventoryYes {
+		output.Prompt(fmt.Sprintf("\n💾 Write these changes to %s? (yes/no)", editInventoryFile))
+		response, _ := readAnswer(bufio.NewReader(cmd.InOrStdin())) // Closed input counts as no
+		if strings.ToLower(response) != "yes" {
+			output.Warnf("🚫 %s was not changed\n", editInventoryFile)
+			return false
+		}
+	}
+
+	if err := inventory.OverwriteInventoryFile(editInventoryFile, content, false); err != nil {
+		output.Errorf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// ✅ ANSI colors for diff lines, by their first character
+var diffColors = map[byte]string{
+	'+': "\x1b[32m",
+	'-': "\x1b[31m",
+	'@': "\x1b[36m",
+}
+
+// ✅ Color added lines green, removed lines red and hunk headers cyan, unless
+// plain output is on
+func colorDiff(diff string) string {
+	if output.Plain() {
+		return diff
+	}
+	lines := strings.SplitAfter(diff, "\n")
+	for i, line := range lines {
+		if line == "" || strings.HasPrefix(line, "+++ ") || strings.HasPrefix(line, "--- ") {
+			continue // File headers stay plain, as in git
+		}
+		if color := diffColors[line[0]]; color != "" {
+			lines[i] = color + strings.TrimSuffix(line, "\n") + "\x1b[0m\n"
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// ✅ Print the sample inventory, or write it to a new file with --output
+// The content goes to stdout unaffected by --quiet so it can be redirected
+func printInventoryExample(cmd *cobra.Command, args []string) {
+	content := inventory.SampleInventory()
+	if exampleOutput == "" {
+		fmt.Fprint(cmd.OutOrStdout(), content)
+		return
+	}
+
+	// O_EXCL so an inventory that's already there is never replaced
+	file, err := os.OpenFile(exampleOutput, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err == nil {
+		_, err = file.WriteString(content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		output.Errorf("❌ Could not write the sample inventory: %v\n", err)
+		os.Exit(1)
+	}
+	output.Printf("✅ Sample inventory written to %s\n", exampleOutput)
+}
+
+func init() {
+	inventoryCmd.PersistentFlags().StringVarP(&editInventoryFile, "inventory", "i", inventory.DefaultInventoryFilename, "Inventory file to edit")
+	inventoryCmd.PersistentFlags().BoolVar(&inventoryDryRun, "dry-run", false, "Print the resulting inventory instead of writing it")
+	inventoryCmd.PersistentFlags().BoolVar(&inventoryDiff, "diff", false, "Show the changes as a colored diff and ask before writing them (with --dry-run, only show them)")
+	inventoryCmd.PersistentFlags().BoolVarP(&inventoryYes, "yes", "y", false, "Write the changes shown by --diff without asking")
+
+	flags := inventoryAddCmd.Flags()
+	flags.StringVarP(&addHost.Group, "group", "g", "", "Group to add the hosts to (ungrouped by default)")
+	flags.StringVar(&addHost.Address, "address", "", "Connection address (ansible_host) when it differs from the host name")
+	flags.StringVar(&addHost.SSHUser, "user", "", "SSH user (ansible_user)")
+	flags.StringVar(&addHost.SSHKeyFile, "key", "", "SSH private key file")
+	flags.StringVar(&addHost.SSHPort, "port", "", "SSH port")
+	flags.StringVar(&addHost.ProxyJump, "proxy-jump", "", "Bastion to reach the hosts through, as [user@]host[:port]")
+	flags.BoolVar(&addHost.Become, "become", false, "Enable become (sudo) for the hosts")
+	flags.StringVar(&addHost.BecomeUser, "become-user", "", "User to become on these hosts (with --become)")
+	flags.StringVar(&addHost.BecomeMethod, "become-method", "", "Escalation method on these hosts, e.g. sudo or doas (with --become)")
+	flags.StringArrayVar(&addVars, "var", nil, "Host variable as key=value, repeatable (values are always written as strings)")
+	flags.StringVar(&addHost.Connection, "connection", "", "Connection type: ssh, local, docker or winrm")
+	flags.StringVar(&addHost.WinRMTransport, "winrm-transport", "", "WinRM transport for Windows hosts, e.g. ntlm or kerberos (with --connection winrm)")
+
+	inventoryExampleCmd.Flags().StringVarP(&exampleOutput, "output", "o", "", "Write the sample to this new file instead of printing it")
+
+	inventoryCmd.AddCommand(inventoryAddCmd, inventoryRemoveCmd, inventoryExampleCmd)
+	rootCmd.AddCommand(inventoryCmd)
+}
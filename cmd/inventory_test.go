@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bxtal-lsn/gosible/internal/inventory"
+)
+
+// ✅ Test that `inventory add` appends hosts with the given settings
+func TestInventoryAdd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path, err := inventory.CreateInventoryFile(t.TempDir(), []inventory.HostConfig{{Host: "web1", Group: "web"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd.SetArgs([]string{"inventory", "add", "-i", path, "--group", "web", "--user", "ubuntu", "--var", "tier=yes", "web2", "web3"})
+	defer func() {
+		rootCmd.SetArgs(nil)
+		addHost = inventory.HostConfig{}
+		addVars = nil
+	}()
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("inventory add failed: %v", err)
+	}
+
+	inv, err := inventory.LoadInventory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []inventory.HostConfig{
+		{Host: "web1", Group: "web"},
+		{Host: "web2", Group: "web", SSHUser: "ubuntu", Vars: map[string]string{"tier": "yes"}},
+		{Host: "web3", Group: "web", SSHUser: "ubuntu", Vars: map[string]string{"tier": "yes"}},
+	}
+	if !reflect.DeepEqual(inv.Hosts, expected) {
+		t.Errorf("Expected hosts %+v, got %+v", expected, inv.Hosts)
+	}
+}
+
+// ✅ Test that `inventory remove` drops the host
+func TestInventoryRemove(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path, err := inventory.CreateInventoryFile(t.TempDir(), []inventory.HostConfig{{Host: "web1"}, {Host: "web2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd.SetArgs([]string{"inventory", "remove", "-i", path, "web1"})
+	defer rootCmd.SetArgs(nil)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("inventory remove failed: %v", err)
+	}
+
+	inv, err := inventory.LoadInventory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inv.Hosts) != 1 || inv.Hosts[0].Host != "web2" {
+		t.Errorf("Expected only web2 to remain, got %+v", inv.Hosts)
+	}
+}
+
+// ✅ Test that --dry-run prints the edited inventory and leaves the file alone
+func TestInventoryDryRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path, err := inventory.CreateInventoryFile(t.TempDir(), []inventory.HostConfig{{Host: "web1"}, {Host: "web2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+		addHost = inventory.HostConfig{}
+		inventoryDryRun = false
+	}()
+
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"inventory", "add", "--dry-run", "-i", path, "web3"}, "---\nall:\n  hosts:\n    web1:\n    web2:\n    web3:\n"},
+		{[]string{"inventory", "remove", "--dry-run", "-i", path, "web1"}, "---\nall:\n  hosts:\n    web2:\n"},
+	} {
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetArgs(tc.args)
+		captureStderr(t, func() {
+			if err := rootCmd.Execute(); err != nil {
+				t.Fatalf("%v failed: %v", tc.args, err)
+			}
+		})
+		if out.String() != tc.expected {
+			t.Errorf("Expected %v to print:\n%s\ngot:\n%s", tc.args, tc.expected, out.String())
+		}
+
+		current, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(current, original) {
+			t.Errorf("Expected %v to leave the file unchanged, got:\n%s", tc.args, current)
+		}
+		inventoryDryRun = false
+	}
+}
+
+// ✅ Test that --diff shows an added host as a diff, and writes it only once confirmed
+func TestInventoryDiff(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("NO_COLOR", "1")
+	path, err := inventory.CreateInventoryFile(t.TempDir(), []inventory.HostConfig{{Host: "web1"}, {Host: "web2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+		rootCmd.SetIn(nil)
+		inventoryDryRun, inventoryDiff = false, false
+	}()
+
+	expected := "--- " + path + "\n+++ " + path + " (edited)\n" +
+		"@@ -3,3 +3,4 @@\n   hosts:\n     web1:\n     web2:\n+    web3:\n"
+	for _, tc := range []struct {
+		args    []string
+		input   string
+		written bool
+	}{
+		{[]string{"inventory", "add", "--dry-run", "--diff", "-i", path, "web3"}, "", false},
+		{[]string{"inventory", "add", "--diff", "-i", path, "web3"}, "no\n", false},
+		{[]string{"inventory", "add", "--diff", "-i", path, "web3"}, "yes\n", true},
+	} {
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetIn(strings.NewReader(tc.input))
+		rootCmd.SetArgs(tc.args)
+		captureStderr(t, func() {
+			if err := rootCmd.Execute(); err != nil {
+				t.Fatalf("%v failed: %v", tc.args, err)
+			}
+		})
+		if out.String() != expected {
+			t.Errorf("Expected %v to print:\n%s\ngot:\n%s", tc.args, expected, out.String())
+		}
+
+		current, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if written := !bytes.Equal(current, original); written != tc.written {
+			t.Errorf("Expected %v with input %q to write the file: %t, got:\n%s", tc.args, tc.input, tc.written, current)
+		}
+		inventoryDryRun, inventoryDiff = false, false
+	}
+
+	// Removals are shown as removed lines
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"inventory", "remove", "--dry-run", "--diff", "-i", path, "web1"})
+	captureStderr(t, func() { rootCmd.Execute() })
+	if !strings.Contains(out.String(), "\n-    web1:\n") {
+		t.Errorf("Expected web1 to be shown as removed, got:\n%s", out.String())
+	}
+}
+
+// ✅ Test that `inventory example --output` writes a loadable sample once
+func TestInventoryExample_Output(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "sample.yml")
+	rootCmd.SetArgs([]string{"inventory", "example", "--output", path})
+	defer func() {
+		rootCmd.SetArgs(nil)
+		exampleOutput = ""
+	}()
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("inventory example failed: %v", err)
+	}
+
+	inv, err := inventory.LoadInventory(path)
+	if err != nil {
+		t.Fatalf("Sample inventory doesn't load: %v", err)
+	}
+	if len(inv.Hosts) == 0 {
+		t.Error("Expected hosts in the sample inventory")
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/bxtal-lsn/gosible/internal/executor"
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:               "lint <playbook>...",
+	Short:             "Check playbooks with ansible-lint",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeYAMLFiles,
+	Run:               lintPlaybooks,
+}
+
+var lintBin string
+
+// ✅ Allow overriding the lint runner for testing
+var runLint = executor.RunLint
+
+// ✅ Run ansible-lint and exit with its exit code
+// The binary defaults to ansible-lint-bin from the config
+func lintPlaybooks(cmd *cobra.Command, args []string) {
+	binary := lintBin
+	if !cmd.Flags().Changed("ansible-lint-bin") && cfg.AnsibleLintBin != "" {
+		binary = cfg.AnsibleLintBin
+	}
+
+	err := runLint(binary, args)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	} else if err != nil {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	lintCmd.Flags().StringVar(&lintBin, "ansible-lint-bin", executor.LintBinary, "ansible-lint executable to run")
+	rootCmd.AddCommand(lintCmd)
+}
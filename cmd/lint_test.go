@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bxtal-lsn/gosible/internal/config"
+)
+
+// ✅ Test that lint passes the playbooks to the configured ansible-lint
+func TestLintPlaybooks(t *testing.T) {
+	var binary string
+	var playbooks []string
+	oldRunLint := runLint
+	runLint = func(b string, p []string) error {
+		binary, playbooks = b, p
+		return nil
+	}
+	defer func() { runLint = oldRunLint }()
+	oldCfg := cfg
+	cfg = config.Config{AnsibleLintBin: "/opt/bin/ansible-lint"}
+	defer func() { cfg = oldCfg }()
+
+	lintPlaybooks(lintCmd, []string{"site.yml", "db.yml"})
+	if binary != "/opt/bin/ansible-lint" || !reflect.DeepEqual(playbooks, []string{"site.yml", "db.yml"}) {
+		t.Errorf("Expected the configured binary and both playbooks, got %s %q", binary, playbooks)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package cmd
+
+import "os"
+
+// ✅ Whether the current user owns the file described by info
+// Ownership isn't exposed here; temp dirs are per user on these systems anyway.
+func ownedByCurrentUser(info os.FileInfo) bool {
+	return true
+}
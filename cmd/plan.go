@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/bxtal-lsn/gosible/internal/config"
+	"github.com/bxtal-lsn/gosible/internal/executor"
+	"github.com/bxtal-lsn/gosible/internal/inventory"
+	"github.com/bxtal-lsn/gosible/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Preview the changes playbooks would make (check mode with diffs)",
+	Long: `Run playbooks with ansible's --check and --diff, so nothing is changed
+and every change that would be made is shown, like a Terraform plan.
+
+Tasks that can't run in check mode can be tagged no-check to be skipped
+(see --skip-check-tag).`,
+	Args: cobra.NoArgs,
+	Run:  planPlaybooks,
+}
+
+// planOptions holds the flags accepted by the plan command
+type planOptions struct {
+	inventory string
+	playbooks []string
+	tags      string
+	limit     string
+	extraVars []string
+
+	failOnChange bool
+	skipCheckTag string
+}
+
+var planOpts planOptions
+
+// ✅ Build the executor options for planning one playbook
+// Binary, forks and vault settings come from the config file
+func (o planOptions) executorOptions(playbook string) executor.Options {
+	return executor.Options{
+		Inventory: o.inventory,
+		Playbook:  playbook,
+		DryRun:    true,
+		Diff:      true,
+		Tags:      o.tags,
+		SkipTags:  o.skipCheckTag,
+		Limit:     o.limit,
+		ExtraVars: o.extraVars,
+
+		Binary:            cfg.AnsibleBin,
+		Forks:             cfg.Forks,
+		VaultPasswordFile: cfg.VaultPasswordFile,
+		OnRecap:           printRecapResult,
+	}
+}
+
+// ✅ Plan every playbook, exiting non-zero if any check fails, or with
+// --fail-on-change if any would change something
+func planPlaybooks(cmd *cobra.Command, args []string) {
+	if !cmd.Flags().Changed("skip-check-tag") {
+		planOpts.skipCheckTag = cfg.SkipCheckTag
+	}
+
+	tracker := &changeTracker{}
+	run := executePlaybook
+	if planOpts.failOnChange {
+		run = tracker.track(run)
+	}
+
+	failed := 0
+	for _, playbook := range planOpts.playbooks {
+		output.Printf("\n🔍 Planning playbook: %s using inventory: %s\n", playbook, planOpts.inventory)
+		if err := run(planOpts.executorOptions(playbook)); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		output.Errorf("❌ %d of %d playbook(s) could not be planned\n", failed, len(planOpts.playbooks))
+		os.Exit(1)
+	}
+	if err := tracker.err(); err != nil {
+		output.Errorf("❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	flags := planCmd.Flags()
+	flags.StringVarP(&planOpts.inventory, "inventory", "i", inventory.DefaultInventoryFilename, "Inventory file or dynamic inventory script")
+	flags.StringArrayVarP(&planOpts.playbooks, "playbook", "p", nil, "Playbook to plan, repeatable")
+	flags.StringVarP(&planOpts.tags, "tags", "t", "", "Only plan plays and tasks tagged with these values (comma-separated)")
+	flags.StringVarP(&planOpts.limit, "limit", "l", "", "Limit the plan to hosts matching this pattern")
+	flags.StringArrayVarP(&planOpts.extraVars, "extra-vars", "e", nil, "Extra variable as key=value, repeatable")
+	flags.StringVar(&planOpts.skipCheckTag, "skip-check-tag", config.Default().SkipCheckTag, "Skip tasks with this tag, for tasks that can't run in check mode (empty disables)")
+	flags.BoolVar(&planOpts.failOnChange, "fail-on-change", false, "Exit non-zero when any playbook would change something (drift detection)")
+	planCmd.MarkFlagRequired("playbook")
+	planCmd.RegisterFlagCompletionFunc("inventory", completeYAMLFiles)
+	planCmd.RegisterFlagCompletionFunc("playbook", completeYAMLFiles)
+	rootCmd.AddCommand(planCmd)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bxtal-lsn/gosible/internal/executor"
+)
+
+// ✅ Test that plan runs every playbook with both --check and --diff
+func TestPlan(t *testing.T) {
+	calls := stubExecutor(t)
+	rootCmd.SetArgs([]string{"plan", "-i", "hosts.yml", "-p", "site.yml", "-p", "db.yml"})
+	defer func() {
+		rootCmd.SetArgs(nil)
+		planOpts = planOptions{}
+	}()
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+
+	if len(*calls) != 2 || (*calls)[1].Playbook != "db.yml" {
+		t.Fatalf("Expected both playbooks to be planned, got %+v", *calls)
+	}
+	args := strings.Join(executor.BuildArgs((*calls)[0]), " ")
+	if args != "-i hosts.yml site.yml --check --diff --skip-tags no-check" {
+		t.Errorf("Expected check, diff and skip-tags arguments, got %q", args)
+	}
+}
+
+// ✅ Test that the skipped tag can be renamed or disabled
+func TestPlan_SkipCheckTag(t *testing.T) {
+	defer func() {
+		rootCmd.SetArgs(nil)
+		planOpts = planOptions{}
+		planCmd.Flags().Lookup("skip-check-tag").Changed = false
+	}()
+	for tag, expected := range map[string]string{
+		"check-unsafe": "-i inv.yml site.yml --check --diff --skip-tags check-unsafe",
+		"":             "-i inv.yml site.yml --check --diff",
+	} {
+		calls := stubExecutor(t)
+		rootCmd.SetArgs([]string{"plan", "-i", "inv.yml", "-p", "site.yml", "--skip-check-tag", tag})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("plan failed: %v", err)
+		}
+		if args := strings.Join(executor.BuildArgs((*calls)[0]), " "); args != expected {
+			t.Errorf("Expected %q, got %q", expected, args)
+		}
+		planOpts = planOptions{}
+	}
+}
@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bxtal-lsn/gosible/internal/executor"
+	"github.com/bxtal-lsn/gosible/internal/output"
+)
+
+// RunReport is the JSON summary written by --report
+type RunReport struct {
+	Started   time.Time     `json:"started"`
+	Finished  time.Time     `json:"finished"`
+	Playbooks []ReportEntry `json:"playbooks"`
+}
+
+// ReportEntry records one ansible-playbook execution
+type ReportEntry struct {
+	Playbook  string `json:"playbook"`
+	Inventory string `json:"inventory"`
+
+	DryRun      bool     `json:"dry_run"`
+	SyntaxCheck bool     `json:"syntax_check,omitempty"`
+	Tags        string   `json:"tags,omitempty"`
+	Limit       string   `json:"limit,omitempty"`
+	ExtraVars   []string `json:"extra_vars,omitempty"`
+	Become      bool     `json:"become,omitempty"`
+
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Status          string    `json:"status"` // "ok" or "failed"
+	ExitCode        int       `json:"exit_code"`
+	Error           string    `json:"error,omitempty"`
+
+	// ✅ From the PLAY RECAP: "no changes" or "changed", empty without a recap
+	Result      string   `json:"result,omitempty"`
+	Changed     int      `json:"changed,omitempty"`
+	FailedHosts []string `json:"failed_hosts,omitempty"`
+}
+
+// ✅ Wrap a playbook executor so every execution is added to the report
+func (r *RunReport) record(run func(executor.Options) error) func(executor.Options) error {
+	return func(opts executor.Options) error {
+		entry := ReportEntry{
+			Playbook:    opts.Playbook,
+			Inventory:   opts.Inventory,
+			DryRun:      opts.DryRun,
+			SyntaxCheck: opts.SyntaxCheck,
+			Tags:        opts.Tags,
+			Limit:       opts.Limit,
+			ExtraVars:   reportExtraVars(opts.ExtraVars),
+			Become:      opts.Become,
+			Start:       time.Now(),
+			Status:      "ok",
+		}
+
+		onRecap := opts.OnRecap
+		opts.OnRecap = func(recap executor.Recap) {
+			entry.Result = recap.Result()
+			entry.Changed = recap.Changed()
+			entry.FailedHosts = recap.FailedHosts()
+			if onRecap != nil {
+				onRecap(recap)
+			}
+		}
+
+		err := run(opts)
+
+		entry.End = time.Now()
+		entry.DurationSeconds = entry.End.Sub(entry.Start).Seconds()
+		if err != nil {
+			entry.Status = "failed"
+			entry.Error = err.Error()
+			entry.ExitCode = -1
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				entry.ExitCode = exitErr.ExitCode()
+			}
+		}
+		r.Playbooks = append(r.Playbooks, entry)
+		return err
+	}
+}
+
+// ✅ Extra vars as shown in the report, with secret-looking values redacted
+// like in the audit log
+func reportExtraVars(vars []string) []string {
+	if vars == nil {
+		return nil
+	}
+	redacted := make([]string, len(vars))
+	for i, v := range vars {
+		redacted[i] = executor.RedactExtraVars(v)
+	}
+	return redacted
+}
+
+// ✅ Formats for the run summary and report, chosen with --output-format
+const (
+	outputFormatText  = "text"
+	outputFormatJSON  = "json"
+	outputFormatJUnit = "junit"
+)
+
+// ✅ Check an --output-format value
+func checkOutputFormat(format string) error {
+	switch format {
+	case outputFormatText, outputFormatJSON, outputFormatJUnit:
+		return nil
+	}
+	return fmt.Errorf("invalid --output-format %q: expected %s, %s or %s", format, outputFormatText, outputFormatJSON, outputFormatJUnit)
+}
+
+// ✅ Encode the report as JUnit XML, or as indented JSON for any other format
+func (r *RunReport) encode(format string) ([]byte, error) {
+	if format == outputFormatJUnit {
+		return r.junit()
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// ✅ Write the report in format, JSON unless it's junit
+func (r *RunReport) write(path string, format string) error {
+	data, err := r.encode(format)
+	if err != nil {
+		return err
+	}
+	// Private, like the audit log, as the run's settings may still be sensitive
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("error writing report: %w", err)
+	}
+	return nil
+}
+
+// ✅ JUnit XML as read by CI systems: one suite for the run, one test case per playbook
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// ✅ Render the playbook executions as JUnit XML
+// Each playbook is a test case named after it, in a class named after its
+// inventory; failed playbooks carry a failure with the error and failed hosts.
+// Syntax checks are left out, as in the summary.
+func (r *RunReport) junit() ([]byte, error) {
+	suite := junitTestSuite{Name: "gosible", Timestamp: r.Started.Format(time.RFC3339)}
+	for _, entry := range r.Playbooks {
+		if entry.SyntaxCheck {
+			continue
+		}
+		testCase := junitTestCase{
+			Name:      entry.Playbook,
+			Classname: entry.Inventory,
+			Time:      strconv.FormatFloat(entry.DurationSeconds, 'f', 3, 64),
+		}
+		if entry.Status == "failed" {
+			text := fmt.Sprintf("exit code %d", entry.ExitCode)
+			if len(entry.FailedHosts) > 0 {
+				text += "\nfailed hosts: " + strings.Join(entry.FailedHosts, ", ")
+			}
+			testCase.Failure = &junitFailure{Message: entry.Error, Type: "failed", Text: text}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Tests = len(suite.Cases)
+	suite.Time = strconv.FormatFloat(r.Finished.Sub(r.Started).Seconds(), 'f', 3, 64)
+
+	suites := junitTestSuites{
+		Name:     suite.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// ✅ ANSI colors for the summary's status column
+var statusColors = map[string]string{
+	"ok":     "\x1b[32m",
+	"failed": "\x1b[31m",
+}
+
+// ✅ Render the playbook executions as an aligned table
+// Syntax checks are left out. Statuses are colored unless plain output is on;
+// Changed and Failed are "-" when ansible printed no recap.
+func (r *RunReport) summary() string {
+	var entries []ReportEntry
+	for _, entry := range r.Playbooks {
+		if !entry.SyntaxCheck {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return ""
+	}
+
+	var table strings.Builder
+	tw := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Playbook\tStatus\tDuration\tChanged\tFailed")
+	for _, entry := range entries {
+		changed, failed := "-", "-"
+		if entry.Result != "" {
+			changed, failed = strconv.Itoa(entry.Changed), strconv.Itoa(len(entry.FailedHosts))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", entry.Playbook, entry.Status,
+			formatDuration(entry.End.Sub(entry.Start)), changed, failed)
+	}
+	tw.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+	if !output.Plain() {
+		// Colored after aligning, since tabwriter would count the escape codes
+		column := len([]rune(lines[0][:strings.Index(lines[0], "Status")]))
+		for i, entry := range entries {
+			lines[i+1] = colorCell(lines[i+1], column, entry.Status, statusColors[entry.Status])
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// ✅ Wrap the cell starting at rune column in color
+func colorCell(line string, column int, cell string, color string) string {
+	runes := []rune(line)
+	if color == "" || column+len([]rune(cell)) > len(runes) {
+		return line
+	}
+	end := column + len([]rune(cell))
+	return string(runes[:column]) + color + string(runes[column:end]) + "\x1b[0m" + string(runes[end:])
+}
+
+// ✅ Tenths of a second for short runs, whole seconds for longer ones
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(100 * time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// ✅ Run the playbooks and print a summary of every execution
+// With --report, a report is written too, even when a playbook fails. The
+// json and junit output formats print the report instead of the table, and
+// write it in that format.
+func runPlaybooksWithReport(reader *bufio.Reader) error {
+	report := &RunReport{Started: time.Now(), Playbooks: []ReportEntry{}}
+	oldExecutePlaybook := executePlaybook
+	executePlaybook = report.record(oldExecutePlaybook)
+	defer func() { executePlaybook = oldExecutePlaybook }()
+
+	err := runPlaybooks(reader)
+
+	report.Finished = time.Now()
+	switch runOpts.outputFormat {
+	case outputFormatJSON, outputFormatJUnit:
+		// Printed even when quiet, as it's meant for other tools to read
+		data, encodeErr := report.encode(runOpts.outputFormat)
+		if encodeErr != nil {
+			output.Errorf("❌ Could not encode the summary: %v\n", encodeErr)
+			return errors.Join(err, encodeErr)
+		}
+		os.Stdout.Write(data)
+	default:
+		if summary := report.summary(); summary != "" {
+			output.Printf("\n📊 Summary:\n%s", summary)
+		}
+	}
+	if runOpts.report == "" {
+		return err
+	}
+	if writeErr := report.write(runOpts.report, runOpts.outputFormat); writeErr != nil {
+		output.Errorf("❌ %v\n", writeErr)
+		return errors.Join(err, writeErr)
+	}
+	output.Printf("📝 Run report written to %s\n", runOpts.report)
+	return err
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bxtal-lsn/gosible/internal/executor"
+)
+
+// ✅ Test that the report records each playbook with its status
+func TestRunPlaybooksWithReport(t *testing.T) {
+	stubExecutorWith(t, func(opts executor.Options) error {
+		if opts.Playbook == "db.yml" {
+			return errors.New("exit status 2")
+		}
+		opts.OnRecap(executor.ParseRecap("PLAY RECAP ***\nweb1 : ok=2 changed=0 failed=0\n"))
+		return nil
+	})
+	path := filepath.Join(t.TempDir(), "report.json")
+	runOpts = runOptions{inventory: "inv.yml", playbooks: []string{"site.yml", "db.yml"}, tags: "deploy", yes: true, report: path,
+		extraVars: []string{"app=web db_password=hunter2"}}
+	defer func() { runOpts = runOptions{} }()
+
+	// The failed playbook fails the run, and the report is still written
+	if err := runPlaybooksWithReport(bufio.NewReader(strings.NewReader(""))); err == nil || !strings.Contains(err.Error(), "db.yml") {
+		t.Fatalf("Expected the run to fail for db.yml, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected a report file: %v", err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected the report to be 0600, got %o", info.Mode().Perm())
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Errorf("Expected secret extra vars to be redacted, got %s", data)
+	}
+	var report RunReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Report isn't valid JSON: %v", err)
+	}
+
+	if len(report.Playbooks) != 2 {
+		t.Fatalf("Expected 2 entries, got %+v", report.Playbooks)
+	}
+	first, second := report.Playbooks[0], report.Playbooks[1]
+	if first.Playbook != "site.yml" || first.Status != "ok" || first.ExitCode != 0 || first.Tags != "deploy" || first.Result != executor.ResultNoChanges {
+		t.Errorf("Unexpected first entry %+v", first)
+	}
+	if second.Playbook != "db.yml" || second.Status != "failed" || second.Error != "exit status 2" || second.Result != "" {
+		t.Errorf("Unexpected second entry %+v", second)
+	}
+	if len(first.ExtraVars) != 1 || first.ExtraVars[0] != "app=web 'db_password=<redacted>'" {
+		t.Errorf("Expected the redacted extra vars, got %q", first.ExtraVars)
+	}
+	if first.Inventory != "inv.yml" || first.End.Before(first.Start) || report.Finished.Before(report.Started) {
+		t.Errorf("Unexpected inventory or timing in %+v", report)
+	}
+}
+
+// ✅ Test that the summary is an aligned table with colored statuses unless plain
+func TestRunReportSummary(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	report := &RunReport{Playbooks: []ReportEntry{
+		{Playbook: "site.yml", SyntaxCheck: true, Status: "ok", Start: start, End: start},
+		{Playbook: "site.yml", Status: "ok", Start: start, End: start.Add(1500 * time.Millisecond), Result: executor.ResultChanged, Changed: 3},
+		{Playbook: "database.yml", Status: "failed", Start: start, End: start.Add(2 * time.Minute), Result: executor.ResultChanged, FailedHosts: []string{"db1"}},
+		{Playbook: "broken.yml", Status: "failed", Start: start, End: start},
+	}}
+
+	t.Setenv("NO_COLOR", "1")
+	expected := "Playbook      Status  Duration  Changed  Failed\n" +
+		"site.yml      ok      1.5s      3        0\n" +
+		"database.yml  failed  2m0s      0        1\n" +
+		"broken.yml    failed  0s        -        -\n"
+	if got := report.summary(); got != expected {
+		t.Errorf("Expected plain summary:\n%s\ngot:\n%s", expected, got)
+	}
+
+	t.Setenv("NO_COLOR", "")
+	colored := report.summary()
+	if !strings.Contains(colored, "site.yml      \x1b[32mok\x1b[0m      1.5s") ||
+		!strings.Contains(colored, "database.yml  \x1b[31mfailed\x1b[0m  2m0s") {
+		t.Errorf("Expected colored statuses, got:\n%q", colored)
+	}
+}
+
+// ✅ Test that the JUnit report is valid XML with a failure for the failed playbook
+func TestRunReportJUnit(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	report := &RunReport{Started: start, Finished: start.Add(3 * time.Second), Playbooks: []ReportEntry{
+		{Playbook: "site.yml", Inventory: "inv.yml", SyntaxCheck: true, Status: "ok"},
+		{Playbook: "site.yml", Inventory: "inv.yml", Status: "ok", DurationSeconds: 1.5},
+		{Playbook: "db.yml", Inventory: "inv.yml", Status: "failed", ExitCode: 2, Error: "exit status 2", FailedHosts: []string{"db1"}},
+	}}
+
+	path := filepath.Join(t.TempDir(), "report.xml")
+	if err := report.write(path, outputFormatJUnit); err != nil {
+		t.Fatalf("write returned error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected a report file: %v", err)
+	}
+	if !strings.HasPrefix(string(data), xml.Header) {
+		t.Errorf("Expected an XML header, got:\n%s", data)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatalf("Report isn't valid XML: %v\n%s", err, data)
+	}
+	if suites.Tests != 2 || suites.Failures != 1 || suites.Time != "3.000" || len(suites.Suites) != 1 {
+		t.Fatalf("Unexpected test suites %+v", suites)
+	}
+	cases := suites.Suites[0].Cases
+	if len(cases) != 2 || cases[0].Name != "site.yml" || cases[0].Time != "1.500" || cases[0].Failure != nil {
+		t.Fatalf("Expected a passing site.yml test case, got %+v", cases)
+	}
+	failure := cases[1].Failure
+	if cases[1].Name != "db.yml" || cases[1].Classname != "inv.yml" || failure == nil ||
+		failure.Message != "exit status 2" || !strings.Contains(failure.Text, "db1") {
+		t.Errorf("Expected a failure for db.yml naming db1, got %+v with %+v", cases[1], failure)
+	}
+
+	if err := checkOutputFormat("xml"); err == nil {
+		t.Error("Expected an error for an unknown output format")
+	}
+}
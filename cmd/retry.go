@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bxtal-lsn/gosible/internal/output"
+)
+
+// ✅ Retry file written by default, in the current directory
+// It lists playbooks rather than hosts, unlike ansible's own .retry files
+const defaultRetryFile = "gosible.retry"
+
+// ✅ Failed playbooks of a run
+// The run stops at the first failure unless --keep-going is set, in which case
+// the rest still run; either way the run fails once any playbook has
+type runFailures struct {
+	inventory string // Inventory flags for the rerun hint, "" if there are none to give
+	playbooks []string
+	dryRun    bool
+	failed    []string
+	pending   []string // Failed and skipped playbooks, for the retry file
+}
+
+func newRunFailures(inventoryFlags string, playbooks []string, dryRun bool) *runFailures {
+	return &runFailures{inventory: inventoryFlags, playbooks: playbooks, dryRun: dryRun}
+}
+
+// ✅ Inventory flags that rerun against the same inventory
+// An inventory generated for the run is deleted when it ends, so --hosts is
+// given again instead, and nothing for one piped in or built interactively
+func rerunInventoryFlags(inventoryFile string, ephemeral bool) string {
+	switch {
+	case !ephemeral:
+		return "-i " + inventoryFile
+	case runOpts.hosts != "":
+		return "--hosts " + runOpts.hosts
+	default:
+		return ""
+	}
+}
+
+// ✅ Record that playbooks[i] failed, reporting whether the run should stop
+func (f *runFailures) add(i int) bool {
+	playbook, rest := f.playbooks[i], f.playbooks[i+1:]
+	f.failed = append(f.failed, playbook)
+	f.pending = append(f.pending, playbook)
+	if runOpts.keepGoing || len(rest) == 0 {
+		return false
+	}
+	f.pending = append(f.pending, rest...)
+	output.Errorf("⏭️ Skipping %d remaining playbook(s) after %s failed (use --keep-going to run them anyway)\n", len(rest), playbook)
+	return true
+}
+
+// ✅ The run's error once any playbook failed
+// Each failure was already reported by the executor, so only the tally is
+// printed, along with the retry file listing what's left to run
+func (f *runFailures) err() error {
+	if len(f.failed) == 0 {
+		f.consumeRetryFile()
+		return nil
+	}
+	err := fmt.Errorf("%d of %d playbook(s) failed: %s", len(f.failed), len(f.playbooks), strings.Join(f.failed, ", "))
+	if runOpts.keepGoing {
+		output.Errorf("❌ %v\n", err)
+	}
+	f.writeRetryFile()
+	return err
+}
+
+// ✅ List the pending playbooks in the retry file, in the --playbook-list format
+func (f *runFailures) writeRetryFile() {
+	if runOpts.retryFile == "" {
+		return
+	}
+	var b strings.Builder
+	b.WriteString("# Playbooks that failed or didn't run, rerun them with:\n")
+	rerun := "gosible run"
+	if f.inventory != "" {
+		rerun += " " + f.inventory
+	}
+	b.WriteString(fmt.Sprintf("#   %s --from-retry %s\n", rerun, runOpts.retryFile))
+	for _, playbook := range f.pending {
+		b.WriteString(playbook + "\n")
+	}
+	if err := os.WriteFile(runOpts.retryFile, []byte(b.String()), 0o644); err != nil {
+		output.Warnf("⚠️ Could not write retry file %s: %v\n", runOpts.retryFile, err)
+		return
+	}
+	output.Errorf("📝 Rerun what's left with --from-retry %s\n", runOpts.retryFile)
+}
+
+// ✅ Remove a retry file once every playbook it listed has succeeded
+// A passing dry run changed nothing, so the file is kept for the real run
+func (f *runFailures) consumeRetryFile() {
+	if runOpts.fromRetry == "" || f.dryRun {
+		return
+	}
+	if err := os.Remove(runOpts.fromRetry); err != nil && !os.IsNotExist(err) {
+		output.Warnf("⚠️ Could not remove retry file %s: %v\n", runOpts.fromRetry, err)
+		return
+	}
+	output.Printf("🧹 Every retried playbook succeeded, removed %s\n", runOpts.fromRetry)
+}
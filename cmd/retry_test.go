@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bxtal-lsn/gosible/internal/executor"
+	"github.com/bxtal-lsn/gosible/internal/playbook"
+)
+
+// ✅ Test that failed playbooks are written to the retry file and that
+// --from-retry runs only those, removing the file once they pass
+func TestRunPlaybooks_RetryFile(t *testing.T) {
+	retryFile := filepath.Join(t.TempDir(), "gosible.retry")
+	calls := stubExecutorWith(t, func(opts executor.Options) error {
+		if opts.Playbook == "db.yml" {
+			return errors.New("exit status 2")
+		}
+		return nil
+	})
+	runOpts = runOptions{inventory: "inv.yml", playbooks: []string{"site.yml", "db.yml", "app.yml"}, yes: true, keepGoing: true, retryFile: retryFile}
+	defer func() { runOpts = runOptions{} }()
+
+	captureStderr(t, func() {
+		if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err == nil {
+			t.Error("Expected the run to fail")
+		}
+	})
+	if listed, err := playbook.FromList(retryFile); err != nil || !reflect.DeepEqual(listed, []string{"db.yml"}) {
+		t.Fatalf("Expected the retry file to list db.yml, got %v (%v)", listed, err)
+	}
+
+	// The retried playbook passes now
+	calls = stubExecutor(t)
+	runOpts = runOptions{inventory: "inv.yml", yes: true, fromRetry: retryFile}
+	playbooks, err := collectPlaybooks(runOpts)
+	if err != nil {
+		t.Fatalf("collectPlaybooks returned error: %v", err)
+	}
+	runOpts.playbooks = playbooks
+	if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err != nil {
+		t.Fatalf("Retry run failed: %v", err)
+	}
+	if len(*calls) != 1 || (*calls)[0].Playbook != "db.yml" {
+		t.Errorf("Expected only db.yml to run, got %+v", *calls)
+	}
+	if _, err := os.Stat(retryFile); !os.IsNotExist(err) {
+		t.Errorf("Expected the retry file to be removed, got %v", err)
+	}
+}
+
+// ✅ Test that playbooks skipped after a failure are listed for retry too
+func TestRunPlaybooks_RetryFileSkipped(t *testing.T) {
+	retryFile := filepath.Join(t.TempDir(), "gosible.retry")
+	stubExecutorWith(t, func(opts executor.Options) error {
+		if opts.Playbook == "db.yml" {
+			return errors.New("exit status 2")
+		}
+		return nil
+	})
+	runOpts = runOptions{inventory: "inv.yml", playbooks: []string{"site.yml", "db.yml", "app.yml"}, yes: true, retryFile: retryFile}
+	defer func() { runOpts = runOptions{} }()
+
+	captureStderr(t, func() { runPlaybooks(bufio.NewReader(strings.NewReader(""))) })
+	if listed, err := playbook.FromList(retryFile); err != nil || !reflect.DeepEqual(listed, []string{"db.yml", "app.yml"}) {
+		t.Errorf("Expected db.yml and the skipped app.yml, got %v (%v)", listed, err)
+	}
+}
+
+// ✅ Test that the rerun hint only names an inventory that outlives the run
+func TestRunPlaybooks_RetryFileHint(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     runOptions
+		expected string
+	}{
+		{"inventory file", runOptions{inventory: "inv.yml"}, "#   gosible run -i inv.yml --from-retry "},
+		{"hosts", runOptions{hosts: "web1,web2"}, "#   gosible run --hosts web1,web2 --from-retry "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retryFile := filepath.Join(t.TempDir(), "gosible.retry")
+			stubExecutorWith(t, func(opts executor.Options) error { return errors.New("exit status 2") })
+			runOpts = tt.opts
+			runOpts.playbooks, runOpts.yes, runOpts.retryFile = []string{"site.yml"}, true, retryFile
+			defer func() { runOpts = runOptions{} }()
+
+			captureStderr(t, func() { runPlaybooks(bufio.NewReader(strings.NewReader(""))) })
+			content, err := os.ReadFile(retryFile)
+			if err != nil {
+				t.Fatalf("Expected a retry file: %v", err)
+			}
+			if !strings.Contains(string(content), tt.expected+retryFile+"\n") {
+				t.Errorf("Expected the hint %q, got:\n%s", tt.expected, content)
+			}
+		})
+	}
+
+	// An inventory piped in is gone after the run, so no -i is suggested
+	retryFile := filepath.Join(t.TempDir(), "gosible.retry")
+	stubExecutorWith(t, func(opts executor.Options) error { return errors.New("exit status 2") })
+	runOpts = runOptions{inventory: stdinInventory, playbooks: []string{"site.yml"}, yes: true, retryFile: retryFile}
+	defer func() { runOpts = runOptions{} }()
+
+	captureStderr(t, func() {
+		runPlaybooks(bufio.NewReader(strings.NewReader("all:\n  hosts:\n    web1:\n")))
+	})
+	content, err := os.ReadFile(retryFile)
+	if err != nil {
+		t.Fatalf("Expected a retry file: %v", err)
+	}
+	if !strings.Contains(string(content), "#   gosible run --from-retry "+retryFile+"\n") {
+		t.Errorf("Expected a hint without -i, got:\n%s", content)
+	}
+}
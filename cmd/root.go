@@ -1,26 +1,78 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
 
+	"github.com/bxtal-lsn/gosible/internal/config"
+	"github.com/bxtal-lsn/gosible/internal/inventory"
+	"github.com/bxtal-lsn/gosible/internal/output"
 	"github.com/spf13/cobra"
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "ansiblecli",
-	Short: "A CLI tool for dynamically running Ansible playbooks",
+	Use:               "ansiblecli",
+	Short:             "A CLI tool for dynamically running Ansible playbooks",
+	PersistentPreRunE: setup,
 }
 
+// Path given with --config, and the defaults loaded from it
+var (
+	configFile string
+	cfg        = config.Default()
+)
+
+// Inventory syntax set by --inventory-format
+var inventoryFormat string
+
+// Output modes set by --plain/--no-emoji and --quiet
+var (
+	plainOutput bool
+	quietOutput bool
+)
+
 // Execute runs the root command
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+		output.Errorf("%v\n", err)
 		os.Exit(1)
 	}
 }
 
-// Add subcommands here
+// setup applies global flags before any command runs
+func setup(cmd *cobra.Command, args []string) error {
+	output.SetPlain(plainOutput)
+	output.SetQuiet(quietOutput)
+	if err := inventory.SetFormat(inventory.Format(inventoryFormat)); err != nil {
+		return err
+	}
+	return loadConfig()
+}
+
+// loadConfig reads the config file
+// Flags override config values, which override the built-in defaults
+func loadConfig() error {
+	path := configFile
+	if path == "" {
+		defaultPath, err := config.DefaultPath()
+		if err != nil {
+			return nil // No home directory, so no config to read
+		}
+		path = defaultPath
+	}
+
+	loaded, err := config.Load(path, configFile != "")
+	if err != nil {
+		return err
+	}
+	cfg = loaded
+	return nil
+}
+
+// Subcommands register themselves in their own init functions
 func init() {
-	rootCmd.AddCommand(runCmd)
+	rootCmd.PersistentFlags().BoolVar(&plainOutput, "plain", false, "Print gosible's messages without emoji or colors (also enabled by NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVar(&plainOutput, "no-emoji", false, "Alias for --plain")
+	rootCmd.PersistentFlags().BoolVarP(&quietOutput, "quiet", "q", false, "Only print errors and ansible's own output")
+	rootCmd.PersistentFlags().StringVar(&inventoryFormat, "inventory-format", string(inventory.FormatAuto), "Syntax of inventory files read by gosible: auto (detect from content), yaml or ini")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file with default flag values (default ~/.config/gosible/config.yml)")
 }
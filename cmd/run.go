@@ -2,140 +2,772 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/bxtal-lsn/gosible/internal/config"
 	"github.com/bxtal-lsn/gosible/internal/executor"
 	"github.com/bxtal-lsn/gosible/internal/inventory"
+	"github.com/bxtal-lsn/gosible/internal/output"
+	"github.com/bxtal-lsn/gosible/internal/playbook"
+	"github.com/bxtal-lsn/gosible/internal/prompt"
+	"github.com/bxtal-lsn/gosible/internal/rotate"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var runCmd = &cobra.Command{
-	Use:   "run",
+	Use:   "run [-- ansible-playbook args...]",
 	Short: "Run Ansible playbooks with optional auto-discovery and dry-run mode",
-	Run:   runPlaybook,
+	Long: `Run Ansible playbooks with optional auto-discovery and dry-run mode.
+
+serial and max_fail_percentage are play keywords ansible-playbook has no flags
+for, so --serial and --max-fail-percentage pass them as extra vars that plays
+opt into, and gosible warns about playbooks that don't:
+
+  - hosts: web
+    serial: "{{ gosible_serial | default('100%') }}"
+    max_fail_percentage: "{{ gosible_max_fail_percentage | default(100) }}"
+
+With both, a rollout goes out in batches and stops once too many hosts of a
+batch fail.`,
+	Run: runPlaybook,
+}
+
+// runOptions holds the flags accepted by the run command
+type runOptions struct {
+	inventory      string
+	hosts          string // Comma-separated hosts run through a temporary inventory
+	playbooks      []string
+	playbookDir    string
+	playbookList   string
+	listPlaybooks  bool
+	dryRun         bool
+	checkAndApply  bool
+	syntaxCheck    bool
+	requireApply   bool
+	apply          bool
+	tags           string
+	limit          string
+	limitFailed    bool
+	extraVars      []string
+	verbosity      int
+	heartbeat      time.Duration
+	summaryOnly    bool
+	failOnChange   bool
+	validateScript bool
+	validateLimit  bool
+	preview        bool
+	keepTilde      bool
+	ephemeral      bool
+	overwrite      bool
+	backup         bool
+	yes            bool
+	force          bool
+	allowLocalhost bool
+	become         bool
+	becomeMethod   string
+	becomeUser     string
+	becomePassFile string // Never recorded in history
+	ansibleBin     string
+	forks          int
+	serial         string
+	maxFailPct     int
+	maxFailSet     bool // --max-fail-percentage was passed, as 0 is a valid percentage
+	vaultPassFile  string
+	vaultIDs       []string
+	privateKey     string
+	remoteUser     string
+	sshTimeout     int
+	hostKeyCheck   string // true or false forces SSH host key checking, empty inherits ansible's
+	forceHandlers  bool
+	chdir          string
+	keepGoing      bool
+	retryFile      string
+	inventoryCheck bool
+	describe       bool
+	watch          bool
+	fromRetry      string
+	historySize    int
+	pruneHistory   bool
+	sinceLast      bool
+	template       string
+	autoGroup      bool
+	verify         bool
+	installDeps    bool
+	report         string
+	outputFormat   string // text, json or junit, for the summary and --report
+	auditLog       string
+	logMaxSize     string // Size past which the audit log and history are rotated
+	playbookArgs   string
+	passthrough    []string // --playbook-args and anything after --, split into arguments
+}
+
+var runOpts runOptions
+
+// ✅ Changes may only be applied when no apply policy is in force or --apply was passed
+func (o runOptions) applyAllowed() bool {
+	return !o.requireApply || o.apply
+}
+
+// ✅ With both an inventory (or --hosts) and playbooks on the command line,
+// nothing is asked interactively apart from the apply confirmation (skipped by --yes)
+func (o runOptions) nonInteractive() bool {
+	return (o.inventory != "" || o.hosts != "") && len(o.playbooks) > 0
 }
 
+// ✅ Allow overriding the executor for testing
+var executePlaybook = executor.Run
+
+// ✅ Allow overriding ansible-inventory for testing
+var showInventory = executor.ShowInventory
+
 func runPlaybook(cmd *cobra.Command, args []string) {
+	applyRunConfig(cmd.Flags(), cfg, &runOpts)
+	if err := executor.ValidateExtraVars(runOpts.extraVars); err != nil {
+		output.Errorf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := inventory.LookupTemplate(runOpts.template); err != nil {
+		output.Errorf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if err := checkOutputFormat(runOpts.outputFormat); err != nil {
+		output.Errorf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := strconv.ParseBool(runOpts.hostKeyCheck); runOpts.hostKeyCheck != "" && err != nil {
+		output.Errorf("❌ invalid --host-key-checking %q: expected true or false\n", runOpts.hostKeyCheck)
+		os.Exit(1)
+	}
+	if _, err := rotate.ParseSize(runOpts.logMaxSize); err != nil {
+		output.Errorf("❌ --log-max-size: %v\n", err)
+		os.Exit(1)
+	}
+	if runOpts.serial != "" {
+		serialVar, err := serialExtraVar(runOpts.serial)
+		if err != nil {
+			output.Errorf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		// Kept with the other extra vars so history reruns keep the batch size
+		runOpts.extraVars = append(runOpts.extraVars, serialVar)
+	}
+	runOpts.maxFailSet = cmd.Flags().Changed("max-fail-percentage")
+	if runOpts.maxFailSet {
+		maxFailVar, err := maxFailExtraVar(runOpts.maxFailPct)
+		if err != nil {
+			output.Errorf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		runOpts.extraVars = append(runOpts.extraVars, maxFailVar)
+	}
+	passthrough, err := passthroughArgs(runOpts.playbookArgs, args, cmd.ArgsLenAtDash())
+	if err != nil {
+		output.Errorf("❌ --playbook-args: %v\n", err)
+		os.Exit(1)
+	}
+	runOpts.passthrough = passthrough
+	playbooks, err := collectPlaybooks(runOpts)
+	if err != nil {
+		output.Errorf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	runOpts.playbooks = playbooks
 	reader := bufio.NewReader(os.Stdin)
+	if runOpts.watch {
+		if err := prepareWatch(&runOpts); err != nil {
+			output.Errorf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		if err := watchPlaybooks(ctx, watchedFiles(runOpts), func() error { return runPlaybooksFailOnChange(reader) }); err != nil {
+			output.Errorf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := runPlaybooksFailOnChange(reader); err != nil {
+		os.Exit(1)
+	}
+}
+
+// runPlaybooks drives the interactive run flow using answers from reader
+// The returned error has already been reported to the user
+func runPlaybooks(reader *bufio.Reader) error {
 	var inventoryFile string
-	var instances []string
+	var instances []inventory.HostConfig
 	var playbooks []string
 	var dryRun bool
 
 	// Check command history
+	if runOpts.pruneHistory {
+		if removed, err := pruneHistory(); err != nil {
+			output.Warnf("⚠️ Could not prune command history: %v\n", err)
+		} else if removed > 0 {
+			output.Printf("🧹 Removed %d stale history entries\n", removed)
+		}
+	}
 	historyEntries, err := loadHistory()
 	if err != nil {
-		fmt.Printf("⚠️ Could not load command history: %v\n", err)
+		output.Warnf("⚠️ Could not load command history: %v\n", err)
+	}
+
+	if runOpts.sinceLast {
+		if len(historyEntries) == 0 {
+			err := errors.New("no previous command to repeat, the history is empty")
+			output.Errorf("❌ %v\n", err)
+			return err
+		}
+		last := historyEntries[len(historyEntries)-1]
+		output.Printf("🔁 Repeating: Inventory: %s | Playbooks: %s | Dry-run: %t%s\n",
+			last.InventoryFile, strings.Join(last.Playbooks, " "), last.DryRun, last.describeOptions())
+		runOpts.yes = true // Nothing is asked when repeating
+		return rerunHistoryEntry(reader, last)
 	}
 
 	// Offer to reuse previous command if history exists
-	if len(historyEntries) > 0 {
-		fmt.Println("\n🕒 Previous commands (latest first):")
+	if len(historyEntries) > 0 && runOpts.inventory == "" && runOpts.hosts == "" && len(runOpts.playbooks) == 0 {
+		output.Println("\n🕒 Previous commands (latest first):")
 		displayedEntries := historyEntries
-		if len(displayedEntries) > 5 {
-			displayedEntries = displayedEntries[len(displayedEntries)-5:]
+		if len(displayedEntries) > historySize() {
+			displayedEntries = displayedEntries[len(displayedEntries)-historySize():]
 		}
 
 		// Display entries in reverse chronological order
 		for i := len(displayedEntries) - 1; i >= 0; i-- {
 			entry := displayedEntries[i]
-			fmt.Printf("%d. Inventory: %s | Playbooks: %s | Dry-run: %t\n",
+			output.Printf("%d. Inventory: %s | Playbooks: %s | Dry-run: %t%s\n",
 				len(displayedEntries)-i,
 				entry.InventoryFile,
 				strings.Join(entry.Playbooks, " "),
-				entry.DryRun)
+				entry.DryRun,
+				entry.describeOptions())
 		}
 
-		fmt.Println("\n↩️ Choose a previous command (1-5) or press Enter to start fresh:")
-		fmt.Print("> ")
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(input)
+		output.Prompt(fmt.Sprintf("\n↩️ Choose a previous command (1-%d) or press Enter to start fresh:", len(displayedEntries)))
+		input, err := readAnswer(reader)
+		if err != nil {
+			return reportInputError(err)
+		}
 
 		if input != "" {
 			if choice, err := strconv.Atoi(input); err == nil {
 				if choice >= 1 && choice <= len(displayedEntries) {
 					selectedIndex := len(displayedEntries) - choice
-					selectedEntry := displayedEntries[selectedIndex]
-
-					// Use selected history entry
-					inventoryFile = selectedEntry.InventoryFile
-					playbooks = selectedEntry.Playbooks
-					dryRun = selectedEntry.DryRun
-
-					// Execute directly
-					for _, playbook := range playbooks {
-						fmt.Printf("\n🚀 Running playbook: %s using inventory: %s\n",
-							playbook, inventoryFile)
-						executor.ExecuteAnsiblePlaybook(inventoryFile, playbook, nil, dryRun)
-					}
-
-					// Save to history again
-					saveNewHistoryEntry(inventoryFile, playbooks, dryRun)
-					return
+					return rerunHistoryEntry(reader, displayedEntries[selectedIndex])
 				}
 			}
 		}
 	}
 
 	// Normal execution flow
-	inventoryFile = askForInventory(reader, &instances)
-	playbooks = askForPlaybooks(reader)
-	dryRun = askForDryRun(reader)
+	var created, temporary bool
+	if runOpts.inventory == stdinInventory {
+		if inventoryFile, err = readInventory(reader); err != nil {
+			output.Errorf("❌ %v\n", err)
+			return err
+		}
+		temporary = true
+	} else if runOpts.hosts != "" {
+		if inventoryFile, err = hostsInventory(runOpts.hosts, runOpts.remoteUser); err != nil {
+			output.Errorf("❌ %v\n", err)
+			return err
+		}
+		temporary = true
+	} else if runOpts.inventory != "" {
+		inventoryFile = runOpts.inventory
+	} else {
+		inventoryFile, created, err = askForInventory(reader, &instances)
+		if err != nil {
+			return reportInputError(err)
+		}
+	}
+	if inventoryFile == "" {
+		output.Errorf("❌ No inventory to run against, aborting.\n")
+		return errors.New("no inventory to run against")
+	}
+
+	// ✅ Inventories generated, piped in or built from --hosts for this run only
+	// are removed when it ends, and left out of history since they won't exist
+	// to rerun against
+	ephemeral := (created && runOpts.ephemeral) || temporary
+	if ephemeral {
+		defer removeEphemeralInventory(inventoryFile)
+	}
+	remember := func(dryRun bool) {
+		if !ephemeral {
+			saveNewHistoryEntry(inventoryFile, playbooks, dryRun)
+		}
+	}
+
+	if !checkInventoryScript(inventoryFile) {
+		return nil
+	}
+	if len(runOpts.playbooks) > 0 {
+		playbooks = runOpts.playbooks
+	} else {
+		if runOpts.listPlaybooks {
+			playbooks, err = selectPlaybooks(reader, playbook.DefaultDir)
+		} else {
+			playbooks, err = askForPlaybooks(reader)
+		}
+		if err != nil {
+			return reportInputError(err)
+		}
+		varsFile, err := askForExtraVarsFile(reader)
+		if err != nil {
+			return reportInputError(err)
+		}
+		if varsFile != "" {
+			runOpts.extraVars = append(runOpts.extraVars, "@"+varsFile)
+		}
+	}
+
+	if err := checkTargets(inventoryFile, playbooks); err != nil {
+		return err
+	}
+
+	if runOpts.describe {
+		// The description is what was asked for, so it isn't silenced by --quiet
+		fmt.Fprint(os.Stdout, output.Clean(describeRun(inventoryFile, playbooks)))
+		return nil
+	}
+	if runOpts.inventoryCheck {
+		output.Printf("\n🔎 Hosts and groups ansible resolves from %s:\n", inventoryFile)
+		if err := showInventory(inventoryFile); err != nil {
+			output.Errorf("❌ %v\n", err)
+			return err
+		}
+	}
+	warnSerialUnused(playbooks)
+	warnMaxFailUnused(playbooks)
+	if err := installDependencies(playbooks); err != nil {
+		return err
+	}
+	if err := checkSyntax(inventoryFile, playbooks); err != nil {
+		return err
+	}
+	if err := checkRetryFiles(playbooks); err != nil {
+		return err
+	}
+
+	if runOpts.checkAndApply {
+		remember(false)
+		return checkAndApply(inventoryFile, playbooks)
+	}
+
+	if !runOpts.applyAllowed() {
+		warnApplyPolicy()
+		dryRun = true
+	} else if runOpts.apply {
+		dryRun = false
+	} else if runOpts.dryRun || runOpts.nonInteractive() {
+		dryRun = runOpts.dryRun
+	} else {
+		if dryRun, err = askForDryRun(reader); err != nil {
+			return reportInputError(err)
+		}
+	}
+
+	if !dryRun && !confirmRun(reader, inventoryFile, playbooks) {
+		return nil
+	}
 
 	// Save to history
-	saveNewHistoryEntry(inventoryFile, playbooks, dryRun)
+	remember(dryRun)
 
 	// Execute playbooks
-	for _, playbook := range playbooks {
-		fmt.Printf("\n🚀 Running playbook: %s using inventory: %s\n", playbook, inventoryFile)
-		executor.ExecuteAnsiblePlaybook(inventoryFile, playbook, nil, dryRun)
-		if dryRun {
-			fmt.Println("\n🔄 Would you like to run this again without dry-run? (yes/no)")
-			fmt.Print("> ")
-			response, _ := reader.ReadString('\n')
-			response = strings.TrimSpace(strings.ToLower(response))
-			if response == "yes" {
+	rerunFlags := rerunInventoryFlags(inventoryFile, ephemeral)
+	failures := newRunFailures(rerunFlags, playbooks, dryRun)
+	for i, playbook := range playbooks {
+		output.Printf("\n🚀 Running playbook: %s using inventory: %s\n", playbook, inventoryFile)
+		if err := executePlaybook(playbookOptions(inventoryFile, playbook, dryRun)); err != nil {
+			if failures.add(i) {
+				break
+			}
+			continue // Nothing worth applying
+		}
+		if dryRun && !runOpts.nonInteractive() && runOpts.applyAllowed() {
+			output.Prompt("\n🔄 Would you like to run this again without dry-run? (yes/no)")
+			response, _ := readAnswer(reader) // Closed input counts as no
+			if strings.ToLower(response) == "yes" {
 				// Re-run with same settings but dry-run disabled
-				for _, playbook := range playbooks {
-					fmt.Printf("\n🚀 Running playbook: %s using inventory: %s\n",
+				applied := newRunFailures(rerunFlags, playbooks, false)
+				for i, playbook := range playbooks {
+					output.Printf("\n🚀 Running playbook: %s using inventory: %s\n",
 						playbook, inventoryFile)
-					executor.ExecuteAnsiblePlaybook(inventoryFile, playbook, nil, false)
+					if err := executePlaybook(playbookOptions(inventoryFile, playbook, false)); err != nil && applied.add(i) {
+						break
+					}
 				}
 				// Save new history entry for non-dry run
-				saveNewHistoryEntry(inventoryFile, playbooks, false)
+				remember(false)
+				if err := applied.err(); err != nil {
+					return err
+				}
 			}
 		}
 
 	}
+	return failures.err()
+}
+
+// ✅ Arguments passed through to ansible-playbook untouched: the split
+// --playbook-args string followed by the arguments after `--`
+func passthroughArgs(playbookArgs string, args []string, dash int) ([]string, error) {
+	passthrough, err := executor.SplitArgs(playbookArgs)
+	if err != nil {
+		return nil, err
+	}
+	if dash >= 0 {
+		passthrough = append(passthrough, args[dash:]...)
+	}
+	return passthrough, nil
+}
+
+// ✅ `--inventory -` reads the inventory from stdin
+const stdinInventory = "-"
+
+// ✅ Copy an inventory piped in on stdin to a temporary file ansible can read
+func readInventory(reader io.Reader) (string, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("error reading inventory from stdin: %w", err)
+	}
+	if strings.TrimSpace(string(content)) == "" {
+		return "", errors.New("no inventory on stdin")
+	}
+
+	return writeTempInventory(content)
+}
+
+// ✅ Render an inventory of the comma-separated hosts given with --hosts,
+// connecting as user when one is set, to a temporary file
+func hostsInventory(list, user string) (string, error) {
+	var hosts []inventory.HostConfig
+	seen := map[string]bool{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		hosts = append(hosts, inventory.HostConfig{Host: name, SSHUser: user})
+	}
+	if len(hosts) == 0 {
+		return "", errors.New("no hosts given with --hosts")
+	}
+
+	content, err := inventory.RenderInventory(hosts)
+	if err != nil {
+		return "", err
+	}
+	return writeTempInventory([]byte(content))
+}
+
+// ✅ Write inventory content to a temporary file ansible can read
+func writeTempInventory(content []byte) (string, error) {
+	file, err := os.CreateTemp("", "gosible-inventory-*.yml")
+	if err != nil {
+		return "", fmt.Errorf("error creating temporary inventory: %w", err)
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", fmt.Errorf("error writing temporary inventory: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("error writing temporary inventory: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// ✅ Run a history entry again with its tags, limit, extra vars and become
+// settings, then record it as the newest entry
+func rerunHistoryEntry(reader *bufio.Reader, entry CommandHistoryEntry) error {
+	inventoryFile, playbooks, dryRun := entry.InventoryFile, entry.Playbooks, entry.DryRun
+
+	runOpts.applyHistoryEntry(entry)
+	if !dryRun && !runOpts.applyAllowed() {
+		warnApplyPolicy()
+		dryRun = true
+	}
+	if err := checkTargets(inventoryFile, playbooks); err != nil {
+		return err
+	}
+	if err := installDependencies(playbooks); err != nil {
+		return err
+	}
+	if err := checkSyntax(inventoryFile, playbooks); err != nil {
+		return err
+	}
+
+	if !dryRun && !confirmRun(reader, inventoryFile, playbooks) {
+		return nil
+	}
+
+	// Execute directly
+	failures := newRunFailures(rerunInventoryFlags(inventoryFile, false), playbooks, dryRun)
+	for i, playbook := range playbooks {
+		output.Printf("\n🚀 Running playbook: %s using inventory: %s\n",
+			playbook, inventoryFile)
+		if err := executePlaybook(playbookOptions(inventoryFile, playbook, dryRun)); err != nil && failures.add(i) {
+			break
+		}
+	}
+
+	// Save to history again
+	saveNewHistoryEntry(inventoryFile, playbooks, dryRun)
+	return failures.err()
+}
+
+// ✅ Delete an inventory generated for a run with --ephemeral-inventory,
+// piped in with --inventory - or built from --hosts
+func removeEphemeralInventory(inventoryFile string) {
+	if err := os.Remove(inventoryFile); err != nil {
+		output.Warnf("⚠️ Could not remove temporary inventory %s: %v\n", inventoryFile, err)
+		return
+	}
+	output.Printf("🧹 Removed temporary inventory %s\n", inventoryFile)
+}
+
+// ✅ Combine --playbook with the contents of --playbook-list, --playbook-dir
+// and --from-retry
+func collectPlaybooks(opts runOptions) ([]string, error) {
+	playbooks := append([]string{}, opts.playbooks...)
+	if opts.playbookList != "" {
+		listed, err := playbook.FromList(opts.playbookList)
+		if err != nil {
+			return nil, err
+		}
+		playbooks = append(playbooks, listed...)
+	}
+	if opts.playbookDir != "" {
+		found, err := playbook.FromDir(opts.playbookDir)
+		if err != nil {
+			return nil, err
+		}
+		playbooks = append(playbooks, found...)
+	}
+	if opts.fromRetry != "" {
+		retried, err := playbook.FromList(opts.fromRetry)
+		if err != nil {
+			return nil, err
+		}
+		if len(retried) == 0 {
+			return nil, fmt.Errorf("%s lists no playbooks to retry", opts.fromRetry)
+		}
+		playbooks = append(playbooks, retried...)
+	}
+	return playbooks, nil
+}
+
+// ✅ Run --syntax-check on every playbook first when --check-syntax-before-run is set
+func checkSyntax(inventoryFile string, playbooks []string) error {
+	if !runOpts.syntaxCheck {
+		return nil
+	}
+	for _, playbook := range playbooks {
+		opts := playbookOptions(inventoryFile, playbook, false)
+		opts.SyntaxCheck = true
+		output.Printf("\n🧪 Checking syntax: %s\n", playbook)
+		if err := executePlaybook(opts); err != nil {
+			output.Errorf("❌ Syntax check failed for %s, nothing was run.\n", playbook)
+			return err
+		}
+	}
+	return nil
+}
+
+// ✅ With --install-deps, install the requirements.yml next to each playbook
+// Each file is installed once even when several playbooks share a directory.
+// Runs before the syntax check, which fails on missing roles.
+func installDependencies(playbooks []string) error {
+	if !runOpts.installDeps {
+		return nil
+	}
+	installed := map[string]bool{}
+	for _, playbook := range playbooks {
+		requirements := filepath.Join(filepath.Dir(playbook), executor.DefaultRequirementsFile)
+		if installed[requirements] {
+			continue
+		}
+		installed[requirements] = true
+		if _, err := os.Stat(requirements); err != nil {
+			continue // Nothing to install for this playbook
+		}
+		if err := runGalaxyInstall(requirements, false); err != nil {
+			output.Errorf("❌ Could not install dependencies from %s, nothing was run.\n", requirements)
+			return err
+		}
+	}
+	return nil
+}
+
+// ✅ With --limit-from-failed, make sure every playbook left a retry file
+func checkRetryFiles(playbooks []string) error {
+	if !runOpts.limitFailed {
+		return nil
+	}
+	for _, playbook := range playbooks {
+		retryFile := executor.RetryFile(playbook)
+		if _, err := os.Stat(retryFile); err != nil {
+			output.Errorf("❌ No retry file for %s: %v\n", playbook, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// ✅ Dry-run every playbook, then apply them only if all checks passed
+func checkAndApply(inventoryFile string, playbooks []string) error {
+	for _, playbook := range playbooks {
+		output.Printf("\n🔍 Checking playbook: %s using inventory: %s\n", playbook, inventoryFile)
+		if err := executePlaybook(playbookOptions(inventoryFile, playbook, true)); err != nil {
+			output.Errorf("❌ Check failed for %s, nothing was applied.\n", playbook)
+			return err
+		}
+	}
+
+	if !runOpts.applyAllowed() {
+		warnApplyPolicy()
+		return nil
+	}
+
+	output.Println("\n✅ All checks passed, applying changes.")
+	for _, playbook := range playbooks {
+		output.Printf("\n🚀 Running playbook: %s using inventory: %s\n", playbook, inventoryFile)
+		if err := executePlaybook(playbookOptions(inventoryFile, playbook, false)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // CommandHistoryEntry represents a previous command run
+// Fields after DryRun were added later and are empty in older history files
 type CommandHistoryEntry struct {
 	InventoryFile string   `json:"inventory_file"`
 	Playbooks     []string `json:"playbooks"`
 	DryRun        bool     `json:"dry_run"`
+
+	Tags         string   `json:"tags,omitempty"`
+	Limit        string   `json:"limit,omitempty"`
+	ExtraVars    []string `json:"extra_vars,omitempty"`
+	Verbosity    int      `json:"verbosity,omitempty"`
+	Become       bool     `json:"become,omitempty"`
+	BecomeMethod string   `json:"become_method,omitempty"`
+	BecomeUser   string   `json:"become_user,omitempty"`
+}
+
+// describeOptions summarises the optional settings for the history menu
+func (e CommandHistoryEntry) describeOptions() string {
+	var parts []string
+	if e.Tags != "" {
+		parts = append(parts, "Tags: "+e.Tags)
+	}
+	if e.Limit != "" {
+		parts = append(parts, "Limit: "+e.Limit)
+	}
+	if len(e.ExtraVars) > 0 {
+		parts = append(parts, "Extra vars: "+strings.Join(e.ExtraVars, " "))
+	}
+	if e.Become {
+		parts = append(parts, "Become")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " | " + strings.Join(parts, " | ")
+}
+
+// applyHistoryEntry restores the run options recorded in a history entry
+// Binary, forks and vault settings still come from the current flags and config
+func (o *runOptions) applyHistoryEntry(entry CommandHistoryEntry) {
+	o.tags = entry.Tags
+	o.limit = entry.Limit
+	o.extraVars = entry.ExtraVars
+	o.verbosity = entry.Verbosity
+	o.become = entry.Become
+	o.becomeMethod = entry.BecomeMethod
+	o.becomeUser = entry.BecomeUser
 }
 
 // getHistoryPath returns the path to the history file
+// $GOSIBLE_HISTORY_FILE wins, then ~/.gosible_history; without a home
+// directory $XDG_STATE_HOME, the user cache and config directories and finally
+// the temp dir are used. An empty path means history is disabled.
 func getHistoryPath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
+	if path := os.Getenv("GOSIBLE_HISTORY_FILE"); path != "" {
+		return path, nil
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".gosible_history"), nil
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "gosible", "history"), nil
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "gosible", "history"), nil
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "gosible", "history"), nil
+	}
+	if info, err := os.Stat(os.TempDir()); err == nil && info.IsDir() {
+		path := filepath.Join(os.TempDir(), fmt.Sprintf("gosible_history_%d", os.Getuid()))
+		if err := checkSharedHistory(path); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+	return "", nil
+}
+
+// ✅ Refuse a history file in a shared directory unless it's missing or a
+// regular file owned by the current user, as anyone could have planted it
+// there, e.g. as a symlink to a file of the user's they want overwritten
+func checkSharedHistory(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
 	}
-	return filepath.Join(home, ".gosible_history"), nil
+	if !info.Mode().IsRegular() || !ownedByCurrentUser(info) {
+		return fmt.Errorf("refusing to use %s as history: it isn't a regular file owned by you", path)
+	}
+	return nil
+}
+
+// historySize returns how many history entries to keep
+func historySize() int {
+	if runOpts.historySize > 0 {
+		return runOpts.historySize
+	}
+	return config.Default().HistorySize
 }
 
-// loadHistory loads up to the last 5 commands from the history file
+// loadHistory loads up to the last historySize() commands from the history file
 func loadHistory() ([]CommandHistoryEntry, error) {
 	path, err := getHistoryPath()
 	if err != nil {
 		return nil, err
 	}
+	if path == "" {
+		return []CommandHistoryEntry{}, nil
+	}
 
 	data, err := os.ReadFile(path)
 	if os.IsNotExist(err) {
@@ -158,9 +790,9 @@ func loadHistory() ([]CommandHistoryEntry, error) {
 		entries = append(entries, entry)
 	}
 
-	// Keep only last 5 entries
-	if len(entries) > 5 {
-		entries = entries[len(entries)-5:]
+	// Keep only the most recent entries
+	if len(entries) > historySize() {
+		entries = entries[len(entries)-historySize():]
 	}
 
 	return entries, nil
@@ -172,6 +804,12 @@ func saveHistory(entries []CommandHistoryEntry) error {
 	if err != nil {
 		return err
 	}
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
 
 	var lines []string
 	for _, entry := range entries {
@@ -183,7 +821,24 @@ func saveHistory(entries []CommandHistoryEntry) error {
 	}
 
 	data := strings.Join(lines, "\n")
-	return os.WriteFile(path, []byte(data), 0o644)
+	history := rotate.Writer{Path: path, MaxBytes: logMaxBytes(), Perm: 0o600}
+	if err := history.Replace([]byte(data)); err != nil {
+		return err
+	}
+	// Files written before the history was private keep their mode otherwise
+	return os.Chmod(path, 0o600)
+}
+
+// ✅ Extra vars as kept in history, without secret-looking values
+// The history outlives the run, so a rerun has to be given those again.
+func historyExtraVars(vars []string) []string {
+	var kept []string
+	for _, v := range vars {
+		if v = executor.DropSecretVars(v); v != "" {
+			kept = append(kept, v)
+		}
+	}
+	return kept
 }
 
 // saveNewHistoryEntry adds a new entry to history
@@ -192,143 +847,762 @@ func saveNewHistoryEntry(inventoryFile string, playbooks []string, dryRun bool)
 		InventoryFile: inventoryFile,
 		Playbooks:     playbooks,
 		DryRun:        dryRun,
+		Tags:          runOpts.tags,
+		Limit:         runOpts.limit,
+		ExtraVars:     historyExtraVars(runOpts.extraVars),
+		Verbosity:     runOpts.verbosity,
+		Become:        runOpts.become,
+		BecomeMethod:  runOpts.becomeMethod,
+		BecomeUser:    runOpts.becomeUser,
 	}
 
 	currentHistory, err := loadHistory()
 	if err != nil {
-		fmt.Printf("⚠️ Could not load command history: %v\n", err)
+		output.Warnf("⚠️ Could not load command history: %v\n", err)
 		currentHistory = []CommandHistoryEntry{}
 	}
 
 	currentHistory = append(currentHistory, entry)
-	if len(currentHistory) > 5 {
-		currentHistory = currentHistory[len(currentHistory)-5:]
+	if len(currentHistory) > historySize() {
+		currentHistory = currentHistory[len(currentHistory)-historySize():]
 	}
 
 	if err := saveHistory(currentHistory); err != nil {
-		fmt.Printf("⚠️ Could not save command history: %v\n", err)
+		output.Warnf("⚠️ Could not save command history: %v\n", err)
 	}
 }
 
 // ✅ Ask user for inventory file or create one
-// ✅ Ask user for inventory file or create one
-func askForInventory(reader *bufio.Reader, instances *[]string) string {
-	fmt.Println("\n📂 Do you already have an inventory file? (yes/no)")
-	fmt.Print("> ")
-	response, _ := reader.ReadString('\n')
-	response = strings.TrimSpace(strings.ToLower(response))
+// created reports whether a new inventory file was written
+func askForInventory(reader *bufio.Reader, instances *[]inventory.HostConfig) (inventoryFile string, created bool, err error) {
+	output.Prompt("\n📂 Do you already have an inventory file? (yes/no)")
+	response, err := readAnswer(reader)
+	if err != nil {
+		return "", false, err
+	}
 
-	if response == "yes" {
-		fmt.Println("\n📍 Enter the path to your inventory file:")
-		fmt.Print("> ")
-		inventoryFile, _ := reader.ReadString('\n')
-		return strings.TrimSpace(inventoryFile)
+	if strings.ToLower(response) == "yes" {
+		output.Prompt("\n📍 Enter the path to your inventory file:")
+		inventoryFile, err := readAnswer(reader)
+		return inventoryFile, false, err
 	}
 
 	// ✅ No inventory file → Ask if user wants to auto-discover instances
-	fmt.Println("\n🔍 Do you want to auto-discover running Multipass/Docker instances? (yes/no)")
-	fmt.Print("> ")
-	response, _ = reader.ReadString('\n')
-	response = strings.TrimSpace(strings.ToLower(response))
+	output.Prompt("\n🔍 Do you want to auto-discover running Multipass/Docker/Vagrant/LXD instances? (yes/no)")
+	if response, err = readAnswer(reader); err != nil {
+		return "", false, err
+	}
 
-	if response == "yes" {
-		*instances = inventory.DiscoverInstances(reader) // ✅ Use `reader`
+	if strings.ToLower(response) == "yes" {
+		// ✅ Docker containers come back with the docker connection, not SSH
+		for _, instance := range inventory.DiscoverInstances(reader, runOpts.verify) {
+			*instances = append(*instances, instance.HostConfig())
+		}
 	} else {
-		fmt.Println("\n🖥️ Enter server IPs or DNS names (space-separated):")
-		fmt.Print("> ")
-		input, _ := reader.ReadString('\n')
-		*instances = strings.Fields(strings.TrimSpace(input))
+		output.Prompt("\n🖥️ Enter server IPs or DNS names (space-separated):")
+		input, err := readAnswer(reader)
+		if err != nil {
+			return "", false, err
+		}
+		for _, host := range strings.Fields(input) {
+			*instances = append(*instances, inventory.HostConfig{Host: host})
+		}
 	}
 
 	// ✅ Proceed with inventory creation
-	return createInventoryFile(reader, *instances) // ✅ Use `reader`
+	inventoryFile, err = createInventoryFile(reader, *instances) // ✅ Use `reader`
+	return inventoryFile, inventoryFile != "", err
 }
 
 // ✅ Create a new inventory file
-func createInventoryFile(reader *bufio.Reader, instances []string) string {
-	fmt.Println("\n📂 Where should the inventory file be saved? (Press Enter for current directory):")
-	fmt.Print("> ")
-	inventoryDir, _ := reader.ReadString('\n')
-	inventoryDir = strings.TrimSpace(inventoryDir)
+// An empty path without an error means the user chose not to write it
+func createInventoryFile(reader *bufio.Reader, instances []inventory.HostConfig) (string, error) {
+	// ✅ Once input has ended the remaining questions are skipped and inputErr is returned
+	var inputErr error
+	ask := func(question string) string {
+		if inputErr != nil {
+			return ""
+		}
+		output.Prompt(question)
+		answer, err := readAnswer(reader)
+		inputErr = err
+		return answer
+	}
+
+	// Checked in runPlaybook; an unknown name falls back to no defaults
+	template, _ := inventory.LookupTemplate(runOpts.template)
+
+	inventoryDir := ask("\n📂 Where should the inventory file be saved? (Press Enter for current directory):")
 	if inventoryDir == "" {
 		inventoryDir = "."
 	}
 
+	// ✅ Hosts behind a bastion usually share it, so it's asked for once
+	var proxyJump string
+	for _, instance := range instances {
+		if instance.UsesSSH() {
+			proxyJump = ask("\n🧱 Bastion (jump host) to reach the servers through, as [user@]host[:port] (Press Enter to connect directly):")
+			break
+		}
+	}
+
+	// ✅ With --auto-group, numbered names like web-1 are proposed as groups
+	var suggested map[string][]string
+	if runOpts.autoGroup {
+		names := make([]string, 0, len(instances))
+		for _, instance := range instances {
+			names = append(names, instance.Host)
+		}
+		suggested = inventory.InferGroups(names)
+		if len(suggested) > 0 {
+			output.Printf("\n🏷️ Suggested groups: %s\n", inventory.DescribeGroups(suggested))
+		}
+	}
+
 	// ✅ Configure each instance
 	hostConfigs := []inventory.HostConfig{}
 	for _, instance := range instances {
-		fmt.Printf("\n🖥️ Configuring %s\n", instance)
-
-		fmt.Println("\n👤 SSH user (e.g., ubuntu, root):")
-		fmt.Print("> ")
-		sshUser, _ := reader.ReadString('\n')
-		sshUser = strings.TrimSpace(sshUser)
-
-		fmt.Println("\n🔑 SSH private key file (Press Enter for default ~/.ssh/id_rsa):")
-		fmt.Print("> ")
-		sshKey, _ := reader.ReadString('\n')
-		sshKey = strings.TrimSpace(sshKey)
-		if sshKey == "" {
-			sshKey = "~/.ssh/id_rsa"
-		}
-
-		fmt.Println("\n📦 Server group (Press Enter to skip grouping):")
-		fmt.Print("> ")
-		group, _ := reader.ReadString('\n')
-		group = strings.TrimSpace(group)
-
-		fmt.Println("\n🔌 SSH port (Press Enter for default 22):")
-		fmt.Print("> ")
-		sshPort, _ := reader.ReadString('\n')
-		sshPort = strings.TrimSpace(sshPort)
-
-		fmt.Println("\n🔓 Enable sudo (become) for this server? (yes/no):")
-		fmt.Print("> ")
-		becomeInput, _ := reader.ReadString('\n')
-		become := strings.TrimSpace(strings.ToLower(becomeInput)) == "yes"
-
-		hostConfigs = append(hostConfigs, inventory.HostConfig{
-			Host:       instance,
-			Group:      group,
-			SSHUser:    sshUser,
-			SSHKeyFile: sshKey,
-			SSHPort:    sshPort,
-			Become:     become,
+		if inputErr != nil {
+			break
+		}
+		output.Printf("\n🖥️ Configuring %s\n", instance.Host)
+		host := template.Apply(instance) // Answers below override the template
+
+		// ✅ Windows hosts are managed over WinRM instead of SSH
+		if host.UsesSSH() && strings.ToLower(ask("\n🪟 Windows host, managed over WinRM? (yes/no):")) == "yes" {
+			host = host.Windows()
+		}
+
+		// ✅ SSH settings only apply to hosts reached over SSH, not e.g. docker containers
+		if host.Connection == inventory.ConnectionWinRM {
+			host.Address = ask("\n🌐 Connection address if different from the name (Press Enter to use the name):")
+			host.SSHUser = orDefault(ask(fmt.Sprintf("\n👤 WinRM user (Press Enter for %s):", orDefault(host.SSHUser, "Administrator"))), orDefault(host.SSHUser, "Administrator"))
+			host.Password = orDefault(ask(fmt.Sprintf("\n🔒 WinRM password (Press Enter to use %s from an ansible-vault file):", inventory.DefaultWinRMPassword)), host.Password)
+			if !strings.Contains(host.Password, "{{") {
+				output.Warnf("⚠️ The password is written to the inventory in plain text; consider ansible-vault\n")
+			}
+			host.WinRMTransport = orDefault(ask(fmt.Sprintf("\n🔐 WinRM transport: ntlm, kerberos, credssp, basic or certificate (Press Enter for %s):", host.WinRMTransport)), host.WinRMTransport)
+		} else if host.UsesSSH() {
+			host.Address = ask("\n🌐 Connection address if different from the name (Press Enter to use the name):")
+			if host.SSHUser == "" {
+				host.SSHUser = ask("\n👤 SSH user (e.g., ubuntu, root):")
+			} else {
+				host.SSHUser = orDefault(ask(fmt.Sprintf("\n👤 SSH user (Press Enter for %s):", host.SSHUser)), host.SSHUser)
+			}
+			defaultKey := orDefault(host.SSHKeyFile, "~/.ssh/id_rsa")
+			host.SSHKeyFile = orDefault(ask(fmt.Sprintf("\n🔑 SSH private key file (Press Enter for default %s):", defaultKey)), defaultKey)
+			host.ProxyJump = proxyJump
+		} else {
+			output.Printf("🔗 Using the %s connection, skipping SSH settings\n", host.Connection)
+		}
+
+		groupAnswer := ""
+		if suggestion := inventory.InferGroup(instance.Host); suggested != nil && suggestion != "" {
+			// Enter accepts the suggestion, - drops it, anything else replaces it
+			groupAnswer = orDefault(ask(fmt.Sprintf("\n📦 Server groups, space or comma separated (Press Enter for %s, - for no group):", suggestion)), suggestion)
+			if groupAnswer == "-" {
+				groupAnswer = ""
+			}
+		} else {
+			groupAnswer = ask("\n📦 Server groups, space or comma separated (Press Enter to skip grouping):")
+		}
+		groups := strings.FieldsFunc(groupAnswer, func(r rune) bool {
+			return r == ',' || r == ' '
 		})
+		if len(groups) > 0 {
+			host.Group, host.Groups = groups[0], groups[1:]
+		}
+
+		if host.Connection == inventory.ConnectionWinRM {
+			host.SSHPort = orDefault(ask(fmt.Sprintf("\n🔌 WinRM port (Press Enter for default %s):", host.SSHPort)), host.SSHPort)
+			hostConfigs = append(hostConfigs, host)
+			continue // sudo doesn't apply to Windows
+		}
+		if host.UsesSSH() {
+			host.SSHPort = ask("\n🔌 SSH port (Press Enter for default 22):")
+		}
+
+		host.Become = strings.ToLower(ask("\n🔓 Enable sudo (become) for this server? (yes/no):")) == "yes"
+
+		hostConfigs = append(hostConfigs, host)
+	}
+
+	// ✅ A host entered both with and without a group would be defined twice
+	if duplicates := inventory.UngroupedDuplicates(hostConfigs); len(duplicates) > 0 && inputErr == nil {
+		output.Warnf("⚠️ Entered both ungrouped and in a group: %s\n", strings.Join(duplicates, ", "))
+		if strings.ToLower(ask("\n🧩 Keep these hosts only in their groups? (yes/no, Press Enter for yes):")) != "no" {
+			hostConfigs = inventory.WithoutUngroupedDuplicates(hostConfigs)
+		}
+	}
+	if inputErr != nil {
+		return "", inputErr
+	}
+
+	if !runOpts.keepTilde {
+		hostConfigs = inventory.ExpandHostPaths(hostConfigs)
+	}
+
+	// ✅ Render the inventory so it can be previewed before writing
+	content, err := inventory.RenderInventory(hostConfigs)
+	if err != nil {
+		output.Errorf("❌ Error creating inventory file: %v\n", err)
+		os.Exit(1)
+	}
+
+	if runOpts.preview {
+		output.Printf("\n📝 Inventory preview:\n\n%s\n", content)
+		if strings.ToLower(ask("💾 Write this inventory? (yes/no)")) != "yes" {
+			output.Println("🚫 Inventory not written.")
+			return "", inputErr
+		}
 	}
 
-	// ✅ Create inventory file
-	inventoryFile, err := inventory.CreateInventoryFile(inventoryDir, hostConfigs)
+	// ✅ Create inventory file, replacing the default one if asked to
+	var inventoryFile string
+	if runOpts.overwrite {
+		inventoryFile = filepath.Join(inventoryDir, inventory.DefaultInventoryFilename)
+		err = inventory.OverwriteInventoryFile(inventoryFile, content, runOpts.backup)
+	} else {
+		inventoryFile, err = inventory.WriteInventoryFile(inventoryDir, content)
+	}
 	if err != nil {
-		fmt.Printf("❌ Error creating inventory file: %v\n", err)
+		output.Errorf("❌ Error creating inventory file: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("\n✅ Inventory file created at: %s\n", inventoryFile)
-	return inventoryFile
+	output.Printf("\n✅ Inventory file created at: %s\n", inventoryFile)
+	return inventoryFile, nil
+}
+
+// ✅ Use fallback when the answer is empty
+func orDefault(answer string, fallback string) string {
+	if answer == "" {
+		return fallback
+	}
+	return answer
 }
 
 // ✅ Ask user for playbooks to run
-func askForPlaybooks(reader *bufio.Reader) []string {
-	fmt.Println("\n📜 Enter playbooks to run (space-separated):")
-	fmt.Print("> ")
-	input, _ := reader.ReadString('\n')
-	return strings.Fields(strings.TrimSpace(input))
+// Asked again until at least one is given, as a run of nothing would pass
+func askForPlaybooks(reader *bufio.Reader) ([]string, error) {
+	for {
+		output.Prompt("\n📜 Enter playbooks to run (space-separated):")
+		input, err := readAnswer(reader)
+		if playbooks := strings.Fields(input); len(playbooks) > 0 || err != nil {
+			return playbooks, err
+		}
+		output.Warnf("⚠️ Enter at least one playbook\n")
+	}
+}
+
+// ✅ Let the user pick playbooks found in dir from a numbered menu
+// Falls back to typing names when the directory has no playbooks
+func selectPlaybooks(reader *bufio.Reader, dir string) ([]string, error) {
+	found, err := playbook.FromDir(dir)
+	if err != nil {
+		output.Warnf("⚠️ %v\n", err)
+		return askForPlaybooks(reader)
+	}
+
+	output.Printf("\n📜 Playbooks in %s:\n", dir)
+	for i, path := range found {
+		output.Printf("[%d] %s\n", i+1, filepath.Base(path))
+	}
+	for {
+		output.Prompt("\nSelect playbooks to run in order (space-separated numbers, or type 'all' for all):")
+		input, readErr := readAnswer(reader)
+		if readErr != nil {
+			return nil, readErr
+		}
+		selected, err := prompt.ParseSelection(input, found)
+		if err == nil && len(selected) == 0 {
+			err = errors.New("select at least one playbook")
+		}
+		if err == nil {
+			return selected, nil
+		}
+		output.Warnf("⚠️ %v\n", err)
+	}
+}
+
+// ✅ Ask for an optional file of extra variables, passed to ansible as `@file`
+func askForExtraVarsFile(reader *bufio.Reader) (string, error) {
+	output.Prompt("\n📎 Load extra vars from a file? (Enter a path or press Enter to skip):")
+	input, err := readAnswer(reader)
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimPrefix(input, "@")
+	if path == "" {
+		return "", nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		output.Warnf("⚠️ Ignoring extra vars file: %v\n", err)
+		return "", nil
+	}
+	return path, nil
 }
 
 // ✅ Ask if dry-run mode should be enabled
-func askForDryRun(reader *bufio.Reader) bool {
-	fmt.Println("\n🔍 Would you like to run this in dry-run mode? (yes/no)")
-	fmt.Print("> ")
-	response, _ := reader.ReadString('\n')
-	response = strings.TrimSpace(strings.ToLower(response))
-	if response == "yes" {
-		fmt.Println("✅ Dry-run mode enabled! Playbooks will simulate changes without applying them.")
+func askForDryRun(reader *bufio.Reader) (bool, error) {
+	output.Prompt("\n🔍 Would you like to run this in dry-run mode? (yes/no)")
+	response, err := readAnswer(reader)
+	if err != nil {
+		return false, err
+	}
+	if strings.ToLower(response) == "yes" {
+		output.Println("✅ Dry-run mode enabled! Playbooks will simulate changes without applying them.")
+		return true, nil
+	}
+	return false, nil
+}
+
+// ✅ Tell the user why the run stopped early and pass the error on
+func reportInputError(err error) error {
+	output.Errorf("\n❌ %v, nothing was run.\n", err)
+	return err
+}
+
+// ✅ Returned when stdin closes before a question is answered
+var errInputClosed = errors.New("input ended before all questions were answered")
+
+// ✅ Read one answer, trimmed
+// A final line without a newline still counts; reaching EOF without an
+// answer gives errInputClosed so prompts don't carry on with empty input
+func readAnswer(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	} else if err == io.EOF {
+		err = errInputClosed
+	} else if err != nil {
+		err = fmt.Errorf("error reading input: %w", err)
+	}
+	return strings.TrimSpace(line), err
+}
+
+// ✅ Build the executor options for one playbook from the run flags
+func playbookOptions(inventoryFile string, playbook string, dryRun bool) executor.Options {
+	limit := runOpts.limit
+	if runOpts.limitFailed {
+		limit = "@" + executor.RetryFile(playbook)
+	}
+
+	return executor.Options{
+		Inventory:    inventoryFile,
+		Playbook:     playbook,
+		DryRun:       dryRun,
+		Tags:         runOpts.tags,
+		Limit:        limit,
+		ExtraVars:    runOpts.extraVars,
+		Verbosity:    runOpts.verbosity,
+		Become:       runOpts.become,
+		BecomeMethod: runOpts.becomeMethod,
+		BecomeUser:   runOpts.becomeUser,
+
+		Binary:            runOpts.ansibleBin,
+		Forks:             runOpts.forks,
+		VaultPasswordFile: runOpts.vaultPassFile,
+		VaultIDs:          runOpts.vaultIDs,
+		Heartbeat:         runOpts.heartbeat,
+		SummaryOnly:       runOpts.summaryOnly,
+		AuditLog:          auditLogPath(),
+		AuditLogMaxBytes:  logMaxBytes(),
+		PrivateKey:        inventory.ExpandHome(runOpts.privateKey),
+		RemoteUser:        runOpts.remoteUser,
+		SSHTimeout:        runOpts.sshTimeout,
+		HostKeyChecking:   hostKeyChecking(),
+		ForceHandlers:     runOpts.forceHandlers,
+		WorkingDir:        inventory.ExpandHome(runOpts.chdir),
+
+		BecomePasswordFile: inventory.ExpandHome(runOpts.becomePassFile),
+		ExtraArgs:          runOpts.passthrough,
+		OnRecap:            printRecapResult,
+	}
+}
+
+// ✅ Say whether the playbook changed anything, from its PLAY RECAP
+func printRecapResult(recap executor.Recap) {
+	if recap.Result() == executor.ResultNoChanges {
+		output.Println("✨ No changes: every host reported changed=0")
+		return
+	}
+	output.Printf("🔄 %d change(s) on %s\n", recap.Changed(), strings.Join(recap.ChangedHosts(), ", "))
+}
+
+// ✅ Audit log from --audit-log, falling back to $GOSIBLE_AUDIT_LOG
+func auditLogPath() string {
+	if runOpts.auditLog != "" {
+		return inventory.ExpandHome(runOpts.auditLog)
+	}
+	return os.Getenv("GOSIBLE_AUDIT_LOG")
+}
+
+// ✅ Host key checking from --host-key-checking, already validated when the
+// run started; left to ansible's configuration when the flag isn't given
+func hostKeyChecking() executor.HostKeyChecking {
+	if runOpts.hostKeyCheck == "" {
+		return executor.HostKeyCheckingDefault
+	}
+	if enabled, _ := strconv.ParseBool(runOpts.hostKeyCheck); enabled {
+		return executor.HostKeyCheckingOn
+	}
+	return executor.HostKeyCheckingOff
+}
+
+// ✅ Size limit from --log-max-size for the audit log and history, already
+// validated when the run started; zero never rotates
+func logMaxBytes() int64 {
+	size, _ := rotate.ParseSize(runOpts.logMaxSize)
+	return size
+}
+
+// ✅ Report dynamic inventory scripts and optionally check their output
+// Scripts are passed to ansible-playbook unchanged, like static files
+func checkInventoryScript(inventoryFile string) bool {
+	if !inventory.IsExecutable(inventoryFile) {
+		return true
+	}
+
+	output.Printf("🧩 Using dynamic inventory script: %s\n", inventoryFile)
+	if !runOpts.validateScript {
+		return true
+	}
+	if err := inventory.ValidateInventoryScript(inventoryFile); err != nil {
+		output.Errorf("❌ %v\n", err)
+		return false
+	}
+	output.Println("✅ Inventory script emitted valid JSON")
+	return true
+}
+
+// ✅ Check what a run would reach before anything is executed, for new runs
+// and history reruns alike: the inventory has hosts, --limit matches some and
+// no play falls back to the implicit localhost
+func checkTargets(inventoryFile string, playbooks []string) error {
+	if !checkInventoryHosts(inventoryFile) {
+		return errors.New("inventory has no hosts")
+	}
+	if !checkLimit(inventoryFile) {
+		return errors.New("limit matches no hosts")
+	}
+	if !checkImplicitLocalhost(inventoryFile, playbooks) {
+		return errors.New("run would target the implicit localhost")
+	}
+	return nil
+}
+
+// ✅ Warn about static inventories without hosts and abort unless --force
+// Scripts and files the loader can't read are left for ansible to judge
+func checkInventoryHosts(inventoryFile string) bool {
+	if inventory.IsExecutable(inventoryFile) {
+		return true
+	}
+	inv, err := inventory.LoadInventory(inventoryFile)
+	if err != nil || len(inv.Hosts) > 0 {
+		return true
+	}
+
+	output.Warnf("⚠️ Inventory %s has no hosts, so playbooks would match nothing.\n", inventoryFile)
+	if runOpts.force || runOpts.allowLocalhost {
+		return true
+	}
+	output.Errorf("❌ Aborting. Add hosts to the inventory or pass --force to run anyway.\n")
+	return false
+}
+
+// ✅ With --validate-limit, check that --limit selects at least one host
+// Aborts unless --force when nothing matches. Dynamic inventories and
+// patterns that can't be resolved locally (e.g. `@file`) are left to ansible.
+func checkLimit(inventoryFile string) bool {
+	if !runOpts.validateLimit || runOpts.limit == "" || runOpts.limitFailed || inventory.IsExecutable(inventoryFile) {
+		return true
+	}
+	inv, err := inventory.LoadInventory(inventoryFile)
+	if err != nil {
+		return true
+	}
+	matches, err := inv.MatchHosts(runOpts.limit)
+	if err != nil || len(matches) > 0 {
+		return true
+	}
+
+	output.Warnf("⚠️ --limit %s matches no hosts in %s.\n", runOpts.limit, inventoryFile)
+	if runOpts.force {
+		return true
+	}
+	output.Errorf("❌ Aborting. Check the pattern for typos or pass --force to run anyway.\n")
+	return false
+}
+
+// ✅ Refuse plays or limits naming localhost when the inventory doesn't define it
+// Ansible then falls back to an implicit localhost and changes the operator's
+// own machine; --allow-localhost permits it
+func checkImplicitLocalhost(inventoryFile string, playbooks []string) bool {
+	if runOpts.allowLocalhost || inventory.IsExecutable(inventoryFile) {
+		return true
+	}
+	inv, err := inventory.LoadInventory(inventoryFile)
+	if err != nil {
+		return true // Ansible reports unreadable inventories itself
+	}
+	for _, host := range inv.Hosts {
+		if isLocalhost(host.Host) {
+			return true // Explicitly defined, so it isn't implicit
+		}
+	}
+
+	var sources []string
+	if namesLocalhost(runOpts.limit) {
+		sources = append(sources, "--limit "+runOpts.limit)
+	}
+	for _, pb := range playbooks {
+		patterns, err := playbook.Hosts(pb)
+		if err != nil {
+			continue // Left to the syntax check or ansible
+		}
+		for _, pattern := range patterns {
+			if namesLocalhost(pattern) {
+				sources = append(sources, pb)
+				break
+			}
+		}
+	}
+	if len(sources) == 0 {
 		return true
 	}
+
+	output.Errorf("❌ %s targets localhost, which %s doesn't define, so ansible would run against this machine.\n", strings.Join(sources, ", "), inventoryFile)
+	output.Errorf("   Add localhost to the inventory or pass --allow-localhost to run anyway.\n")
+	return false
+}
+
+// ✅ Extra var carrying --serial; `serial` is a play keyword ansible-playbook
+// has no flag for, so plays opt in with
+// `serial: "{{ gosible_serial | default('100%') }}"`
+const serialVar = "gosible_serial"
+
+// ✅ Turn --serial into a JSON extra var, keeping counts as numbers
+// Accepts a host count, a percentage, or a comma-separated list of them for
+// growing batches, e.g. `1,5,25%`
+func serialExtraVar(serial string) (string, error) {
+	var batches []any
+	for _, part := range strings.Split(serial, ",") {
+		part = strings.TrimSpace(part)
+		if n, err := strconv.Atoi(part); err == nil && n > 0 {
+			batches = append(batches, n)
+			continue
+		}
+		if percent, ok := strings.CutSuffix(part, "%"); ok {
+			if n, err := strconv.Atoi(percent); err == nil && n > 0 && n <= 100 {
+				batches = append(batches, part)
+				continue
+			}
+		}
+		return "", fmt.Errorf("invalid --serial %q: expected a host count, a percentage or a comma-separated list of them", serial)
+	}
+
+	var value any = batches
+	if len(batches) == 1 {
+		value = batches[0]
+	}
+	data, err := json.Marshal(map[string]any{serialVar: value})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ✅ Extra var carrying --max-fail-percentage; like serial it's a play keyword
+// without a flag, and a wrapper play can't set it on imported plays, so plays
+// opt in with `max_fail_percentage: "{{ gosible_max_fail_percentage | default(100) }}"`
+const maxFailVar = "gosible_max_fail_percentage"
+
+// ✅ Turn --max-fail-percentage into a JSON extra var
+func maxFailExtraVar(percent int) (string, error) {
+	if percent < 0 || percent > 100 {
+		return "", fmt.Errorf("invalid --max-fail-percentage %d: expected 0 to 100", percent)
+	}
+	return fmt.Sprintf(`{"%s":%d}`, maxFailVar, percent), nil
+}
+
+// ✅ Warn about playbooks that ignore --serial because no play reads gosible_serial
+func warnSerialUnused(playbooks []string) {
+	if runOpts.serial == "" {
+		return
+	}
+	warnVarUnused(playbooks, serialVar, "--serial", fmt.Sprintf("serial: \"{{ %s | default('100%%') }}\"", serialVar))
+}
+
+// ✅ Warn about playbooks that ignore --max-fail-percentage because no play
+// reads gosible_max_fail_percentage
+func warnMaxFailUnused(playbooks []string) {
+	if !runOpts.maxFailSet {
+		return
+	}
+	warnVarUnused(playbooks, maxFailVar, "--max-fail-percentage", fmt.Sprintf("max_fail_percentage: \"{{ %s | default(100) }}\"", maxFailVar))
+}
+
+// ✅ Warn about playbooks that never mention name, so flag has no effect on
+// them, suggesting the play keyword line that would read it
+func warnVarUnused(playbooks []string, name, flag, keyword string) {
+	for _, pb := range playbooks {
+		data, err := os.ReadFile(pb)
+		if err != nil || strings.Contains(string(data), name) {
+			continue // Unreadable playbooks are left to the syntax check or ansible
+		}
+		output.Warnf("⚠️ %s doesn't use %s, so %s has no effect on it. Add to its plays:\n", pb, name, flag)
+		output.Warnf("   %s\n", keyword)
+	}
+}
+
+// ✅ Report whether a host pattern names localhost, e.g. `web:localhost`
+func namesLocalhost(pattern string) bool {
+	for _, part := range strings.FieldsFunc(pattern, func(r rune) bool { return r == ',' || r == ':' }) {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "!") {
+			continue // Excluded, not targeted
+		}
+		if isLocalhost(strings.TrimPrefix(part, "&")) {
+			return true
+		}
+	}
 	return false
 }
 
+func isLocalhost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1"
+}
+
+// ✅ Explain why a run was switched to check mode
+func warnApplyPolicy() {
+	output.Warnf("🛡️ Apply policy in effect: running in check mode only. Pass --apply to make changes.\n")
+}
+
+// ✅ Ask for confirmation before applying changes, unless --yes was passed
+func confirmRun(reader *bufio.Reader, inventoryFile string, playbooks []string) bool {
+	if runOpts.yes {
+		return true
+	}
+
+	// Shown as a warning so the safety prompt survives --quiet
+	output.Warnf("\n⚠️ About to apply changes (not a dry run):\n")
+	output.Warnf("   Inventory: %s\n", inventoryFile)
+	output.Warnf("   Playbooks: %s\n", strings.Join(playbooks, " "))
+	if runOpts.limit != "" {
+		output.Warnf("   Limit: %s\n", runOpts.limit)
+	}
+	output.Warnf("\n❓ Proceed? (yes/no)\n> ")
+	response, _ := readAnswer(reader) // Closed input aborts
+	if strings.ToLower(response) != "yes" {
+		output.Warnf("🚫 Aborted, nothing was run.\n")
+		return false
+	}
+	return true
+}
+
+// ✅ Register the run flags on a flag set
+func bindRunFlags(flags *pflag.FlagSet, opts *runOptions) {
+	flags.StringVarP(&opts.inventory, "inventory", "i", "", "Inventory file, executable dynamic inventory script, or - to read YAML from stdin (skips the inventory prompts)")
+	flags.StringVar(&opts.hosts, "hosts", "", "Comma-separated hosts to run against through a temporary inventory, e.g. 10.0.0.1,10.0.0.2 (skips the inventory prompts)")
+	flags.StringArrayVarP(&opts.playbooks, "playbook", "p", nil, "Playbook to run, repeatable (skips the playbook prompt)")
+	flags.StringVar(&opts.playbookDir, "playbook-dir", "", "Run every *.yml playbook in a directory, in sorted order")
+	flags.StringVar(&opts.playbookList, "playbook-list", "", "File listing playbooks to run, one per line (# starts a comment)")
+	flags.BoolVar(&opts.listPlaybooks, "list-playbooks", false, "Pick playbooks from a menu of the playbooks/ directory instead of typing names")
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "Run playbooks in check mode (skips the dry-run prompt)")
+	flags.BoolVar(&opts.apply, "apply", false, "Apply changes (skips the dry-run prompt; required by --require-confirm-apply)")
+	flags.BoolVar(&opts.requireApply, "require-confirm-apply", false, "Run in check mode unless --apply is passed")
+	flags.StringVarP(&opts.tags, "tags", "t", "", "Only run plays and tasks tagged with these values (comma-separated)")
+	flags.StringVarP(&opts.limit, "limit", "l", "", "Limit the run to hosts matching this pattern")
+	flags.BoolVar(&opts.limitFailed, "limit-from-failed", false, "Only retry the hosts listed in each playbook's .retry file from a previous failed run")
+	flags.StringArrayVarP(&opts.extraVars, "extra-vars", "e", nil, "Extra variable as key=value, repeatable")
+	flags.CountVarP(&opts.verbosity, "verbose", "v", "Increase ansible verbosity (-v, -vv, -vvv, ...)")
+	flags.BoolVar(&opts.summaryOnly, "summary-only", false, "Only show the play recap and failures, not every task")
+	flags.BoolVar(&opts.failOnChange, "fail-on-change", false, "Exit non-zero when a dry run reports changed tasks (drift detection)")
+	flags.BoolVar(&opts.checkAndApply, "check-and-apply", false, "Dry-run all playbooks and apply them automatically if every check succeeds")
+	flags.BoolVar(&opts.installDeps, "install-deps", false, "Install the requirements.yml next to each playbook with ansible-galaxy before running")
+	flags.BoolVar(&opts.syntaxCheck, "check-syntax-before-run", false, "Run --syntax-check on every playbook first and stop if any fails")
+	flags.BoolVar(&opts.validateLimit, "validate-limit", false, "Check that --limit matches at least one inventory host before running")
+	flags.BoolVar(&opts.validateScript, "validate-inventory-script", false, "Check that a dynamic inventory script emits JSON for --list before running")
+	flags.BoolVarP(&opts.yes, "yes", "y", false, "Skip the confirmation prompt before applying changes")
+	flags.BoolVar(&opts.force, "force", false, "Run even when the inventory or --limit selects no hosts")
+	flags.BoolVar(&opts.allowLocalhost, "allow-localhost", false, "Allow plays to reach localhost when the inventory doesn't define it")
+	flags.BoolVarP(&opts.become, "become", "b", false, "Run every play with become (privilege escalation), regardless of per-host settings")
+	flags.StringVar(&opts.becomeMethod, "become-method", "", "Privilege escalation method to use with --become (e.g. sudo, su, doas)")
+	flags.StringVar(&opts.becomeUser, "become-user", "", "User to become with --become")
+	flags.StringVar(&opts.becomePassFile, "become-pass-file", "", "Read the become (sudo) password from this file instead of prompting")
+	flags.BoolVar(&opts.preview, "preview", false, "Preview a newly created inventory and confirm before writing it")
+	flags.BoolVar(&opts.verify, "verify", false, "Check that discovered instances are reachable and mark the ones that aren't")
+	flags.StringVar(&opts.template, "template", "", "Pre-fill new inventory hosts with cloud defaults: "+strings.Join(inventory.TemplateNames(), ", "))
+	flags.BoolVar(&opts.autoGroup, "auto-group", false, "Suggest groups from numbered instance names (web-1, web-2 → web) when creating an inventory")
+	flags.BoolVar(&opts.keepTilde, "keep-tilde", false, "Write ~ in SSH key paths literally instead of expanding it to the home directory")
+	flags.BoolVar(&opts.ephemeral, "ephemeral-inventory", false, "Delete an inventory created during the run once the playbooks finish")
+	flags.BoolVar(&opts.overwrite, "overwrite", false, "Write a new inventory to inv.yml, replacing an existing one")
+	flags.BoolVar(&opts.backup, "backup", false, "Keep a .bak copy of an inventory replaced by --overwrite")
+	flags.StringVar(&opts.ansibleBin, "ansible-bin", config.Default().AnsibleBin, "ansible-playbook executable to run")
+	flags.IntVar(&opts.forks, "forks", 0, "Number of parallel processes for ansible (0 uses ansible's default)")
+	flags.IntVar(&opts.maxFailPct, "max-fail-percentage", 0, "Abort a play once more than this percentage of its hosts (or of a --serial batch) fail, for plays that read gosible_max_fail_percentage (0 aborts on the first failure)")
+	flags.StringVar(&opts.serial, "serial", "", "Rolling batch size for plays that set serial from gosible_serial: a count, a percentage or a list like 1,5,25%")
+	flags.StringVar(&opts.vaultPassFile, "vault-password-file", "", "Vault password file passed to ansible")
+	flags.StringArrayVar(&opts.vaultIDs, "vault-id", nil, "Vault identity as label@source (e.g. prod@~/.vault_prod or dev@prompt), repeatable")
+	flags.DurationVar(&opts.heartbeat, "heartbeat", 0, "Print the elapsed time at this interval while a playbook runs, e.g. 30s (0 disables)")
+	flags.StringVar(&opts.privateKey, "private-key", "", "SSH private key to use instead of the inventory's per-host keys")
+	flags.StringVarP(&opts.remoteUser, "user", "u", "", "Connect as this SSH user instead of the inventory's ansible_user")
+	flags.StringVar(&opts.hostKeyCheck, "host-key-checking", "", "Force SSH host key checking on (true) or off (false, so new hosts don't hang on a prompt); ansible's configuration decides when unset")
+	flags.Lookup("host-key-checking").NoOptDefVal = "true"
+	flags.IntVar(&opts.sshTimeout, "ssh-timeout", 0, "SSH connection timeout in seconds passed to ansible as --timeout (0 uses ansible's default)")
+	flags.BoolVar(&opts.watch, "watch", false, "Run again whenever a playbook or the inventory file changes, in check mode unless --apply is passed")
+	flags.BoolVar(&opts.describe, "describe", false, "Explain what the run would do, with the inventory's hosts, without running anything")
+	flags.BoolVar(&opts.inventoryCheck, "inventory-check", false, "Show the groups and hosts ansible-inventory resolves from the inventory before running")
+	flags.StringVar(&opts.retryFile, "retry-file", defaultRetryFile, "File listing the playbooks that failed or didn't run, for --from-retry (empty disables)")
+	flags.StringVar(&opts.fromRetry, "from-retry", "", "Run only the playbooks listed in a retry file written by a failed run")
+	flags.BoolVar(&opts.keepGoing, "keep-going", false, "Run the remaining playbooks after one fails instead of stopping; the run still exits non-zero")
+	flags.StringVar(&opts.chdir, "chdir", "", "Run ansible-playbook in this directory, where its ansible.cfg and relative roles are found; gosible's own paths are unaffected")
+	flags.BoolVar(&opts.forceHandlers, "force-handlers", false, "Run notified handlers even if a task fails, e.g. to still restart a service")
+	flags.StringVar(&opts.playbookArgs, "playbook-args", "", "Extra ansible-playbook arguments, quoted like a shell (e.g. \"--skip-tags slow --flush-cache\"); arguments after -- are passed too")
+	flags.StringVar(&opts.report, "report", "", "Write a JSON summary of every playbook execution to this file (JUnit XML with --output-format junit)")
+	flags.StringVar(&opts.outputFormat, "output-format", outputFormatText, "Format of the run summary: text, json or junit (one test case per playbook, for CI)")
+	flags.StringVar(&opts.auditLog, "audit-log", "", "Append every executed command, with secrets redacted, to this file (default $GOSIBLE_AUDIT_LOG)")
+	flags.StringVar(&opts.logMaxSize, "log-max-size", "", "Rotate the audit log and history to gzipped <file>.1.gz copies once they pass this size, e.g. 10M (empty never rotates)")
+	flags.IntVar(&opts.historySize, "history-size", config.Default().HistorySize, "Number of previous commands to remember")
+	flags.BoolVar(&opts.sinceLast, "since-last", false, "Repeat the most recent command from history with its saved options, without prompting")
+	flags.BoolVar(&opts.pruneHistory, "prune-history", false, "Remove history entries whose inventory or playbooks no longer exist before offering them")
+}
+
+// ✅ Fill in config file defaults for flags the user didn't set
+func applyRunConfig(flags *pflag.FlagSet, cfg config.Config, opts *runOptions) {
+	if !flags.Changed("ansible-bin") && cfg.AnsibleBin != "" {
+		opts.ansibleBin = cfg.AnsibleBin
+	}
+	if !flags.Changed("forks") && cfg.Forks != 0 {
+		opts.forks = cfg.Forks
+	}
+	if !flags.Changed("vault-password-file") && cfg.VaultPasswordFile != "" {
+		opts.vaultPassFile = cfg.VaultPasswordFile
+	}
+	if !flags.Changed("history-size") && cfg.HistorySize != 0 {
+		opts.historySize = cfg.HistorySize
+	}
+	if !flags.Changed("log-max-size") && cfg.LogMaxSize != "" {
+		opts.logMaxSize = cfg.LogMaxSize
+	}
+	if !flags.Changed("require-confirm-apply") && cfg.RequireConfirmApply {
+		opts.requireApply = true
+	}
+}
+
 func init() {
+	bindRunFlags(runCmd.Flags(), &runOpts)
+	runCmd.RegisterFlagCompletionFunc("inventory", completeYAMLFiles)
+	runCmd.RegisterFlagCompletionFunc("playbook", completeYAMLFiles)
+	runCmd.MarkFlagsMutuallyExclusive("dry-run", "check-and-apply")
+	runCmd.MarkFlagsMutuallyExclusive("dry-run", "apply")
+	runCmd.MarkFlagsMutuallyExclusive("limit", "limit-from-failed")
+	runCmd.MarkFlagsMutuallyExclusive("since-last", "inventory")
+	runCmd.MarkFlagsMutuallyExclusive("since-last", "hosts")
+	runCmd.MarkFlagsMutuallyExclusive("hosts", "inventory")
+	runCmd.MarkFlagsMutuallyExclusive("since-last", "playbook")
+	runCmd.MarkFlagsMutuallyExclusive("watch", "since-last")
+	runCmd.MarkFlagsMutuallyExclusive("watch", "check-and-apply")
 	rootCmd.AddCommand(runCmd)
 }
@@ -0,0 +1,1470 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bxtal-lsn/gosible/internal/config"
+	"github.com/bxtal-lsn/gosible/internal/executor"
+	"github.com/bxtal-lsn/gosible/internal/inventory"
+	"github.com/spf13/pflag"
+)
+
+// ✅ Test that a declined inventory preview doesn't write a file
+func TestCreateInventoryFile_PreviewDeclined(t *testing.T) {
+	runOpts = runOptions{preview: true}
+	defer func() { runOpts = runOptions{} }()
+
+	dir := t.TempDir()
+	// Directory, bastion, then Windows, address, SSH user, key, group, port and become for the host, then decline
+	reader := bufio.NewReader(strings.NewReader(dir + "\n\nno\n\nubuntu\n\n\n\nno\nno\n"))
+
+	inventoryFile, err := createInventoryFile(reader, []inventory.HostConfig{{Host: "10.0.0.5"}})
+	if err != nil || inventoryFile != "" {
+		t.Errorf("Expected no inventory file, got %q", inventoryFile)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Could not read directory: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no files to be written, found %d", len(entries))
+	}
+}
+
+// ✅ Record playbook executions instead of running ansible
+func stubExecutor(t *testing.T) *[]executor.Options {
+	t.Helper()
+	return stubExecutorWith(t, func(executor.Options) error { return nil })
+}
+
+// ✅ Record playbook executions, returning result's error for each one
+func stubExecutorWith(t *testing.T, result func(executor.Options) error) *[]executor.Options {
+	t.Helper()
+	var calls []executor.Options
+	oldExecutePlaybook := executePlaybook
+	executePlaybook = func(opts executor.Options) error {
+		calls = append(calls, opts)
+		return result(opts)
+	}
+	t.Cleanup(func() { executePlaybook = oldExecutePlaybook })
+
+	// Keep history out of the real home directory
+	t.Setenv("HOME", t.TempDir())
+	return &calls
+}
+
+// ✅ Test that answering "no" to the confirmation aborts the run
+func TestRunPlaybooks_ConfirmDeclined(t *testing.T) {
+	calls := stubExecutor(t)
+
+	// Existing inventory, its path, playbooks, no vars file, no dry-run, then decline
+	reader := bufio.NewReader(strings.NewReader("yes\ninv.yml\nsite.yml\n\nno\nno\n"))
+	runPlaybooks(reader)
+
+	if len(*calls) != 0 {
+		t.Errorf("Expected the executor not to be called, got %v", *calls)
+	}
+}
+
+// ✅ Test that confirming runs the playbooks
+func TestRunPlaybooks_ConfirmAccepted(t *testing.T) {
+	calls := stubExecutor(t)
+
+	reader := bufio.NewReader(strings.NewReader("yes\ninv.yml\nsite.yml db.yml\n\nno\nyes\n"))
+	runPlaybooks(reader)
+
+	if len(*calls) != 2 || (*calls)[0].Playbook != "site.yml" || (*calls)[1].Playbook != "db.yml" {
+		t.Errorf("Expected both playbooks to run, got %v", *calls)
+	}
+}
+
+// ✅ Test that --yes skips the confirmation prompt
+func TestRunPlaybooks_Yes(t *testing.T) {
+	calls := stubExecutor(t)
+	runOpts = runOptions{yes: true}
+	defer func() { runOpts = runOptions{} }()
+
+	reader := bufio.NewReader(strings.NewReader("yes\ninv.yml\nsite.yml\n\nno\n"))
+	runPlaybooks(reader)
+
+	if len(*calls) != 1 {
+		t.Errorf("Expected the playbook to run without confirmation, got %v", *calls)
+	}
+}
+
+// ✅ Test that an executable inventory script is passed to the executor unchanged
+func TestRunPlaybooks_DynamicInventory(t *testing.T) {
+	calls := stubExecutor(t)
+	script := filepath.Join(t.TempDir(), "ec2.py")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho '{}'\n"), 0o755); err != nil {
+		t.Fatalf("Could not write script: %v", err)
+	}
+	runOpts = runOptions{inventory: script, yes: true}
+	defer func() { runOpts = runOptions{} }()
+
+	// Only the playbooks, vars file and dry-run questions remain
+	runPlaybooks(bufio.NewReader(strings.NewReader("site.yml\n\nno\n")))
+
+	if len(*calls) != 1 || (*calls)[0].Inventory != script {
+		t.Errorf("Expected the script %s to be used as the inventory, got %v", script, *calls)
+	}
+}
+
+// ✅ Test that docker hosts skip the SSH prompts during inventory creation
+func TestCreateInventoryFile_DockerSkipsSSH(t *testing.T) {
+	runOpts = runOptions{keepTilde: true}
+	defer func() { runOpts = runOptions{} }()
+	dir := t.TempDir()
+	hosts := []inventory.HostConfig{
+		{Host: "10.0.0.5"},
+		{Host: "container1", Connection: inventory.ConnectionDocker},
+	}
+	// Directory, bastion; SSH host: Windows, address, user, key, group, port, become; docker host: group, become
+	reader := bufio.NewReader(strings.NewReader(dir + "\n\nno\n\nubuntu\n\nweb\n\nno\napps\nyes\n"))
+
+	inventoryFile, _ := createInventoryFile(reader, hosts)
+	inv, err := inventory.LoadInventory(inventoryFile)
+	if err != nil {
+		t.Fatalf("LoadInventory returned error: %v", err)
+	}
+
+	// Groups are written in sorted order
+	expected := []inventory.HostConfig{
+		{Host: "container1", Group: "apps", Connection: inventory.ConnectionDocker, Become: true},
+		{Host: "10.0.0.5", Group: "web", SSHUser: "ubuntu", SSHKeyFile: "~/.ssh/id_rsa"},
+	}
+	if !reflect.DeepEqual(inv.Hosts, expected) {
+		t.Errorf("Expected hosts %+v, got %+v", expected, inv.Hosts)
+	}
+}
+
+// ✅ Test that --template pre-fills host settings and answers override it per host
+func TestCreateInventoryFile_Template(t *testing.T) {
+	runOpts = runOptions{keepTilde: true, template: "ec2"}
+	defer func() { runOpts = runOptions{} }()
+	dir := t.TempDir()
+	hosts := []inventory.HostConfig{{Host: "web1"}, {Host: "web2"}}
+	// Directory, bastion; per host: Windows, address, user, key, group, port, become
+	reader := bufio.NewReader(strings.NewReader(dir + "\n\nno\n\n\n\n\n\nno\nno\n\nadmin\n\n\n\nno\n"))
+
+	inventoryFile, err := createInventoryFile(reader, hosts)
+	if err != nil {
+		t.Fatalf("createInventoryFile returned error: %v", err)
+	}
+	inv, err := inventory.LoadInventory(inventoryFile)
+	if err != nil {
+		t.Fatalf("LoadInventory returned error: %v", err)
+	}
+
+	expected := []inventory.HostConfig{
+		{Host: "web1", SSHUser: "ec2-user", SSHKeyFile: "~/.ssh/ec2.pem", PythonInterpreter: "/usr/bin/python3"},
+		{Host: "web2", SSHUser: "admin", SSHKeyFile: "~/.ssh/ec2.pem", PythonInterpreter: "/usr/bin/python3"},
+	}
+	if !reflect.DeepEqual(inv.Hosts, expected) {
+		t.Errorf("Expected hosts %+v, got %+v", expected, inv.Hosts)
+	}
+}
+
+// ✅ Test that -b turns on ansible's global --become
+func TestRunFlags_BecomeShorthand(t *testing.T) {
+	var opts runOptions
+	flags := pflag.NewFlagSet("run", pflag.ContinueOnError)
+	bindRunFlags(flags, &opts)
+	if err := flags.Parse([]string{"-b"}); err != nil {
+		t.Fatalf("Could not parse flags: %v", err)
+	}
+
+	runOpts = opts
+	defer func() { runOpts = runOptions{} }()
+	args := executor.BuildArgs(playbookOptions("inv.yml", "site.yml", false))
+	if !reflect.DeepEqual(args[len(args)-1:], []string{"--become"}) {
+		t.Errorf("Expected --become to be appended, got %q", args)
+	}
+}
+
+// ✅ Test that --audit-log wins over $GOSIBLE_AUDIT_LOG
+func TestAuditLogPath(t *testing.T) {
+	t.Setenv("GOSIBLE_AUDIT_LOG", "/var/log/gosible/audit.log")
+	defer func() { runOpts = runOptions{} }()
+
+	runOpts = runOptions{}
+	if got := playbookOptions("inv.yml", "site.yml", false).AuditLog; got != "/var/log/gosible/audit.log" {
+		t.Errorf("Expected the environment audit log, got %q", got)
+	}
+	runOpts = runOptions{auditLog: "/tmp/audit.log"}
+	if got := playbookOptions("inv.yml", "site.yml", false).AuditLog; got != "/tmp/audit.log" {
+		t.Errorf("Expected the flag to win, got %q", got)
+	}
+}
+
+// ✅ Test that --host-key-checking forces checking either way and is left to
+// ansible's configuration when not given
+func TestHostKeyChecking(t *testing.T) {
+	defer func() { runOpts = runOptions{} }()
+	for _, tc := range []struct {
+		args     []string
+		expected executor.HostKeyChecking
+	}{
+		{nil, executor.HostKeyCheckingDefault},
+		{[]string{"--host-key-checking"}, executor.HostKeyCheckingOn},
+		{[]string{"--host-key-checking=false"}, executor.HostKeyCheckingOff},
+	} {
+		runOpts = runOptions{}
+		flags := pflag.NewFlagSet("run", pflag.ContinueOnError)
+		bindRunFlags(flags, &runOpts)
+		if err := flags.Parse(tc.args); err != nil {
+			t.Fatalf("Could not parse %v: %v", tc.args, err)
+		}
+		if got := playbookOptions("inv.yml", "site.yml", false).HostKeyChecking; got != tc.expected {
+			t.Errorf("Expected %v to give host key checking %d, got %d", tc.args, tc.expected, got)
+		}
+	}
+}
+
+// ✅ Test that config values fill unset flags and explicit flags win
+func TestApplyRunConfig(t *testing.T) {
+	var opts runOptions
+	flags := pflag.NewFlagSet("run", pflag.ContinueOnError)
+	bindRunFlags(flags, &opts)
+	if err := flags.Parse([]string{"--forks", "50"}); err != nil {
+		t.Fatalf("Could not parse flags: %v", err)
+	}
+
+	cfg := config.Config{
+		AnsibleBin:        "/opt/ansible/bin/ansible-playbook",
+		Forks:             10,
+		VaultPasswordFile: "~/.vault_pass",
+		HistorySize:       20,
+		LogMaxSize:        "10M",
+	}
+	applyRunConfig(flags, cfg, &opts)
+
+	if opts.ansibleBin != cfg.AnsibleBin {
+		t.Errorf("Expected ansible-bin from config, got %q", opts.ansibleBin)
+	}
+	if opts.vaultPassFile != cfg.VaultPasswordFile {
+		t.Errorf("Expected vault-password-file from config, got %q", opts.vaultPassFile)
+	}
+	if opts.historySize != 20 {
+		t.Errorf("Expected history-size 20 from config, got %d", opts.historySize)
+	}
+	if opts.logMaxSize != "10M" {
+		t.Errorf("Expected log-max-size 10M from config, got %q", opts.logMaxSize)
+	}
+	if opts.forks != 50 {
+		t.Errorf("Expected --forks to override the config, got %d", opts.forks)
+	}
+}
+
+// ✅ Test that built-in defaults apply without a config file
+func TestApplyRunConfig_Defaults(t *testing.T) {
+	var opts runOptions
+	flags := pflag.NewFlagSet("run", pflag.ContinueOnError)
+	bindRunFlags(flags, &opts)
+	applyRunConfig(flags, config.Default(), &opts)
+
+	if opts.ansibleBin != "ansible-playbook" || opts.historySize != 5 || opts.forks != 0 {
+		t.Errorf("Expected built-in defaults, got %+v", opts)
+	}
+}
+
+// ✅ Test that --inventory plus --playbook runs without prompting
+func TestRunPlaybooks_NonInteractive(t *testing.T) {
+	calls := stubExecutor(t)
+	runOpts = runOptions{inventory: "inv.yml", playbooks: []string{"site.yml", "db.yml"}, dryRun: true}
+	defer func() { runOpts = runOptions{} }()
+
+	runPlaybooks(bufio.NewReader(strings.NewReader("")))
+
+	if len(*calls) != 2 {
+		t.Fatalf("Expected 2 playbook runs, got %d", len(*calls))
+	}
+	for _, call := range *calls {
+		if !call.DryRun || call.Inventory != "inv.yml" {
+			t.Errorf("Unexpected options: %+v", call)
+		}
+	}
+}
+
+// ✅ Test that a failed check aborts before anything is applied
+func TestRunPlaybooks_CheckAndApplyCheckFails(t *testing.T) {
+	calls := stubExecutorWith(t, func(opts executor.Options) error {
+		if opts.Playbook == "db.yml" {
+			return errors.New("exit status 2")
+		}
+		return nil
+	})
+	runOpts = runOptions{inventory: "inv.yml", playbooks: []string{"site.yml", "db.yml"}, checkAndApply: true}
+	defer func() { runOpts = runOptions{} }()
+
+	if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err == nil {
+		t.Error("Expected an error when the check fails")
+	}
+	for _, call := range *calls {
+		if !call.DryRun {
+			t.Errorf("Expected no real run after a failed check, got %+v", call)
+		}
+	}
+	if len(*calls) != 2 {
+		t.Errorf("Expected 2 check runs, got %d", len(*calls))
+	}
+}
+
+// ✅ Test that passing checks are followed by the real run
+func TestRunPlaybooks_CheckAndApplySucceeds(t *testing.T) {
+	calls := stubExecutor(t)
+	runOpts = runOptions{inventory: "inv.yml", playbooks: []string{"site.yml", "db.yml"}, checkAndApply: true}
+	defer func() { runOpts = runOptions{} }()
+
+	if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var dryRuns []bool
+	for _, call := range *calls {
+		dryRuns = append(dryRuns, call.DryRun)
+	}
+	if fmt.Sprint(dryRuns) != "[true true false false]" {
+		t.Errorf("Expected two checks followed by two real runs, got %v", dryRuns)
+	}
+}
+
+// ✅ Test that --playbook entries come before list and directory playbooks
+func TestCollectPlaybooks(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.yml", "a.yml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list := filepath.Join(t.TempDir(), "list.txt")
+	if err := os.WriteFile(list, []byte("# first\nlisted.yml\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	playbooks, err := collectPlaybooks(runOptions{playbooks: []string{"site.yml"}, playbookList: list, playbookDir: dir})
+	if err != nil {
+		t.Fatalf("collectPlaybooks returned error: %v", err)
+	}
+
+	expected := []string{"site.yml", "listed.yml", filepath.Join(dir, "a.yml"), filepath.Join(dir, "b.yml")}
+	if strings.Join(playbooks, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, playbooks)
+	}
+}
+
+// ✅ Test that tags and limit survive a save/load round trip
+func TestHistory_RoundTripOptions(t *testing.T) {
+	stubExecutor(t)
+	runOpts = runOptions{tags: "deploy", limit: "web", extraVars: []string{"version=2"}, verbosity: 2}
+	defer func() { runOpts = runOptions{} }()
+
+	saveNewHistoryEntry("inv.yml", []string{"site.yml"}, true)
+	entries, err := loadHistory()
+	if err != nil {
+		t.Fatalf("loadHistory returned error: %v", err)
+	}
+
+	expected := CommandHistoryEntry{
+		InventoryFile: "inv.yml",
+		Playbooks:     []string{"site.yml"},
+		DryRun:        true,
+		Tags:          "deploy",
+		Limit:         "web",
+		ExtraVars:     []string{"version=2"},
+		Verbosity:     2,
+	}
+	if len(entries) != 1 || fmt.Sprintf("%+v", entries[0]) != fmt.Sprintf("%+v", expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entries)
+	}
+}
+
+// ✅ Test that entries written before options were recorded still load
+func TestHistory_LoadsOldEntries(t *testing.T) {
+	stubExecutor(t)
+	path, _ := getHistoryPath()
+	old := `{"inventory_file":"inv.yml","playbooks":["site.yml"],"dry_run":false}`
+	if err := os.WriteFile(path, []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := loadHistory()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected one entry, got %v (%v)", entries, err)
+	}
+	if entries[0].Tags != "" || entries[0].Limit != "" || entries[0].ExtraVars != nil {
+		t.Errorf("Expected empty options for an old entry, got %+v", entries[0])
+	}
+}
+
+// ✅ Test that reusing a history entry passes its options to the executor
+func TestRunPlaybooks_ReuseHistoryOptions(t *testing.T) {
+	calls := stubExecutor(t)
+	runOpts = runOptions{tags: "deploy", limit: "web", verbosity: 1}
+	saveNewHistoryEntry("inv.yml", []string{"site.yml"}, true)
+	runOpts = runOptions{}
+	defer func() { runOpts = runOptions{} }()
+
+	runPlaybooks(bufio.NewReader(strings.NewReader("1\n")))
+
+	if len(*calls) != 1 {
+		t.Fatalf("Expected one playbook run, got %v", *calls)
+	}
+	call := (*calls)[0]
+	if call.Tags != "deploy" || call.Limit != "web" || call.Verbosity != 1 || !call.DryRun {
+		t.Errorf("Expected the history options to be reused, got %+v", call)
+	}
+}
+
+// ✅ Test that --since-last reruns the newest entry with its options and no prompts
+func TestRunPlaybooks_SinceLast(t *testing.T) {
+	calls := stubExecutor(t)
+	defer func() { runOpts = runOptions{} }()
+
+	runOpts = runOptions{sinceLast: true}
+	captureStderr(t, func() {
+		if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err == nil {
+			t.Error("Expected an error without history")
+		}
+	})
+
+	runOpts = runOptions{tags: "old"}
+	saveNewHistoryEntry("old.yml", []string{"old.yml"}, true)
+	runOpts = runOptions{tags: "deploy", limit: "web", extraVars: []string{"v=2"}, become: true}
+	saveNewHistoryEntry("inv.yml", []string{"site.yml", "db.yml"}, false)
+	runOpts = runOptions{sinceLast: true}
+
+	// No input: an apply would be aborted if confirmation were asked
+	if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err != nil {
+		t.Fatalf("runPlaybooks returned error: %v", err)
+	}
+	if len(*calls) != 2 {
+		t.Fatalf("Expected both playbooks of the last entry to run, got %+v", *calls)
+	}
+	call := (*calls)[1]
+	if call.Inventory != "inv.yml" || call.Playbook != "db.yml" || call.DryRun || call.Tags != "deploy" ||
+		call.Limit != "web" || !reflect.DeepEqual(call.ExtraVars, []string{"v=2"}) || !call.Become {
+		t.Errorf("Expected the saved options to be reused, got %+v", call)
+	}
+}
+
+// ✅ Test that --serial becomes a typed JSON extra var and is validated
+func TestSerialExtraVar(t *testing.T) {
+	for serial, expected := range map[string]string{
+		"2":        `{"gosible_serial":2}`,
+		"25%":      `{"gosible_serial":"25%"}`,
+		"1, 5,25%": `{"gosible_serial":[1,5,"25%"]}`,
+	} {
+		got, err := serialExtraVar(serial)
+		if err != nil || got != expected {
+			t.Errorf("serialExtraVar(%q) = %s, %v; expected %s", serial, got, err, expected)
+		}
+		if err := executor.ValidateExtraVars([]string{got}); err != nil {
+			t.Errorf("Expected %s to be a valid extra var: %v", got, err)
+		}
+	}
+	for _, serial := range []string{"0", "abc", "150%", "1,,2"} {
+		if _, err := serialExtraVar(serial); err == nil {
+			t.Errorf("Expected an error for %q", serial)
+		}
+	}
+}
+
+// ✅ Test the warning for playbooks that don't read gosible_serial
+func TestWarnSerialUnused(t *testing.T) {
+	dir := t.TempDir()
+	rolling := filepath.Join(dir, "rolling.yml")
+	plain := filepath.Join(dir, "plain.yml")
+	os.WriteFile(rolling, []byte("- hosts: web\n  serial: \"{{ gosible_serial | default('100%') }}\"\n"), 0o644)
+	os.WriteFile(plain, []byte("- hosts: web\n"), 0o644)
+	runOpts = runOptions{serial: "2"}
+	defer func() { runOpts = runOptions{} }()
+
+	stderr := captureStderr(t, func() { warnSerialUnused([]string{rolling, plain}) })
+	if strings.Contains(stderr, rolling) || !strings.Contains(stderr, plain+" doesn't use gosible_serial") {
+		t.Errorf("Expected a warning for %s only, got:\n%s", plain, stderr)
+	}
+}
+
+// ✅ Test that --max-fail-percentage becomes an extra var and warns about
+// playbooks that don't read it
+func TestMaxFailPercentage(t *testing.T) {
+	got, err := maxFailExtraVar(25)
+	if err != nil || got != `{"gosible_max_fail_percentage":25}` {
+		t.Errorf("maxFailExtraVar(25) = %s, %v", got, err)
+	}
+	if err := executor.ValidateExtraVars([]string{got}); err != nil {
+		t.Errorf("Expected %s to be a valid extra var: %v", got, err)
+	}
+	for _, percent := range []int{-1, 101} {
+		if _, err := maxFailExtraVar(percent); err == nil {
+			t.Errorf("Expected an error for %d", percent)
+		}
+	}
+
+	dir := t.TempDir()
+	guarded := filepath.Join(dir, "guarded.yml")
+	plain := filepath.Join(dir, "plain.yml")
+	os.WriteFile(guarded, []byte("- hosts: web\n  max_fail_percentage: \"{{ gosible_max_fail_percentage | default(100) }}\"\n"), 0o644)
+	os.WriteFile(plain, []byte("- hosts: web\n"), 0o644)
+	runOpts = runOptions{maxFailPct: 25, maxFailSet: true}
+	defer func() { runOpts = runOptions{} }()
+
+	stderr := captureStderr(t, func() { warnMaxFailUnused([]string{guarded, plain}) })
+	if strings.Contains(stderr, guarded) || !strings.Contains(stderr, plain+" doesn't use gosible_max_fail_percentage") ||
+		!strings.Contains(stderr, "max_fail_percentage: \"{{ gosible_max_fail_percentage | default(100) }}\"") {
+		t.Errorf("Expected a warning with the play keyword for %s only, got:\n%s", plain, stderr)
+	}
+}
+
+// ✅ Test that --max-fail-percentage 0 is passed on to abort on the first
+// failure rather than read as unset
+func TestMaxFailPercentage_Zero(t *testing.T) {
+	calls := stubExecutor(t)
+	defer func() {
+		runOpts = runOptions{}
+		runCmd.Flags().Visit(func(f *pflag.Flag) { f.Changed = false })
+		rootCmd.SetArgs(nil)
+	}()
+
+	// Earlier tests reset runOpts, so the flag defaults the run checks are restored
+	runOpts = runOptions{outputFormat: outputFormatText}
+	rootCmd.SetArgs([]string{"run", "-i", "inv.yml", "-p", "site.yml", "--max-fail-percentage", "0", "--yes"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if len(*calls) != 1 || !strings.Contains(strings.Join((*calls)[0].ExtraVars, " "), `{"gosible_max_fail_percentage":0}`) {
+		t.Errorf("Expected a max fail percentage of 0 to be passed on, got %+v", *calls)
+	}
+	if !strings.Contains(describeRun("inv.yml", []string{"site.yml"}), "more than 0% of its hosts fail") {
+		t.Error("Expected --describe to mention the max fail percentage")
+	}
+}
+
+// ✅ Test that the apply policy forces check mode without --apply
+func TestRunPlaybooks_RequireApply(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		apply       bool
+		expectCheck bool
+	}{
+		{"without --apply", false, true},
+		{"with --apply", true, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := stubExecutor(t)
+			runOpts = runOptions{inventory: "inv.yml", playbooks: []string{"site.yml"}, requireApply: true, apply: tc.apply, yes: true}
+			defer func() { runOpts = runOptions{} }()
+
+			runPlaybooks(bufio.NewReader(strings.NewReader("")))
+
+			if len(*calls) != 1 || (*calls)[0].DryRun != tc.expectCheck {
+				t.Errorf("Expected one run with check=%t, got %+v", tc.expectCheck, *calls)
+			}
+		})
+	}
+}
+
+// ✅ Test that the policy also holds for interactive runs and history reuse
+func TestRunPlaybooks_RequireApplyInteractive(t *testing.T) {
+	calls := stubExecutor(t)
+	runOpts = runOptions{requireApply: true}
+	defer func() { runOpts = runOptions{} }()
+
+	// No dry-run question is asked and no rerun is offered
+	runPlaybooks(bufio.NewReader(strings.NewReader("yes\ninv.yml\nsite.yml\n\n")))
+	// Reuse an entry recorded without dry-run
+	saveNewHistoryEntry("inv.yml", []string{"site.yml"}, false)
+	runPlaybooks(bufio.NewReader(strings.NewReader("1\nyes\n")))
+
+	if len(*calls) != 2 {
+		t.Fatalf("Expected two runs, got %+v", *calls)
+	}
+	for _, call := range *calls {
+		if !call.DryRun {
+			t.Errorf("Expected check mode under the apply policy, got %+v", call)
+		}
+	}
+}
+
+// ✅ Test the history path fallbacks when HOME is unavailable
+func TestGetHistoryPath_NoHome(t *testing.T) {
+	t.Setenv("HOME", "")
+	t.Setenv("GOSIBLE_HISTORY_FILE", "")
+	state := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", state)
+
+	path, err := getHistoryPath()
+	if err != nil || path != filepath.Join(state, "gosible", "history") {
+		t.Errorf("Expected the XDG state path, got %q (%v)", path, err)
+	}
+
+	// The state directory is created on first save
+	saveNewHistoryEntry("inv.yml", []string{"site.yml"}, false)
+	if entries, err := loadHistory(); err != nil || len(entries) != 1 {
+		t.Errorf("Expected the entry to be saved under XDG_STATE_HOME, got %v (%v)", entries, err)
+	}
+
+	explicit := filepath.Join(t.TempDir(), "history")
+	t.Setenv("GOSIBLE_HISTORY_FILE", explicit)
+	if path, _ := getHistoryPath(); path != explicit {
+		t.Errorf("Expected GOSIBLE_HISTORY_FILE to win, got %q", path)
+	}
+}
+
+// ✅ Test that the user cache directory comes before the shared temp dir, and
+// that a temp path someone else could have planted is refused
+func TestGetHistoryPath_Fallbacks(t *testing.T) {
+	t.Setenv("HOME", "")
+	t.Setenv("GOSIBLE_HISTORY_FILE", "")
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	if path, err := getHistoryPath(); err != nil || path != filepath.Join(cache, "gosible", "history") {
+		t.Errorf("Expected the user cache path, got %q (%v)", path, err)
+	}
+
+	t.Setenv("XDG_CACHE_HOME", "")
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	shared := filepath.Join(tmp, fmt.Sprintf("gosible_history_%d", os.Getuid()))
+	if path, err := getHistoryPath(); err != nil || path != shared {
+		t.Errorf("Expected the temp path while it's missing, got %q (%v)", path, err)
+	}
+
+	if err := os.Symlink(filepath.Join(t.TempDir(), "victim"), shared); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := getHistoryPath(); err == nil {
+		t.Errorf("Expected a planted symlink to be refused, got %q", path)
+	}
+
+	os.Remove(shared)
+	if err := os.WriteFile(shared, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := getHistoryPath(); err != nil || path != shared {
+		t.Errorf("Expected the user's own file to be used, got %q (%v)", path, err)
+	}
+}
+
+// ✅ Test that history is disabled quietly when no location is usable
+func TestHistory_Disabled(t *testing.T) {
+	t.Setenv("HOME", "")
+	t.Setenv("GOSIBLE_HISTORY_FILE", "")
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "missing"))
+
+	if path, err := getHistoryPath(); path != "" || err != nil {
+		t.Fatalf("Expected history to be disabled, got %q (%v)", path, err)
+	}
+
+	stderr := captureStderr(t, func() {
+		saveNewHistoryEntry("inv.yml", []string{"site.yml"}, false)
+		if entries, err := loadHistory(); err != nil || len(entries) != 0 {
+			t.Errorf("Expected no history, got %v (%v)", entries, err)
+		}
+	})
+	if stderr != "" {
+		t.Errorf("Expected no warnings, got %q", stderr)
+	}
+}
+
+// ✅ Capture what f writes to stderr
+func captureStderr(t *testing.T, f func()) string {
+	t.Helper()
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	f()
+	w.Close()
+	os.Stderr = oldStderr
+
+	data, _ := io.ReadAll(r)
+	return string(data)
+}
+
+// ✅ Test that --auto-group suggests groups that can be accepted, replaced or dropped
+func TestCreateInventoryFile_AutoGroup(t *testing.T) {
+	runOpts = runOptions{keepTilde: true, autoGroup: true}
+	defer func() { runOpts = runOptions{} }()
+	dir := t.TempDir()
+	hosts := []inventory.HostConfig{{Host: "web-1"}, {Host: "web-2"}, {Host: "db-1"}}
+	// Directory, bastion; per host: Windows, address, user, key, group, port, become
+	reader := bufio.NewReader(strings.NewReader(dir + "\n\n" +
+		"no\n\nubuntu\n\n\n\nno\n" +
+		"no\n\nubuntu\n\n-\n\nno\n" +
+		"no\n\nubuntu\n\npostgres\n\nno\n"))
+
+	inventoryFile, _ := createInventoryFile(reader, hosts)
+	inv, err := inventory.LoadInventory(inventoryFile)
+	if err != nil {
+		t.Fatalf("LoadInventory returned error: %v", err)
+	}
+
+	groups := map[string]string{}
+	for _, host := range inv.Hosts {
+		groups[host.Host] = host.Group
+	}
+	expected := map[string]string{"web-1": "web", "web-2": "", "db-1": "postgres"}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("Expected groups %v, got %v", expected, groups)
+	}
+}
+
+// ✅ Test that a host entered with and without a group is kept only in its
+// group unless the user declines
+func TestCreateInventoryFile_UngroupedCollision(t *testing.T) {
+	runOpts = runOptions{keepTilde: true}
+	defer func() { runOpts = runOptions{} }()
+	hosts := []inventory.HostConfig{{Host: "web1"}, {Host: "web1"}}
+
+	for answer, expectedGroups := range map[string][]string{"": {"web"}, "no": {"", "web"}} {
+		dir := t.TempDir()
+		// Directory, bastion; per host: Windows, address, user, key, group, port, become; then keep only grouped
+		reader := bufio.NewReader(strings.NewReader(dir + "\n\n" +
+			"no\n\nubuntu\n\n\n\nno\n" +
+			"no\n\nubuntu\n\nweb\n\nno\n" +
+			answer + "\n"))
+
+		var inventoryFile string
+		stderr := captureStderr(t, func() { inventoryFile, _ = createInventoryFile(reader, hosts) })
+		if !strings.Contains(stderr, "Entered both ungrouped and in a group: web1") {
+			t.Errorf("Expected a collision warning, got %q", stderr)
+		}
+		inv, err := inventory.LoadInventory(inventoryFile)
+		if err != nil {
+			t.Fatalf("LoadInventory returned error: %v", err)
+		}
+		var groups []string
+		for _, host := range inv.Hosts {
+			groups = append(groups, host.Group)
+		}
+		if !reflect.DeepEqual(groups, expectedGroups) {
+			t.Errorf("Answer %q: expected web1 in groups %q, got %q", answer, expectedGroups, groups)
+		}
+	}
+}
+
+// ✅ Test that a Windows host is configured for WinRM without SSH or sudo prompts
+func TestCreateInventoryFile_Windows(t *testing.T) {
+	dir := t.TempDir()
+	// Directory, bastion, then Windows, address, user, password, transport, group and port
+	reader := bufio.NewReader(strings.NewReader(dir + "\n\nyes\n10.0.0.9\n\n\nkerberos\nwindows\n\n"))
+
+	inventoryFile, err := createInventoryFile(reader, []inventory.HostConfig{{Host: "win1"}})
+	if err != nil {
+		t.Fatalf("createInventoryFile returned error: %v", err)
+	}
+	data, err := os.ReadFile(inventoryFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, line := range []string{
+		"ansible_connection: winrm", "ansible_winrm_transport: kerberos", "ansible_user: Administrator",
+		"ansible_password: '{{ vault_winrm_password }}'", "ansible_port: 5986",
+	} {
+		if !strings.Contains(content, line) {
+			t.Errorf("Expected %q in the inventory, got:\n%s", line, content)
+		}
+	}
+	if strings.Contains(content, "ansible_ssh_private_key_file") || strings.Contains(content, "ansible_become") {
+		t.Errorf("Expected no SSH key or become for a Windows host, got:\n%s", content)
+	}
+}
+
+// ✅ Test that a connection address and bastion entered for a host are kept
+func TestCreateInventoryFile_Address(t *testing.T) {
+	dir := t.TempDir()
+	// Directory, bastion, then Windows, address, SSH user, key, group, port and become
+	reader := bufio.NewReader(strings.NewReader(dir + "\nbastion\nno\n10.0.0.5\nubuntu\n/keys/web\n\n\nno\n"))
+
+	inventoryFile, _ := createInventoryFile(reader, []inventory.HostConfig{{Host: "web1"}})
+	inv, err := inventory.LoadInventory(inventoryFile)
+	if err != nil {
+		t.Fatalf("LoadInventory returned error: %v", err)
+	}
+
+	expected := inventory.HostConfig{Host: "web1", Address: "10.0.0.5", SSHUser: "ubuntu", SSHKeyFile: "/keys/web", ProxyJump: "bastion"}
+	if len(inv.Hosts) != 1 || !reflect.DeepEqual(inv.Hosts[0], expected) {
+		t.Errorf("Expected %+v, got %+v", expected, inv.Hosts)
+	}
+}
+
+// ✅ Test that a run left without an inventory fails rather than succeeding
+func TestRunPlaybooks_NoInventory(t *testing.T) {
+	calls := stubExecutor(t)
+	runOpts = runOptions{}
+	defer func() { runOpts = runOptions{} }()
+
+	// An existing inventory, but an empty path
+	var err error
+	captureStderr(t, func() {
+		err = runPlaybooks(bufio.NewReader(strings.NewReader("yes\n\n")))
+	})
+	if err == nil || len(*calls) != 0 {
+		t.Errorf("Expected the aborted run to fail, got %v and calls %v", err, *calls)
+	}
+}
+
+// ✅ Test that an inventory without hosts aborts the run unless --force is set
+func TestRunPlaybooks_EmptyInventory(t *testing.T) {
+	calls := stubExecutor(t)
+	path := filepath.Join(t.TempDir(), "inv.yml")
+	if err := os.WriteFile(path, []byte("---\nall:\n  hosts:\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runOpts = runOptions{inventory: path, playbooks: []string{"site.yml"}, yes: true}
+	defer func() { runOpts = runOptions{} }()
+
+	var err error
+	stderr := captureStderr(t, func() {
+		err = runPlaybooks(bufio.NewReader(strings.NewReader("")))
+	})
+	if err == nil || len(*calls) != 0 {
+		t.Errorf("Expected the run to abort, got %v and calls %v", err, *calls)
+	}
+	if !strings.Contains(stderr, "has no hosts") || !strings.Contains(stderr, "--force") {
+		t.Errorf("Expected a helpful message, got %q", stderr)
+	}
+
+	runOpts.force = true
+	captureStderr(t, func() {
+		err = runPlaybooks(bufio.NewReader(strings.NewReader("")))
+	})
+	if err != nil || len(*calls) != 1 {
+		t.Errorf("Expected --force to run anyway, got %v and calls %v", err, *calls)
+	}
+}
+
+// ✅ Test that rerunning history against an inventory emptied since is blocked
+// like a new run
+func TestRunPlaybooks_SinceLastEmptyInventory(t *testing.T) {
+	calls := stubExecutor(t)
+	path := filepath.Join(t.TempDir(), "inv.yml")
+	if err := os.WriteFile(path, []byte("---\nall:\n  hosts:\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { runOpts = runOptions{} }()
+
+	runOpts = runOptions{}
+	saveNewHistoryEntry(path, []string{"site.yml"}, true)
+	runOpts = runOptions{sinceLast: true}
+
+	var err error
+	stderr := captureStderr(t, func() {
+		err = runPlaybooks(bufio.NewReader(strings.NewReader("")))
+	})
+	if err == nil || len(*calls) != 0 {
+		t.Errorf("Expected the rerun to abort, got %v and calls %v", err, *calls)
+	}
+	if !strings.Contains(stderr, "has no hosts") {
+		t.Errorf("Expected the empty inventory to be reported, got %q", stderr)
+	}
+}
+
+// ✅ Test that --become-pass-file reaches the executor but not the history
+func TestRunPlaybooks_BecomePassFile(t *testing.T) {
+	calls := stubExecutor(t)
+	history := filepath.Join(t.TempDir(), "history")
+	t.Setenv("GOSIBLE_HISTORY_FILE", history)
+	passFile := filepath.Join(t.TempDir(), "sudo_pass")
+	if err := os.WriteFile(passFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	runOpts = runOptions{inventory: "inv.yml", playbooks: []string{"site.yml"}, become: true, becomePassFile: passFile, yes: true}
+	defer func() { runOpts = runOptions{} }()
+
+	if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err != nil {
+		t.Fatalf("runPlaybooks returned error: %v", err)
+	}
+	if len(*calls) != 1 || (*calls)[0].BecomePasswordFile != passFile {
+		t.Errorf("Expected the password file to be passed on, got %+v", *calls)
+	}
+
+	data, err := os.ReadFile(history)
+	if err != nil {
+		t.Fatalf("Could not read history: %v", err)
+	}
+	if strings.Contains(string(data), passFile) || strings.Contains(string(data), "s3cret") {
+		t.Errorf("Expected the password file to stay out of history, got %s", data)
+	}
+}
+
+// ✅ Test that --install-deps installs requirements.yml before the playbook runs
+func TestRunPlaybooks_InstallDeps(t *testing.T) {
+	var events []string
+	stubExecutorWith(t, func(opts executor.Options) error {
+		events = append(events, "run "+filepath.Base(opts.Playbook))
+		return nil
+	})
+	oldRunGalaxyInstall := runGalaxyInstall
+	runGalaxyInstall = func(requirements string, collections bool) error {
+		events = append(events, "install "+requirements)
+		return nil
+	}
+	defer func() { runGalaxyInstall = oldRunGalaxyInstall }()
+
+	dir := t.TempDir()
+	requirements := filepath.Join(dir, "requirements.yml")
+	for _, path := range []string{requirements, filepath.Join(dir, "site.yml"), filepath.Join(dir, "db.yml")} {
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runOpts = runOptions{
+		inventory: "inv.yml",
+		playbooks: []string{filepath.Join(dir, "site.yml"), filepath.Join(dir, "db.yml")},
+		yes:       true,
+	}
+	defer func() { runOpts = runOptions{} }()
+
+	// Without the flag nothing is installed
+	if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err != nil {
+		t.Fatalf("runPlaybooks returned error: %v", err)
+	}
+	events = nil
+
+	runOpts.installDeps = true
+	if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err != nil {
+		t.Fatalf("runPlaybooks returned error: %v", err)
+	}
+	expected := []string{"install " + requirements, "run site.yml", "run db.yml"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected %q, got %q", expected, events)
+	}
+}
+
+// ✅ Test that --validate-limit aborts on a pattern matching no hosts
+func TestRunPlaybooks_ValidateLimit(t *testing.T) {
+	calls := stubExecutor(t)
+	path := filepath.Join(t.TempDir(), "inv.yml")
+	content := "---\nall:\n  children:\n    web:\n      hosts:\n        web1:\n        web2:\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { runOpts = runOptions{} }()
+
+	// A typo in the group name
+	runOpts = runOptions{inventory: path, playbooks: []string{"site.yml"}, yes: true, validateLimit: true, limit: "wbe:!web2"}
+	var err error
+	stderr := captureStderr(t, func() {
+		err = runPlaybooks(bufio.NewReader(strings.NewReader("")))
+	})
+	if err == nil || len(*calls) != 0 {
+		t.Errorf("Expected the run to abort, got %v and calls %v", err, *calls)
+	}
+	if !strings.Contains(stderr, "matches no hosts") {
+		t.Errorf("Expected a warning about the limit, got %q", stderr)
+	}
+
+	runOpts.limit = "web:!web2"
+	if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err != nil || len(*calls) != 1 {
+		t.Errorf("Expected a matching limit to run, got %v and calls %v", err, *calls)
+	}
+}
+
+// ✅ Test that a play reaching the implicit localhost is blocked unless --allow-localhost
+func TestRunPlaybooks_ImplicitLocalhost(t *testing.T) {
+	calls := stubExecutor(t)
+	dir := t.TempDir()
+	inv := filepath.Join(dir, "inv.yml")
+	site := filepath.Join(dir, "site.yml")
+	if err := os.WriteFile(inv, []byte("---\nall:\n  hosts:\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(site, []byte("- hosts: localhost\n  tasks: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runOpts = runOptions{inventory: inv, playbooks: []string{site}, yes: true, force: true}
+	defer func() { runOpts = runOptions{} }()
+
+	var err error
+	stderr := captureStderr(t, func() {
+		err = runPlaybooks(bufio.NewReader(strings.NewReader("")))
+	})
+	if err == nil || len(*calls) != 0 {
+		t.Errorf("Expected the run to be blocked, got %v and calls %v", err, *calls)
+	}
+	if !strings.Contains(stderr, "--allow-localhost") {
+		t.Errorf("Expected a hint about --allow-localhost, got %q", stderr)
+	}
+
+	runOpts.force = false
+	runOpts.allowLocalhost = true
+	captureStderr(t, func() {
+		err = runPlaybooks(bufio.NewReader(strings.NewReader("")))
+	})
+	if err != nil || len(*calls) != 1 {
+		t.Errorf("Expected --allow-localhost to run, got %v and calls %v", err, *calls)
+	}
+}
+
+// ✅ Test that localhost patterns are recognised inside compound patterns
+func TestNamesLocalhost(t *testing.T) {
+	tests := map[string]bool{
+		"localhost":      true,
+		"web:localhost":  true,
+		"web,127.0.0.1":  true,
+		"web:&localhost": true,
+		"all:!localhost": false,
+		"web":            false,
+		"localhost-db":   false,
+		"":               false,
+	}
+	for pattern, expected := range tests {
+		if got := namesLocalhost(pattern); got != expected {
+			t.Errorf("namesLocalhost(%q) = %v, expected %v", pattern, got, expected)
+		}
+	}
+}
+
+// ✅ Test that closed input stops the interactive flow instead of running with empty answers
+func TestRunPlaybooks_InputClosed(t *testing.T) {
+	calls := stubExecutor(t)
+	runOpts = runOptions{}
+	defer func() { runOpts = runOptions{} }()
+	dir := t.TempDir()
+
+	// Nothing at all, then input ending halfway through the host questions
+	for _, input := range []string{"", "no\nno\nweb1\n" + dir + "\n"} {
+		var err error
+		stderr := captureStderr(t, func() {
+			err = runPlaybooks(bufio.NewReader(strings.NewReader(input)))
+		})
+		if !errors.Is(err, errInputClosed) {
+			t.Errorf("Expected errInputClosed for %q, got %v", input, err)
+		}
+		if !strings.Contains(stderr, "nothing was run") {
+			t.Errorf("Expected the early stop to be reported, got %q", stderr)
+		}
+	}
+	if len(*calls) != 0 {
+		t.Errorf("Expected no playbook runs, got %+v", *calls)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected no inventory to be written, got %v", entries)
+	}
+}
+
+// ✅ Test that a last answer without a trailing newline is still read
+func TestReadAnswer(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader(" yes \nno"))
+	for _, expected := range []string{"yes", "no"} {
+		if answer, err := readAnswer(reader); err != nil || answer != expected {
+			t.Errorf("Expected %q, got %q and %v", expected, answer, err)
+		}
+	}
+	if _, err := readAnswer(reader); !errors.Is(err, errInputClosed) {
+		t.Errorf("Expected errInputClosed at the end of input, got %v", err)
+	}
+}
+
+// ✅ Test that an extra vars file chosen interactively is passed as @file
+func TestRunPlaybooks_ExtraVarsFile(t *testing.T) {
+	calls := stubExecutor(t)
+	varsFile := filepath.Join(t.TempDir(), "vars.yml")
+	if err := os.WriteFile(varsFile, []byte("version: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runOpts = runOptions{inventory: "inv.yml", extraVars: []string{"env=prod"}}
+	defer func() { runOpts = runOptions{} }()
+
+	// Playbooks, vars file, dry-run
+	runPlaybooks(bufio.NewReader(strings.NewReader("site.yml\n" + varsFile + "\nyes\n")))
+
+	expected := []string{"env=prod", "@" + varsFile}
+	if len(*calls) != 1 || strings.Join((*calls)[0].ExtraVars, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected extra vars %v, got %+v", expected, *calls)
+	}
+}
+
+// ✅ Test that a failed syntax check skips the real run
+func TestRunPlaybooks_SyntaxCheckFails(t *testing.T) {
+	calls := stubExecutorWith(t, func(opts executor.Options) error {
+		if opts.SyntaxCheck && opts.Playbook == "broken.yml" {
+			return errors.New("exit status 4")
+		}
+		return nil
+	})
+	runOpts = runOptions{inventory: "inv.yml", playbooks: []string{"site.yml", "broken.yml"}, syntaxCheck: true, yes: true}
+	defer func() { runOpts = runOptions{} }()
+
+	var err error
+	captureStderr(t, func() {
+		err = runPlaybooks(bufio.NewReader(strings.NewReader("")))
+	})
+	if err == nil {
+		t.Error("Expected an error when the syntax check fails")
+	}
+	for _, call := range *calls {
+		if !call.SyntaxCheck {
+			t.Errorf("Expected only syntax checks, got %+v", call)
+		}
+	}
+}
+
+// ✅ Test that passing syntax checks are followed by the run
+func TestRunPlaybooks_SyntaxCheckPasses(t *testing.T) {
+	calls := stubExecutor(t)
+	runOpts = runOptions{inventory: "inv.yml", playbooks: []string{"site.yml"}, syntaxCheck: true, yes: true}
+	defer func() { runOpts = runOptions{} }()
+
+	if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(*calls) != 2 || !(*calls)[0].SyntaxCheck || (*calls)[1].SyntaxCheck {
+		t.Errorf("Expected a syntax check followed by the run, got %+v", *calls)
+	}
+}
+
+// ✅ Test picking playbooks from the playbooks/ directory menu
+func TestSelectPlaybooks(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"site.yml", "db.yml", "web.yml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The menu is sorted: db.yml, site.yml, web.yml
+	selected, _ := selectPlaybooks(bufio.NewReader(strings.NewReader("3 1\n")), dir)
+
+	expected := []string{filepath.Join(dir, "web.yml"), filepath.Join(dir, "db.yml")}
+	if !reflect.DeepEqual(selected, expected) {
+		t.Errorf("Expected %v, got %v", expected, selected)
+	}
+}
+
+// ✅ Test that a missing playbook directory falls back to typing names
+func TestSelectPlaybooks_NoDirectory(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("site.yml\n"))
+	var selected []string
+	captureStderr(t, func() {
+		selected, _ = selectPlaybooks(reader, filepath.Join(t.TempDir(), "missing"))
+	})
+	if !reflect.DeepEqual(selected, []string{"site.yml"}) {
+		t.Errorf("Expected the typed playbook, got %v", selected)
+	}
+}
+
+// ✅ Test that an invalid playbook selection is asked again
+func TestSelectPlaybooks_Retry(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "site.yml"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var selected []string
+	stderr := captureStderr(t, func() {
+		selected, _ = selectPlaybooks(bufio.NewReader(strings.NewReader("5\n1\n")), dir)
+	})
+	if !reflect.DeepEqual(selected, []string{filepath.Join(dir, "site.yml")}) {
+		t.Errorf("Expected site.yml after the retry, got %v", selected)
+	}
+	if !strings.Contains(stderr, "out of range") {
+		t.Errorf("Expected an out of range warning, got %q", stderr)
+	}
+}
+
+// ✅ Test that an empty playbook answer is asked again instead of running nothing
+func TestSelectPlaybooks_Empty(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "site.yml"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var selected, typed []string
+	var err error
+	stderr := captureStderr(t, func() {
+		selected, _ = selectPlaybooks(bufio.NewReader(strings.NewReader("\n1\n")), dir)
+		typed, _ = askForPlaybooks(bufio.NewReader(strings.NewReader("  \nsite.yml\n")))
+		_, err = selectPlaybooks(bufio.NewReader(strings.NewReader("\n")), dir)
+	})
+	if !reflect.DeepEqual(selected, []string{filepath.Join(dir, "site.yml")}) || !reflect.DeepEqual(typed, []string{"site.yml"}) {
+		t.Errorf("Expected site.yml after the retry, got %v and %v", selected, typed)
+	}
+	if !strings.Contains(stderr, "at least one playbook") {
+		t.Errorf("Expected a warning, got %q", stderr)
+	}
+	if err == nil {
+		t.Error("Expected an error once input ends without a selection")
+	}
+}
+
+// ✅ Test that --limit-from-failed limits each playbook to its retry file
+func TestRunPlaybooks_LimitFromFailed(t *testing.T) {
+	calls := stubExecutor(t)
+	dir := t.TempDir()
+	playbook := filepath.Join(dir, "site.yml")
+	if err := os.WriteFile(filepath.Join(dir, "site.retry"), []byte("web1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runOpts = runOptions{inventory: "inv.yml", playbooks: []string{playbook}, limitFailed: true, yes: true}
+	defer func() { runOpts = runOptions{} }()
+
+	if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := "@" + filepath.Join(dir, "site.retry")
+	if len(*calls) != 1 || (*calls)[0].Limit != expected {
+		t.Errorf("Expected limit %q, got %+v", expected, *calls)
+	}
+}
+
+// ✅ Test that a missing retry file stops the run
+func TestRunPlaybooks_LimitFromFailedMissing(t *testing.T) {
+	calls := stubExecutor(t)
+	runOpts = runOptions{inventory: "inv.yml", playbooks: []string{filepath.Join(t.TempDir(), "site.yml")}, limitFailed: true, yes: true}
+	defer func() { runOpts = runOptions{} }()
+
+	var err error
+	captureStderr(t, func() {
+		err = runPlaybooks(bufio.NewReader(strings.NewReader("")))
+	})
+	if err == nil || len(*calls) != 0 {
+		t.Errorf("Expected the run to stop, got %v and calls %v", err, *calls)
+	}
+}
+
+// ✅ Test that an inventory created for the run is removed afterwards
+func TestRunPlaybooks_EphemeralInventory(t *testing.T) {
+	calls := stubExecutor(t)
+	runOpts = runOptions{ephemeral: true, yes: true}
+	defer func() { runOpts = runOptions{} }()
+
+	dir := t.TempDir()
+	// No inventory, no discovery, one host; directory, bastion, Windows, address, user,
+	// key, group, port, become; playbooks, vars file, no dry-run
+	input := "no\nno\n10.0.0.5\n" + dir + "\n\nno\n\nubuntu\n\n\n\nno\nsite.yml\n\nno\n"
+	runPlaybooks(bufio.NewReader(strings.NewReader(input)))
+
+	if len(*calls) != 1 {
+		t.Fatalf("Expected one playbook run, got %+v", *calls)
+	}
+	inventoryFile := (*calls)[0].Inventory
+	if filepath.Dir(inventoryFile) != dir {
+		t.Fatalf("Expected the inventory to be created in %s, got %s", dir, inventoryFile)
+	}
+	if _, err := os.Stat(inventoryFile); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed after the run, got %v", inventoryFile, err)
+	}
+	if entries, _ := loadHistory(); len(entries) != 0 {
+		t.Errorf("Expected no history entry for a removed inventory, got %+v", entries)
+	}
+}
+
+// ✅ Test that --playbook-args and arguments after -- end the ansible command
+func TestRunPlaybooks_PassthroughArgs(t *testing.T) {
+	passthrough, err := passthroughArgs(`--skip-tags "slow tests"`, []string{"ignored", "--flush-cache"}, 1)
+	if err != nil {
+		t.Fatalf("passthroughArgs returned error: %v", err)
+	}
+	calls := stubExecutor(t)
+	runOpts = runOptions{inventory: "inv.yml", playbooks: []string{"site.yml"}, tags: "web", yes: true, passthrough: passthrough}
+	defer func() { runOpts = runOptions{} }()
+
+	runPlaybooks(bufio.NewReader(strings.NewReader("")))
+
+	if len(*calls) != 1 {
+		t.Fatalf("Expected one playbook run, got %+v", *calls)
+	}
+	args := executor.BuildArgs((*calls)[0])
+	expected := []string{"--tags", "web", "--skip-tags", "slow tests", "--flush-cache"}
+	if got := args[len(args)-len(expected):]; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the args to end with %q, got %q", expected, args)
+	}
+
+	if _, err := passthroughArgs(`--skip-tags "slow`, nil, -1); err == nil {
+		t.Error("Expected an error for an unterminated quote")
+	}
+}
+
+// ✅ Test that a failed playbook stops the run unless --keep-going is set,
+// and that the run fails either way
+func TestRunPlaybooks_KeepGoing(t *testing.T) {
+	for _, keepGoing := range []bool{false, true} {
+		calls := stubExecutorWith(t, func(opts executor.Options) error {
+			if opts.Playbook == "first.yml" {
+				return errors.New("exit status 2")
+			}
+			return nil
+		})
+		runOpts = runOptions{inventory: "inv.yml", playbooks: []string{"first.yml", "second.yml"}, yes: true, keepGoing: keepGoing}
+
+		var err error
+		captureStderr(t, func() { err = runPlaybooks(bufio.NewReader(strings.NewReader(""))) })
+
+		expected := 1
+		if keepGoing {
+			expected = 2
+		}
+		if len(*calls) != expected {
+			t.Errorf("keepGoing=%t: expected %d playbook run(s), got %+v", keepGoing, expected, *calls)
+		}
+		if err == nil || !strings.Contains(err.Error(), "1 of 2 playbook(s) failed: first.yml") {
+			t.Errorf("keepGoing=%t: expected the run to fail, got %v", keepGoing, err)
+		}
+	}
+	runOpts = runOptions{}
+}
+
+// ✅ Test that --inventory-check shows ansible's view of the inventory first,
+// and that an inventory ansible can't read stops the run
+func TestRunPlaybooks_InventoryCheck(t *testing.T) {
+	calls := stubExecutor(t)
+	var checked []string
+	oldShowInventory := showInventory
+	showInventory = func(inventoryFile string) error {
+		checked = append(checked, inventoryFile)
+		if inventoryFile == "broken.yml" {
+			return errors.New("exit status 1")
+		}
+		return nil
+	}
+	defer func() {
+		showInventory = oldShowInventory
+		runOpts = runOptions{}
+	}()
+
+	runOpts = runOptions{inventory: "inv.yml", playbooks: []string{"site.yml"}, yes: true, inventoryCheck: true}
+	if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err != nil {
+		t.Fatalf("runPlaybooks returned error: %v", err)
+	}
+	runOpts = runOptions{inventory: "broken.yml", playbooks: []string{"site.yml"}, yes: true, inventoryCheck: true}
+	captureStderr(t, func() {
+		if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err == nil {
+			t.Error("Expected an unreadable inventory to stop the run")
+		}
+	})
+
+	if !reflect.DeepEqual(checked, []string{"inv.yml", "broken.yml"}) {
+		t.Errorf("Expected both inventories to be checked, got %v", checked)
+	}
+	if len(*calls) != 1 || (*calls)[0].Inventory != "inv.yml" {
+		t.Errorf("Expected only the readable inventory to run, got %+v", *calls)
+	}
+}
+
+// ✅ Test that --chdir sets the directory ansible-playbook runs in
+func TestRunPlaybooks_Chdir(t *testing.T) {
+	calls := stubExecutor(t)
+	dir := t.TempDir()
+	runOpts = runOptions{inventory: "inv.yml", playbooks: []string{"site.yml"}, yes: true, chdir: dir}
+	defer func() { runOpts = runOptions{} }()
+
+	runPlaybooks(bufio.NewReader(strings.NewReader("")))
+
+	if len(*calls) != 1 || (*calls)[0].WorkingDir != dir {
+		t.Errorf("Expected the playbook to run in %s, got %+v", dir, *calls)
+	}
+}
+
+// ✅ Test that --hosts runs against a temporary inventory of those hosts
+func TestRunPlaybooks_Hosts(t *testing.T) {
+	var hosts map[string]inventory.HostConfig
+	calls := stubExecutorWith(t, func(opts executor.Options) error {
+		inv, err := inventory.LoadInventory(opts.Inventory)
+		if err != nil {
+			t.Errorf("Expected the inventory to exist during the run: %v", err)
+			return nil
+		}
+		hosts = map[string]inventory.HostConfig{}
+		for _, host := range inv.Hosts {
+			hosts[host.Host] = host
+		}
+		return nil
+	})
+	runOpts = runOptions{hosts: "10.0.0.1, 10.0.0.2,", remoteUser: "ubuntu", playbooks: []string{"p.yml"}, yes: true}
+	defer func() { runOpts = runOptions{} }()
+
+	if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err != nil {
+		t.Fatalf("runPlaybooks returned error: %v", err)
+	}
+	if len(*calls) != 1 || len(hosts) != 2 {
+		t.Fatalf("Expected one run against both hosts, got %+v with %+v", *calls, hosts)
+	}
+	for _, name := range []string{"10.0.0.1", "10.0.0.2"} {
+		if hosts[name].SSHUser != "ubuntu" {
+			t.Errorf("Expected %s with user ubuntu, got %+v", name, hosts[name])
+		}
+	}
+	if _, err := os.Stat((*calls)[0].Inventory); !os.IsNotExist(err) {
+		t.Errorf("Expected the temporary inventory to be removed, got %v", err)
+	}
+	if entries, _ := loadHistory(); len(entries) != 0 {
+		t.Errorf("Expected no history entry for --hosts, got %+v", entries)
+	}
+
+	runOpts.hosts = " , "
+	captureStderr(t, func() {
+		if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err == nil {
+			t.Error("Expected an error for an empty host list")
+		}
+	})
+}
+
+// ✅ Test that `--inventory -` passes the piped inventory through a temp file
+func TestRunPlaybooks_StdinInventory(t *testing.T) {
+	content := "all:\n  hosts:\n    web1:\n"
+	var piped string
+	calls := stubExecutorWith(t, func(opts executor.Options) error {
+		data, err := os.ReadFile(opts.Inventory)
+		if err != nil {
+			t.Errorf("Expected the inventory to exist during the run: %v", err)
+		}
+		piped = string(data)
+		return nil
+	})
+	runOpts = runOptions{inventory: "-", playbooks: []string{"site.yml"}, yes: true}
+	defer func() { runOpts = runOptions{} }()
+
+	if err := runPlaybooks(bufio.NewReader(strings.NewReader(content))); err != nil {
+		t.Fatalf("runPlaybooks returned error: %v", err)
+	}
+	if len(*calls) != 1 || piped != content {
+		t.Fatalf("Expected one run with the piped inventory, got %+v with %q", *calls, piped)
+	}
+	if _, err := os.Stat((*calls)[0].Inventory); !os.IsNotExist(err) {
+		t.Errorf("Expected the temporary inventory to be removed, got %v", err)
+	}
+	if entries, _ := loadHistory(); len(entries) != 0 {
+		t.Errorf("Expected no history entry for a piped inventory, got %+v", entries)
+	}
+
+	captureStderr(t, func() {
+		if err := runPlaybooks(bufio.NewReader(strings.NewReader(""))); err == nil {
+			t.Error("Expected an error for an empty stdin")
+		}
+	})
+}
+
+// ✅ Test that an existing inventory is never removed
+func TestRunPlaybooks_EphemeralKeepsExisting(t *testing.T) {
+	stubExecutor(t)
+	path := filepath.Join(t.TempDir(), "inv.yml")
+	if err := os.WriteFile(path, []byte("all:\n  hosts:\n    web1:\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runOpts = runOptions{ephemeral: true, yes: true}
+	defer func() { runOpts = runOptions{} }()
+
+	runPlaybooks(bufio.NewReader(strings.NewReader("yes\n" + path + "\nsite.yml\n\nno\n")))
+
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected the existing inventory to be kept: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bxtal-lsn/gosible/internal/executor"
+	"github.com/bxtal-lsn/gosible/internal/inventory"
+	"github.com/bxtal-lsn/gosible/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var testCmd = &cobra.Command{
+	Use:     "test",
+	Aliases: []string{"connection-test"},
+	Short:   "Check that ansible can reach every host, with the reason for each failure",
+	Long: `Run ansible's ping module against the inventory and show which hosts are
+reachable. Failures are classified as auth (credentials refused), timeout,
+unreachable (refused, no route, unknown host) or failed (connected, but the
+module failed, e.g. without python).
+
+Exits non-zero when any host can't be reached.`,
+	Args: cobra.NoArgs,
+	Run:  testConnections,
+}
+
+// testOptions holds the flags accepted by the test command
+type testOptions struct {
+	inventory string
+	host      string
+}
+
+var testOpts testOptions
+
+// ✅ Allow overriding the ping module for testing
+var pingHosts = executor.Ping
+
+// ✅ Colors for the connection table's status column
+var pingColors = map[string]string{
+	"reachable":   "\x1b[32m",
+	"unreachable": "\x1b[31m",
+}
+
+func testConnections(cmd *cobra.Command, args []string) {
+	out, err := pingHosts(testOpts.inventory, testOpts.host)
+	if err != nil {
+		output.Errorf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	results, err := executor.ParsePing(out)
+	if err != nil {
+		// Show whatever ansible printed rather than nothing
+		fmt.Fprint(cmd.OutOrStdout(), string(out))
+		output.Errorf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprint(cmd.OutOrStdout(), pingTable(results))
+	reachable := 0
+	for _, result := range results {
+		if result.Reachable {
+			reachable++
+		}
+	}
+	if reachable < len(results) {
+		output.Errorf("❌ %d of %d host(s) unreachable\n", len(results)-reachable, len(results))
+		os.Exit(1)
+	}
+	output.Printf("✅ All %d host(s) reachable\n", len(results))
+}
+
+// ✅ Render ping results as an aligned table of host, status, reason and
+// ansible's message, cut to its first line; statuses are colored unless plain
+func pingTable(results []executor.PingResult) string {
+	var table strings.Builder
+	tw := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Host\tStatus\tReason\tDetails")
+	statuses := make([]string, len(results))
+	for i, result := range results {
+		statuses[i] = "reachable"
+		if !result.Reachable {
+			statuses[i] = "unreachable"
+		}
+		details, _, _ := strings.Cut(result.Message, "\n")
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Host, statuses[i], orDefault(result.Reason, "-"), orDefault(details, "-"))
+	}
+	tw.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+	if !output.Plain() {
+		// Colored after aligning, since tabwriter would count the escape codes
+		column := len([]rune(lines[0][:strings.Index(lines[0], "Status")]))
+		for i, status := range statuses {
+			lines[i+1] = colorCell(lines[i+1], column, status, pingColors[status])
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func init() {
+	flags := testCmd.Flags()
+	flags.StringVarP(&testOpts.inventory, "inventory", "i", inventory.DefaultInventoryFilename, "Inventory file or dynamic inventory script")
+	flags.StringVar(&testOpts.host, "host", "all", "Host or pattern to test")
+	testCmd.RegisterFlagCompletionFunc("inventory", completeYAMLFiles)
+	rootCmd.AddCommand(testCmd)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bxtal-lsn/gosible/internal/executor"
+)
+
+// ✅ Test that the connection table shows each host's status and reason
+func TestPingTable(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	results := []executor.PingResult{
+		{Host: "db1", Reason: executor.PingAuth, Message: "Failed to connect to the host via ssh: Permission denied (publickey)."},
+		{Host: "cache1", Reason: executor.PingFailed, Message: "The module failed to execute correctly\nSee stdout/stderr"},
+		{Host: "web1", Reachable: true},
+	}
+
+	expected := "Host    Status       Reason  Details\n" +
+		"db1     unreachable  auth    Failed to connect to the host via ssh: Permission denied (publickey).\n" +
+		"cache1  unreachable  failed  The module failed to execute correctly\n" +
+		"web1    reachable    -       -\n"
+	if got := pingTable(results); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+// ✅ Test that `gosible test` pings the inventory and prints the table
+func TestConnectionTest(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("NO_COLOR", "1")
+	var gotArgs []string
+	oldPingHosts := pingHosts
+	pingHosts = func(inventory string, pattern string) ([]byte, error) {
+		gotArgs = []string{inventory, pattern}
+		return []byte("web1 | SUCCESS => {\n    \"changed\": false,\n    \"ping\": \"pong\"\n}\n"), nil
+	}
+	defer func() { pingHosts = oldPingHosts }()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"test", "-i", "hosts.yml", "--host", "web"})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		testOpts = testOptions{}
+	}()
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("test failed: %v", err)
+	}
+
+	if len(gotArgs) != 2 || gotArgs[0] != "hosts.yml" || gotArgs[1] != "web" {
+		t.Errorf("Unexpected ping arguments %q", gotArgs)
+	}
+	if expected := "Host  Status     Reason  Details\nweb1  reachable  -       -\n"; out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
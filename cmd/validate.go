@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/bxtal-lsn/gosible/internal/inventory"
+	"github.com/bxtal-lsn/gosible/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate <inventory>",
+	Short: "Check an inventory file for duplicate hosts, empty groups and bad connection settings",
+	Args:  cobra.ExactArgs(1),
+	Run:   validateInventory,
+}
+
+func validateInventory(cmd *cobra.Command, args []string) {
+	inventoryFile := args[0]
+
+	inv, err := inventory.LoadInventory(inventoryFile)
+	if err != nil {
+		output.Errorf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	problems := inventory.Validate(inv)
+	if len(problems) == 0 {
+		output.Printf("✅ %s looks good (%d host definitions, %d groups)\n", inventoryFile, len(inv.Hosts), len(inv.Groups))
+		return
+	}
+
+	output.Printf("🔎 Found %d problem(s) in %s:\n", len(problems), inventoryFile)
+	for _, problem := range problems {
+		if problem.Severity == inventory.SeverityError {
+			output.Errorf("❌ %s\n", problem.Message)
+		} else {
+			output.Warnf("⚠️ %s\n", problem.Message)
+		}
+	}
+
+	if inventory.HasErrors(problems) {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
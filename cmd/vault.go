@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/bxtal-lsn/gosible/internal/executor"
+	"github.com/spf13/cobra"
+)
+
+var encryptCmd = &cobra.Command{
+	Use:   "encrypt <file>",
+	Short: "Encrypt a file with ansible-vault",
+	Args:  cobra.ExactArgs(1),
+	Run:   vaultAction(executor.VaultEncrypt),
+}
+
+var decryptCmd = &cobra.Command{
+	Use:   "decrypt <file>",
+	Short: "Decrypt a file with ansible-vault",
+	Args:  cobra.ExactArgs(1),
+	Run:   vaultAction(executor.VaultDecrypt),
+}
+
+var vaultPassFile string
+
+// ✅ Allow overriding the vault runner for testing
+var runVault = executor.RunVault
+
+// ✅ Build the Run function for an ansible-vault action
+// The password file defaults to vault-password-file from the config
+func vaultAction(action string) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		passwordFile := vaultPassFile
+		if !cmd.Flags().Changed("vault-password-file") {
+			passwordFile = cfg.VaultPasswordFile
+		}
+		if err := runVault(action, args[0], passwordFile); err != nil {
+			os.Exit(1)
+		}
+	}
+}
+
+func init() {
+	for _, c := range []*cobra.Command{encryptCmd, decryptCmd} {
+		c.Flags().StringVar(&vaultPassFile, "vault-password-file", "", "Vault password file (prompts for the password when unset)")
+		rootCmd.AddCommand(c)
+	}
+}
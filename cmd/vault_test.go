@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/bxtal-lsn/gosible/internal/config"
+)
+
+// ✅ Test that encrypt uses the configured password file unless the flag is set
+func TestVaultAction_PasswordFile(t *testing.T) {
+	var got []string
+	oldRunVault := runVault
+	runVault = func(action string, file string, passwordFile string) error {
+		got = []string{action, file, passwordFile}
+		return nil
+	}
+	defer func() { runVault = oldRunVault }()
+	oldCfg := cfg
+	cfg = config.Config{VaultPasswordFile: "~/.vault_pass"}
+	defer func() { cfg = oldCfg }()
+
+	vaultAction("encrypt")(encryptCmd, []string{"secrets.yml"})
+	if got[2] != "~/.vault_pass" {
+		t.Errorf("Expected the configured password file, got %q", got)
+	}
+
+	if err := encryptCmd.Flags().Set("vault-password-file", "other.txt"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		vaultPassFile = ""
+		encryptCmd.Flags().Lookup("vault-password-file").Changed = false
+	}()
+	vaultAction("encrypt")(encryptCmd, []string{"secrets.yml"})
+	if got[0] != "encrypt" || got[1] != "secrets.yml" || got[2] != "other.txt" {
+		t.Errorf("Expected the flag to win, got %q", got)
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bxtal-lsn/gosible/internal/output"
+	"github.com/fsnotify/fsnotify"
+)
+
+// ✅ How long watched files must stay quiet after a change before a rerun, so
+// an editor's burst of writes on save runs once
+var watchDebounce = 300 * time.Millisecond
+
+// ✅ Check that a --watch run has everything it needs without prompting, and
+// default it to check mode unless --apply was passed
+func prepareWatch(opts *runOptions) error {
+	if len(opts.playbooks) == 0 || (opts.inventory == "" && opts.hosts == "") {
+		return errors.New("--watch needs --playbook and --inventory (or --hosts), as nothing is asked between runs")
+	}
+	if opts.inventory == stdinInventory {
+		return errors.New("--watch can't rerun with an inventory read from stdin")
+	}
+	if !opts.apply {
+		opts.dryRun = true
+	}
+	return nil
+}
+
+// ✅ The files a --watch run reruns on: its playbooks and a static inventory
+func watchedFiles(opts runOptions) []string {
+	paths := append([]string{}, opts.playbooks...)
+	if opts.inventory != "" {
+		paths = append(paths, opts.inventory)
+	}
+	return paths
+}
+
+// ✅ Run, then run again whenever a watched file changes, until ctx is done
+// The watch starts before the first run, so saves made while a playbook runs
+// still trigger the next one. Failed runs have already been reported and
+// don't stop the watch.
+func watchPlaybooks(ctx context.Context, paths []string, run func() error) error {
+	watcher, err := newFileWatcher(paths)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	output.Printf("👀 Watching %s for changes (Ctrl-C to stop)\n", strings.Join(paths, ", "))
+	for {
+		run()
+		changed, err := waitForChange(ctx, watcher.Events, watcher.Errors, paths, watchDebounce)
+		if err != nil {
+			output.Println("\n👋 Stopped watching")
+			return nil
+		}
+		output.Printf("\n🔄 %s changed, running again\n", strings.Join(changed, ", "))
+	}
+}
+
+// ✅ Watch the directories holding paths rather than the files themselves, as
+// editors often save by writing a new file and renaming it over the old one,
+// which would end a watch on the file
+func newFileWatcher(paths []string) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error starting file watcher: %w", err)
+	}
+	added := map[string]bool{}
+	for _, path := range paths {
+		dir := filepath.Dir(path)
+		if added[dir] {
+			continue
+		}
+		added[dir] = true
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("error watching %s: %w", dir, err)
+		}
+	}
+	return watcher, nil
+}
+
+// ✅ Wait for events on paths until none has arrived for debounce, returning
+// the sorted changed paths, or ctx's error once it's done
+// Events for other files in the watched directories and attribute-only
+// changes are ignored; watcher errors are reported and the wait goes on.
+func waitForChange(ctx context.Context, events <-chan fsnotify.Event, errs <-chan error, paths []string, debounce time.Duration) ([]string, error) {
+	watched := map[string]string{}
+	for _, path := range paths {
+		watched[filepath.Clean(path)] = path
+	}
+
+	changed := map[string]bool{}
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case err := <-errs:
+			output.Warn("⚠️ File watcher: %v", err)
+		case event := <-events:
+			path, ok := watched[filepath.Clean(event.Name)]
+			if !ok || event.Op == fsnotify.Chmod {
+				continue
+			}
+			changed[path] = true
+			settled = time.After(debounce)
+		case <-settled:
+			names := make([]string, 0, len(changed))
+			for path := range changed {
+				names = append(names, path)
+			}
+			sort.Strings(names)
+			return names, nil
+		}
+	}
+}
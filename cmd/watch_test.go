@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ✅ Test that a change to a watched playbook triggers one rerun
+func TestWatchPlaybooks_RerunsOnChange(t *testing.T) {
+	oldDebounce := watchDebounce
+	watchDebounce = 30 * time.Millisecond
+	defer func() { watchDebounce = oldDebounce }()
+
+	path := filepath.Join(t.TempDir(), "site.yml")
+	if err := os.WriteFile(path, []byte("- hosts: all\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	runs := 0
+	err := watchPlaybooks(ctx, []string{path}, func() error {
+		runs++
+		switch runs {
+		case 1:
+			// Two quick saves, as an editor might make, debounce into one rerun
+			os.WriteFile(path, []byte("- hosts: web\n"), 0o644)
+			os.WriteFile(path, []byte("- hosts: web\n  become: true\n"), 0o644)
+		case 2:
+			cancel()
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("watchPlaybooks returned error: %v", err)
+	}
+	if runs != 2 {
+		t.Errorf("Expected one rerun after the change, got %d runs", runs)
+	}
+	if ctx.Err() != context.Canceled {
+		t.Errorf("Expected the watch to stop once canceled, got %v", ctx.Err())
+	}
+}
+
+// ✅ Test that simulated events for a watched file end the wait once they
+// settle, while other files and attribute changes are ignored
+func TestWaitForChange_Events(t *testing.T) {
+	events := make(chan fsnotify.Event, 4)
+	events <- fsnotify.Event{Name: "notes.txt", Op: fsnotify.Write}
+	events <- fsnotify.Event{Name: "inv.yml", Op: fsnotify.Chmod}
+	events <- fsnotify.Event{Name: "./site.yml", Op: fsnotify.Write}
+	events <- fsnotify.Event{Name: "site.yml", Op: fsnotify.Create}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	changed, err := waitForChange(ctx, events, nil, []string{"site.yml", "inv.yml"}, 10*time.Millisecond)
+	if err != nil || len(changed) != 1 || changed[0] != "site.yml" {
+		t.Errorf("Expected site.yml to have changed, got %v, %v", changed, err)
+	}
+
+	// Nothing relevant happens, so only the context ends the wait
+	events <- fsnotify.Event{Name: "inv.yml", Op: fsnotify.Chmod}
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if changed, err := waitForChange(ctx, events, nil, []string{"inv.yml"}, 10*time.Millisecond); err == nil {
+		t.Errorf("Expected no change, got %v", changed)
+	}
+}
+
+// ✅ Test that --watch needs its inventory and playbooks up front and defaults to check mode
+func TestPrepareWatch(t *testing.T) {
+	if err := prepareWatch(&runOptions{playbooks: []string{"site.yml"}}); err == nil {
+		t.Error("Expected an error without an inventory")
+	}
+	if err := prepareWatch(&runOptions{inventory: "-", playbooks: []string{"site.yml"}}); err == nil {
+		t.Error("Expected an error for an inventory on stdin")
+	}
+
+	opts := runOptions{inventory: "inv.yml", playbooks: []string{"site.yml"}}
+	if err := prepareWatch(&opts); err != nil || !opts.dryRun {
+		t.Errorf("Expected a dry run, got %+v with %v", opts, err)
+	}
+	opts = runOptions{hosts: "web1", playbooks: []string{"site.yml"}, apply: true}
+	if err := prepareWatch(&opts); err != nil || opts.dryRun {
+		t.Errorf("Expected --apply to keep applying, got %+v with %v", opts, err)
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ✅ Config holds user defaults for gosible flags
+// Keys in the file match the flag names, e.g. `vault-password-file`
+type Config struct {
+	AnsibleBin        string `yaml:"ansible-bin"`
+	AnsibleLintBin    string `yaml:"ansible-lint-bin"`
+	Forks             int    `yaml:"forks"`
+	VaultPasswordFile string `yaml:"vault-password-file"`
+	HistorySize       int    `yaml:"history-size"`
+
+	// ✅ Size such as 10M past which the audit log and history are rotated to
+	// gzipped copies; empty never rotates
+	LogMaxSize string `yaml:"log-max-size"`
+
+	// ✅ Tag of tasks `plan` skips since they can't run in check mode; empty disables
+	SkipCheckTag string `yaml:"skip-check-tag"`
+
+	// ✅ Team policy: runs stay in check mode unless --apply is passed
+	RequireConfirmApply bool `yaml:"require-confirm-apply"`
+}
+
+// ✅ Built-in defaults used when neither the config file nor a flag sets a value
+func Default() Config {
+	return Config{
+		AnsibleBin:     "ansible-playbook",
+		AnsibleLintBin: "ansible-lint",
+		HistorySize:    5,
+		SkipCheckTag:   "no-check",
+	}
+}
+
+// ✅ Return the default config path, `~/.config/gosible/config.yml`
+// $XDG_CONFIG_HOME is honored when set
+func DefaultPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gosible", "config.yml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "gosible", "config.yml"), nil
+}
+
+// ✅ Load the config file at path on top of the built-in defaults
+// A missing file is only an error when mustExist is set, i.e. when the user
+// pointed at it explicitly
+func Load(path string, mustExist bool) (Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !mustExist {
+		return cfg, nil
+	} else if err != nil {
+		return cfg, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	// ✅ Reject unknown keys so typos don't silently do nothing
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return cfg, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
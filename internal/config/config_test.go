@@ -0,0 +1,73 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ✅ Test that file values override defaults and unset keys keep them
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	content := "ansible-bin: /opt/ansible/bin/ansible-playbook\nansible-lint-bin: /opt/ansible/bin/ansible-lint\nforks: 25\nvault-password-file: ~/.vault_pass\nrequire-confirm-apply: true\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Could not write config: %v", err)
+	}
+
+	cfg, err := Load(path, true)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	expected := Config{
+		AnsibleBin:        "/opt/ansible/bin/ansible-playbook",
+		AnsibleLintBin:    "/opt/ansible/bin/ansible-lint",
+		Forks:             25,
+		VaultPasswordFile: "~/.vault_pass",
+		HistorySize:       5,
+		SkipCheckTag:      "no-check",
+
+		RequireConfirmApply: true,
+	}
+	if cfg != expected {
+		t.Errorf("Expected %+v, got %+v", expected, cfg)
+	}
+}
+
+// ✅ Test missing files: defaults for the implicit path, an error for an explicit one
+func TestLoad_Missing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.yml")
+
+	cfg, err := Load(path, false)
+	if err != nil || cfg != Default() {
+		t.Errorf("Expected defaults and no error, got %+v, %v", cfg, err)
+	}
+	if _, err := Load(path, true); err == nil {
+		t.Error("Expected an error for a missing explicit config file")
+	}
+}
+
+// ✅ Test that unknown keys and empty files are handled
+func TestLoad_Contents(t *testing.T) {
+	dir := t.TempDir()
+	typo := filepath.Join(dir, "typo.yml")
+	empty := filepath.Join(dir, "empty.yml")
+	os.WriteFile(typo, []byte("fork: 10\n"), 0o644)
+	os.WriteFile(empty, []byte(""), 0o644)
+
+	if _, err := Load(typo, true); err == nil {
+		t.Error("Expected an error for an unknown key")
+	}
+	if cfg, err := Load(empty, true); err != nil || cfg != Default() {
+		t.Errorf("Expected defaults for an empty file, got %+v, %v", cfg, err)
+	}
+}
+
+// ✅ Test the default path honors XDG_CONFIG_HOME
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	path, err := DefaultPath()
+	if err != nil || path != "/tmp/xdg/gosible/config.yml" {
+		t.Errorf("Expected /tmp/xdg/gosible/config.yml, got %q (%v)", path, err)
+	}
+}
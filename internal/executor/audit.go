@@ -0,0 +1,115 @@
+package executor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bxtal-lsn/gosible/internal/rotate"
+)
+
+// ✅ Extra var names whose values are kept out of the audit log
+var secretName = regexp.MustCompile(`(?i)pass|secret|token|key`)
+
+// ✅ Allow overriding the clock for testing
+var now = time.Now
+
+// ✅ Append a timestamped, redacted command line to the audit log at path
+// The file is only ever appended to and is created private to the user. Past
+// maxBytes it's rotated to `<path>.1.gz` first; zero never rotates.
+func AppendAuditLog(path string, maxBytes int64, binary string, args []string) error {
+	line := fmt.Sprintf("%s %s\n", now().UTC().Format(time.RFC3339), FormatCommand(binary, RedactArgs(args)))
+	log := rotate.Writer{Path: path, MaxBytes: maxBytes, Perm: 0o600}
+	if err := log.Append([]byte(line)); err != nil {
+		return fmt.Errorf("error writing audit log: %w", err)
+	}
+	return nil
+}
+
+// ✅ Return a copy of args with secret-looking extra var values replaced
+// Extra vars are found in every form ansible-playbook accepts: `--extra-vars v`,
+// `-e v`, `--extra-vars=v` and `-ev`, so passthrough arguments are covered too.
+// `key=value` vars are redacted by key name, each pair of a multi-pair value on
+// its own; inline JSON is redacted whole when it mentions a secret-looking name.
+// `@file` references are kept.
+func RedactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 0; i < len(redacted); i++ {
+		arg := redacted[i]
+		switch {
+		case arg == "--extra-vars" || arg == "-e":
+			if i+1 < len(redacted) {
+				redacted[i+1] = RedactExtraVars(redacted[i+1])
+				i++
+			}
+		case strings.HasPrefix(arg, "--extra-vars="):
+			redacted[i] = "--extra-vars=" + RedactExtraVars(strings.TrimPrefix(arg, "--extra-vars="))
+		case strings.HasPrefix(arg, "-e") && !strings.HasPrefix(arg, "--"):
+			redacted[i] = "-e" + RedactExtraVars(strings.TrimPrefix(arg, "-e"))
+		}
+	}
+	return redacted
+}
+
+// ✅ Redact the secret-looking values of one extra vars argument
+// Values that can't be split like a shell would are redacted whole when they
+// mention a secret-looking name.
+func RedactExtraVars(value string) string {
+	return rewriteSecretVars(value, "<redacted>", func(key string) string { return key + "=<redacted>" })
+}
+
+// ✅ Leave the secret-looking values out of one extra vars argument, returning
+// "" when nothing is left
+// For places that keep vars rather than log them, such as the command history,
+// where a `<redacted>` value would be passed on as the real one.
+func DropSecretVars(value string) string {
+	return rewriteSecretVars(value, "", func(string) string { return "" })
+}
+
+// ✅ Rewrite the secret-looking pairs of an extra vars argument with replace,
+// which returns "" to drop a pair; whole replaces inline JSON and values that
+// can't be split when they mention a secret-looking name
+func rewriteSecretVars(value string, whole string, replace func(key string) string) string {
+	trimmed := strings.TrimSpace(value)
+	switch {
+	case strings.HasPrefix(value, "@"):
+		return value
+	case strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "["):
+		if secretName.MatchString(value) {
+			return whole
+		}
+		return value
+	}
+
+	pairs, err := SplitArgs(value)
+	if err != nil {
+		if secretName.MatchString(value) {
+			return whole
+		}
+		return value
+	}
+	changed := false
+	kept := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		if key, _, found := strings.Cut(pair, "="); found && secretName.MatchString(key) {
+			changed = true
+			pair = replace(key)
+		}
+		if pair != "" {
+			kept = append(kept, pair)
+		}
+	}
+	if !changed {
+		return value
+	}
+	if len(pairs) == 1 {
+		return strings.Join(kept, "") // A lone pair needs no quoting to stay one
+	}
+	quoted := make([]string, len(kept))
+	for i, pair := range kept {
+		quoted[i] = quoteArg(pair)
+	}
+	return strings.Join(quoted, " ")
+}
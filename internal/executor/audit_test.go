@@ -0,0 +1,125 @@
+package executor
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ✅ Test that a run appends a timestamped, redacted command to the audit log
+func TestRun_AuditLog(t *testing.T) {
+	execCommand = mockExecCommand
+	defer func() { execCommand = exec.Command }()
+	now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	opts := Options{
+		Inventory: "inv.yml",
+		Playbook:  "site.yml",
+		ExtraVars: []string{"version=2", "db_password=hunter2", `{"api_token":"abc"}`, "@vars.yml"},
+		AuditLog:  path,
+	}
+	captureOutput(func() {
+		for i := 0; i < 2; i++ {
+			if err := Run(opts); err != nil {
+				t.Fatalf("Run returned error: %v", err)
+			}
+		}
+	})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Could not read audit log: %v", err)
+	}
+	entry := "2024-05-01T12:00:00Z ansible-playbook -i inv.yml site.yml --extra-vars version=2 " +
+		"--extra-vars 'db_password=<redacted>' --extra-vars '<redacted>' --extra-vars @vars.yml\n"
+	if string(data) != entry+entry {
+		t.Errorf("Expected two appended entries:\n%s\ngot:\n%s", entry, data)
+	}
+	if strings.Contains(string(data), "hunter2") || strings.Contains(string(data), "abc") {
+		t.Errorf("Expected secrets to be redacted, got %s", data)
+	}
+}
+
+// ✅ Test that every pair of a multi-pair value and every extra vars form in
+// passthrough arguments is redacted
+func TestRedactArgs(t *testing.T) {
+	args := []string{
+		"-i", "inv.yml", "site.yml",
+		"--extra-vars", "app=web db_password=hunter2",
+		"-e", "api_token=abc",
+		"--extra-vars=secret_key=xyz region=eu",
+		"-evault_pass=s3cret",
+		"-e", "@vars.yml",
+		"--tags", "deploy",
+	}
+	expected := []string{
+		"-i", "inv.yml", "site.yml",
+		"--extra-vars", "app=web 'db_password=<redacted>'",
+		"-e", "api_token=<redacted>",
+		"--extra-vars='secret_key=<redacted>' region=eu",
+		"-evault_pass=<redacted>",
+		"-e", "@vars.yml",
+		"--tags", "deploy",
+	}
+	got := RedactArgs(args)
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected:\n%q\ngot:\n%q", expected, got)
+	}
+	for _, secret := range []string{"hunter2", "abc", "xyz", "s3cret"} {
+		if strings.Contains(strings.Join(got, " "), secret) {
+			t.Errorf("Expected %s to be redacted, got %q", secret, got)
+		}
+	}
+	if args[4] != "app=web db_password=hunter2" {
+		t.Errorf("Expected the arguments to be left unchanged, got %q", args)
+	}
+}
+
+// ✅ Test that secrets passed through ExtraArgs never reach the audit log
+func TestRun_AuditLogPassthrough(t *testing.T) {
+	execCommand = mockExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	opts := Options{
+		Inventory: "inv.yml",
+		Playbook:  "site.yml",
+		ExtraVars: []string{"app=web db_password=hunter2"},
+		ExtraArgs: []string{"-e", "api_token=abc"},
+		AuditLog:  path,
+	}
+	captureOutput(func() {
+		if err := Run(opts); err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+	})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Could not read audit log: %v", err)
+	}
+	if strings.Contains(string(data), "hunter2") || strings.Contains(string(data), "abc") {
+		t.Errorf("Expected secrets to be redacted, got %s", data)
+	}
+}
+
+// ✅ Test that secret-looking pairs are left out rather than redacted
+func TestDropSecretVars(t *testing.T) {
+	for value, expected := range map[string]string{
+		"app=web":                     "app=web",
+		"app=web db_password=hunter2": "app=web",
+		"api_token=abc":               "",
+		"msg='hello world' key=x":     "'msg=hello world'",
+		`{"vault_pass": "s3cret"}`:    "",
+		"@secrets.yml":                "@secrets.yml",
+	} {
+		if got := DropSecretVars(value); got != expected {
+			t.Errorf("DropSecretVars(%q) = %q; expected %q", value, got, expected)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package executor
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ✅ Arguments made only of these characters are shown unquoted
+var plainArg = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// ✅ Render a command for display, quoting arguments the way a POSIX shell
+// would need them so each one reads as a single token
+// Only for banners and logs: commands are always run from the argument slice.
+func FormatCommand(binary string, args []string) string {
+	quoted := make([]string, 0, len(args)+1)
+	for _, arg := range append([]string{binary}, args...) {
+		quoted = append(quoted, quoteArg(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// ✅ Single-quote an argument unless it's plainly safe
+func quoteArg(arg string) string {
+	if plainArg.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// ✅ Split a string into arguments the way a POSIX shell would, honoring
+// single quotes, double quotes and backslash escapes; nothing is expanded
+// The reverse of FormatCommand, for flags that take a whole argument string.
+func SplitArgs(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			// Within double quotes a backslash only escapes these
+			if quote == '"' && !strings.ContainsRune("\"\\$`", r) {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == '\\':
+			escaped, inArg = true, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("unterminated quote in arguments")
+	}
+	if escaped {
+		return nil, errors.New("arguments end with a backslash")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
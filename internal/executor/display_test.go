@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+// ✅ Test quoting of arguments for display
+func TestFormatCommand(t *testing.T) {
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"-i", "inv.yml", "site.yml"}, "ansible-playbook -i inv.yml site.yml"},
+		{[]string{"--extra-vars", "msg=hello world"}, "ansible-playbook --extra-vars 'msg=hello world'"},
+		{[]string{"--extra-vars", `{"k":"v"}`}, `ansible-playbook --extra-vars '{"k":"v"}'`},
+		{[]string{"--extra-vars", "msg=it's"}, `ansible-playbook --extra-vars 'msg=it'\''s'`},
+		{[]string{"--limit", "web:&prod"}, "ansible-playbook --limit 'web:&prod'"},
+		{[]string{"--extra-vars", ""}, "ansible-playbook --extra-vars ''"},
+		{[]string{"--extra-vars", "@vars.yml"}, "ansible-playbook --extra-vars @vars.yml"},
+	} {
+		if got := FormatCommand("ansible-playbook", tc.args); got != tc.expected {
+			t.Errorf("FormatCommand(%q) = %s, expected %s", tc.args, got, tc.expected)
+		}
+	}
+}
+
+// ✅ Test shell-style splitting, including a round trip through FormatCommand
+func TestSplitArgs(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		expected []string
+	}{
+		{"", nil},
+		{"  --diff   --flush-cache ", []string{"--diff", "--flush-cache"}},
+		{`--skip-tags 'slow tests' -e "msg=\"hi\" there"`, []string{"--skip-tags", "slow tests", "-e", `msg="hi" there`}},
+		{`--start-at-task=Install\ nginx`, []string{"--start-at-task=Install nginx"}},
+		{`-e "path=C:\x" ''`, []string{"-e", `path=C:\x`, ""}},
+	} {
+		got, err := SplitArgs(tc.input)
+		if err != nil || !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("SplitArgs(%q) = %q, %v; expected %q", tc.input, got, err, tc.expected)
+		}
+	}
+
+	args := []string{"--extra-vars", "msg=it's", "--limit", "web:&prod"}
+	formatted := FormatCommand("ansible-playbook", args)
+	if got, _ := SplitArgs(formatted); !reflect.DeepEqual(got[1:], args) {
+		t.Errorf("Expected %s to split back into %q, got %q", formatted, args, got)
+	}
+
+	for _, input := range []string{`--tags 'web`, `-e "x`, `--diff \`} {
+		if _, err := SplitArgs(input); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
+
+// ✅ Test that the banner shows an extra var with spaces as one token
+func TestRun_BannerQuoting(t *testing.T) {
+	var ran []string
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		ran = arg
+		return mockExecCommand(name, arg...)
+	}
+	defer func() { execCommand = exec.Command }()
+
+	output := captureOutput(func() {
+		Run(Options{Inventory: "inv.yml", Playbook: "site.yml", ExtraVars: []string{"msg=hello world"}})
+	})
+
+	expected := "🔄 Executing: ansible-playbook -i inv.yml site.yml --extra-vars 'msg=hello world'\n"
+	if output != expected {
+		t.Errorf("Expected banner %q, got %q", expected, output)
+	}
+	if ran[len(ran)-1] != "msg=hello world" {
+		t.Errorf("Expected the unquoted value to be passed to ansible, got %q", ran)
+	}
+}
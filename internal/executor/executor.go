@@ -1,37 +1,468 @@
 package executor
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/bxtal-lsn/gosible/internal/output"
 )
 
 // ✅ Allow overriding exec.Command for testing
 var execCommand = exec.Command
 
-// ✅ Execute Ansible playbook, supporting dry-run mode
-func ExecuteAnsiblePlaybook(inventory string, playbook string, vars []string, dryRun bool) {
-	cmdArgs := []string{"-i", inventory, playbook}
+// ✅ Executable used when Options.Binary isn't set
+const DefaultBinary = "ansible-playbook"
+
+// ✅ Options configures a single ansible-playbook run
+type Options struct {
+	Binary      string // ansible-playbook executable, looked up on PATH by default
+	Inventory   string
+	Playbook    string
+	ExtraVars   []string
+	DryRun      bool
+	Diff        bool // show file and template changes with --diff
+	SyntaxCheck bool // only run --syntax-check, nothing is executed
+
+	// ✅ Run notified handlers even when a task fails, so e.g. a service still
+	// restarts after a later step broke
+	ForceHandlers bool
+
+	Tags      string // comma-separated, as accepted by --tags
+	SkipTags  string // comma-separated, as accepted by --skip-tags
+	Limit     string // host pattern passed to --limit
+	Verbosity int    // number of -v flags
+
+	Forks             int
+	VaultPasswordFile string
+	VaultIDs          []string // label@source, one --vault-id each
+
+	// ✅ SSH key and user used instead of the per-host settings in the inventory
+	PrivateKey string
+	RemoteUser string
+	SSHTimeout int // connection timeout in seconds passed as --timeout, 0 keeps ansible's default
+
+	// ✅ Force SSH host key checking on or off through ANSIBLE_HOST_KEY_CHECKING;
+	// the default keeps whatever ansible's configuration says
+	HostKeyChecking HostKeyChecking
+
+	// ✅ Print an elapsed-time line this often while the playbook runs (0 disables)
+	Heartbeat time.Duration
+
+	// ✅ Only show the PLAY RECAP and failures instead of every task
+	SummaryOnly bool
+
+	// ✅ Append each command to this file before running it (empty disables),
+	// rotating it once it passes AuditLogMaxBytes (zero never rotates)
+	AuditLog         string
+	AuditLogMaxBytes int64
+
+	// ✅ Called with the parsed PLAY RECAP once the playbook exits, failed or
+	// not; skipped when the output had no recap
+	OnRecap func(Recap)
+
+	// ✅ Bytes of output kept in memory to find the recap in, counted from the
+	// end; 0 uses DefaultCaptureLimit
+	CaptureLimit int
+
+	// ✅ Privilege escalation; method and user are ignored unless Become is set
+	Become       bool
+	BecomeMethod string
+	BecomeUser   string
+
+	// ✅ File holding the become password; it reaches ansible through a
+	// temporary vars file so the password never appears in the arguments
+	BecomePasswordFile string
+
+	// ✅ Passed to ansible-playbook as-is after gosible's own arguments
+	ExtraArgs []string
+
+	// ✅ Directory ansible-playbook runs in, for its ansible.cfg and relative
+	// roles and files; empty keeps gosible's own. Relative inventory, playbook
+	// and key paths still mean what they did to gosible.
+	WorkingDir string
+}
+
+// ✅ HostKeyChecking overrides ansible's SSH host key checking for a run
+type HostKeyChecking int
+
+const (
+	HostKeyCheckingDefault HostKeyChecking = iota // Inherit ansible's configuration
+	HostKeyCheckingOn
+	HostKeyCheckingOff
+)
+
+// ✅ ANSIBLE_HOST_KEY_CHECKING setting for the child environment, or "" to
+// leave it to ansible's configuration
+func (h HostKeyChecking) env() string {
+	switch h {
+	case HostKeyCheckingOn:
+		return "ANSIBLE_HOST_KEY_CHECKING=True"
+	case HostKeyCheckingOff:
+		return "ANSIBLE_HOST_KEY_CHECKING=False"
+	}
+	return ""
+}
+
+// ✅ Build the ansible-playbook arguments for the given options
+func BuildArgs(opts Options) []string {
+	cmdArgs := []string{"-i", opts.Inventory, opts.Playbook}
 
 	// ✅ Add extra variables
-	for _, v := range vars {
+	for _, v := range opts.ExtraVars {
 		cmdArgs = append(cmdArgs, "--extra-vars", v)
 	}
 
 	// ✅ Enable dry-run mode if selected
-	if dryRun {
+	if opts.DryRun {
 		cmdArgs = append(cmdArgs, "--check")
 	}
+	if opts.Diff {
+		cmdArgs = append(cmdArgs, "--diff")
+	}
+	if opts.SyntaxCheck {
+		cmdArgs = append(cmdArgs, "--syntax-check")
+	}
+	if opts.ForceHandlers {
+		cmdArgs = append(cmdArgs, "--force-handlers")
+	}
+
+	if opts.Tags != "" {
+		cmdArgs = append(cmdArgs, "--tags", opts.Tags)
+	}
+	if opts.SkipTags != "" {
+		cmdArgs = append(cmdArgs, "--skip-tags", opts.SkipTags)
+	}
+	if opts.Limit != "" {
+		cmdArgs = append(cmdArgs, "--limit", opts.Limit)
+	}
+	if opts.Verbosity > 0 {
+		cmdArgs = append(cmdArgs, "-"+strings.Repeat("v", opts.Verbosity))
+	}
+
+	// Passed as extra vars, since --private-key and --user lose to a key or user
+	// set in the inventory
+	if opts.PrivateKey != "" {
+		key := opts.PrivateKey
+		if resolved, err := filepath.Abs(key); err == nil {
+			key = resolved
+		}
+		cmdArgs = append(cmdArgs, "--extra-vars", connectionVar("ansible_ssh_private_key_file", key))
+	}
+	if opts.RemoteUser != "" {
+		cmdArgs = append(cmdArgs, "--extra-vars", connectionVar("ansible_user", opts.RemoteUser))
+	}
+	if opts.SSHTimeout > 0 {
+		cmdArgs = append(cmdArgs, "--timeout", strconv.Itoa(opts.SSHTimeout))
+	}
+
+	if opts.Forks > 0 {
+		cmdArgs = append(cmdArgs, "--forks", strconv.Itoa(opts.Forks))
+	}
+	if opts.VaultPasswordFile != "" {
+		cmdArgs = append(cmdArgs, "--vault-password-file", opts.VaultPasswordFile)
+	}
+	for _, id := range opts.VaultIDs {
+		cmdArgs = append(cmdArgs, "--vault-id", id)
+	}
+
+	// ✅ Privilege escalation
+	if opts.Become {
+		cmdArgs = append(cmdArgs, "--become")
+		if opts.BecomeMethod != "" {
+			cmdArgs = append(cmdArgs, "--become-method", opts.BecomeMethod)
+		}
+		if opts.BecomeUser != "" {
+			cmdArgs = append(cmdArgs, "--become-user", opts.BecomeUser)
+		}
+	}
+
+	// ✅ Anything gosible doesn't wrap
+	cmdArgs = append(cmdArgs, opts.ExtraArgs...)
+
+	return cmdArgs
+}
+
+// ✅ Extra var overriding a connection variable for every host
+// Extra vars outrank the inventory's per-host settings, which the matching
+// ansible-playbook flags don't.
+func connectionVar(name string, value string) string {
+	return name + "=" + quoteArg(value)
+}
+
+// ✅ Run ansible-playbook with the given options
+// A failed run is logged and returned; a non-zero exit is an *exec.ExitError
+func Run(opts Options) error {
+	if err := checkOptions(opts); err != nil {
+		output.Error("❌ %v", err)
+		return err
+	}
+	if opts.WorkingDir != "" {
+		opts = absolutePaths(opts)
+	}
 
-	cmd := execCommand("ansible-playbook", cmdArgs...)
+	cmdArgs := BuildArgs(opts)
+	if opts.BecomePasswordFile != "" {
+		varsFile, err := writeBecomePassword(opts.BecomePasswordFile)
+		if err != nil {
+			output.Error("❌ %v", err)
+			return err
+		}
+		defer os.Remove(varsFile)
+		cmdArgs = append(cmdArgs, "--extra-vars", "@"+varsFile)
+	}
+	binary := opts.Binary
+	if binary == "" {
+		binary = DefaultBinary
+	}
+	if opts.AuditLog != "" {
+		// Nothing runs unaudited
+		if err := AppendAuditLog(opts.AuditLog, opts.AuditLogMaxBytes, binary, cmdArgs); err != nil {
+			output.Error("❌ %v", err)
+			return err
+		}
+	}
+
+	cmd := execCommand(binary, cmdArgs...)
+	cmd.Dir = opts.WorkingDir
+	if setting := opts.HostKeyChecking.env(); setting != "" {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, setting)
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
+	if opts.SummaryOnly {
+		filter := newRecapFilter(os.Stdout)
+		defer filter.Flush()
+		cmd.Stdout = filter
+	}
+	// ✅ The recap comes last, so only the tail of the output is kept for it
+	var tail *tailBuffer
+	if opts.OnRecap != nil {
+		tail = newTailBuffer(opts.CaptureLimit)
+		cmd.Stdout = io.MultiWriter(cmd.Stdout, tail)
+	}
 
-	fmt.Printf("🔄 Executing: ansible-playbook %s\n", strings.Join(cmdArgs, " "))
+	output.Info("🔄 Executing: %s", FormatCommand(binary, cmdArgs))
 
 	// ✅ Run command
-	if err := cmd.Run(); err != nil {
-		fmt.Println("❌ Error executing playbook:", err)
+	err := runWithHeartbeat(cmd, opts.Playbook, opts.Heartbeat)
+	if tail != nil {
+		if recap := ParseRecap(string(tail.Bytes())); len(recap) > 0 {
+			opts.OnRecap(recap)
+		}
+	}
+	if err != nil {
+		reportRunError(binary, opts.Playbook, err)
+		return err
+	}
+	return nil
+}
+
+// ✅ Meaning of ansible-playbook's documented exit codes
+var exitCodeMeanings = map[int]string{
+	1:   "an error occurred",
+	2:   "one or more hosts had failures",
+	3:   "one or more hosts were unreachable",
+	4:   "the playbook could not be parsed",
+	5:   "bad or incomplete options",
+	99:  "the run was interrupted",
+	250: "ansible hit an unexpected error",
+}
+
+// ✅ Log why a run failed, telling a missing ansible apart from a failed play
+func reportRunError(binary string, playbook string, err error) {
+	var execErr *exec.Error
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &execErr), errors.Is(err, fs.ErrNotExist):
+		output.Error("❌ Could not start %s: %v", binary, err)
+		output.Error("💡 Is Ansible installed? Install it with `pip install ansible` or your package manager, or set --ansible-bin to its path")
+	case errors.As(err, &exitErr):
+		code := exitErr.ExitCode()
+		if meaning, ok := exitCodeMeanings[code]; ok {
+			output.Error("❌ Playbook %s failed with exit code %d: %s", playbook, code, meaning)
+		} else {
+			output.Error("❌ Playbook %s failed with exit code %d", playbook, code)
+		}
+		output.Error("💡 See the task output above for what went wrong")
+	default:
+		output.Error("❌ Error executing playbook: %v", err)
+	}
+}
+
+// ✅ Path of the retry file ansible writes for a playbook's failed hosts
+// `deploy/site.yml` leaves its failed hosts in `deploy/site.retry`
+func RetryFile(playbook string) string {
+	return strings.TrimSuffix(playbook, filepath.Ext(playbook)) + ".retry"
+}
+
+// ✅ Catch problems ansible would otherwise report less clearly
+func checkOptions(opts Options) error {
+	if err := ValidateExtraVars(opts.ExtraVars); err != nil {
+		return err
+	}
+	if opts.PrivateKey != "" {
+		if _, err := os.Stat(opts.PrivateKey); err != nil {
+			return fmt.Errorf("private key %s: %w", opts.PrivateKey, err)
+		}
+	}
+	if opts.WorkingDir != "" {
+		info, err := os.Stat(opts.WorkingDir)
+		if err != nil {
+			return fmt.Errorf("working directory %s: %w", opts.WorkingDir, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("working directory %s is not a directory", opts.WorkingDir)
+		}
+	}
+	return nil
+}
+
+// ✅ Make the paths gosible was given absolute, so running ansible in
+// another directory doesn't change which files they point at
+func absolutePaths(opts Options) Options {
+	abs := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		if resolved, err := filepath.Abs(path); err == nil {
+			return resolved
+		}
+		return path
+	}
+
+	opts.Inventory = abs(opts.Inventory)
+	opts.Playbook = abs(opts.Playbook)
+	opts.PrivateKey = abs(opts.PrivateKey)
+	opts.VaultPasswordFile = abs(opts.VaultPasswordFile)
+	// A vault id's source is a file unless it's `prompt`
+	ids := make([]string, len(opts.VaultIDs))
+	for i, id := range opts.VaultIDs {
+		label, source, found := strings.Cut(id, "@")
+		if !found {
+			label, source = "", id
+		}
+		if source != "prompt" {
+			source = abs(source)
+		}
+		if found {
+			source = label + "@" + source
+		}
+		ids[i] = source
+	}
+	opts.VaultIDs = ids
+	// A limit of `@file`, as --limit-from-failed passes, reads hosts from a file
+	if file, ok := strings.CutPrefix(opts.Limit, "@"); ok {
+		opts.Limit = "@" + abs(file)
 	}
+	vars := make([]string, len(opts.ExtraVars))
+	for i, v := range opts.ExtraVars {
+		if file, ok := strings.CutPrefix(v, "@"); ok {
+			v = "@" + abs(file)
+		}
+		vars[i] = v
+	}
+	opts.ExtraVars = vars
+	return opts
+}
+
+// ✅ Copy the become password into a private temporary vars file
+// The trailing newline editors add to the password file is dropped
+func writeBecomePassword(passwordFile string) (string, error) {
+	data, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return "", fmt.Errorf("error reading become password file: %w", err)
+	}
+	password := strings.TrimRight(string(data), "\r\n")
+	vars, err := json.Marshal(map[string]string{"ansible_become_password": password})
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp("", "gosible-become-*.json") // Created with mode 0600
+	if err != nil {
+		return "", fmt.Errorf("error creating become vars file: %w", err)
+	}
+	if _, err := file.Write(vars); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", fmt.Errorf("error writing become vars file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("error writing become vars file: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// ✅ Check that each extra var is `key=value`, an `@file` reference or a JSON object
+func ValidateExtraVars(vars []string) error {
+	for _, v := range vars {
+		if err := validateExtraVar(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateExtraVar(v string) error {
+	trimmed := strings.TrimSpace(v)
+	switch {
+	case strings.HasPrefix(trimmed, "@"):
+		if strings.TrimSpace(trimmed[1:]) == "" {
+			return fmt.Errorf("invalid extra var %q: @ must be followed by a file name", v)
+		}
+	case strings.HasPrefix(trimmed, "{"):
+		var object map[string]any
+		if err := json.Unmarshal([]byte(trimmed), &object); err != nil {
+			return fmt.Errorf("invalid extra var %q: not a valid JSON object: %v", v, err)
+		}
+	default:
+		key, _, found := strings.Cut(v, "=")
+		if !found || strings.TrimSpace(key) == "" || strings.ContainsAny(key, " \t") {
+			return fmt.Errorf("invalid extra var %q: expected key=value, @file or a JSON object", v)
+		}
+	}
+	return nil
+}
+
+// ✅ Run cmd, logging the elapsed time every interval until it exits
+func runWithHeartbeat(cmd *exec.Cmd, playbook string, interval time.Duration) error {
+	if interval <= 0 {
+		return cmd.Run()
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-ticker.C:
+			output.Info("⏳ Still running %s (%s elapsed)", playbook, time.Since(start).Round(time.Second))
+		}
+	}
+}
+
+// ✅ Execute Ansible playbook, supporting dry-run mode
+func ExecuteAnsiblePlaybook(inventory string, playbook string, vars []string, dryRun bool) error {
+	return Run(Options{Inventory: inventory, Playbook: playbook, ExtraVars: vars, DryRun: dryRun})
 }
@@ -2,10 +2,17 @@ package executor
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/bxtal-lsn/gosible/internal/output"
 )
 
 // ✅ Mock function to replace exec.Command
@@ -22,6 +29,20 @@ func TestHelperProcess(t *testing.T) {
 	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
 		return
 	}
+	if d, err := time.ParseDuration(os.Getenv("MOCK_SLEEP")); err == nil {
+		time.Sleep(d)
+	}
+	fmt.Print(os.Getenv("MOCK_OUTPUT"))
+	if os.Getenv("MOCK_PRINT_CWD") == "1" {
+		dir, _ := os.Getwd()
+		fmt.Println("cwd=" + dir)
+	}
+	if name := os.Getenv("MOCK_PRINT_ENV"); name != "" {
+		fmt.Println(name + "=" + os.Getenv(name))
+	}
+	if os.Getenv("MOCK_EXIT_CODE") == "2" {
+		os.Exit(2)
+	}
 	os.Exit(0)
 }
 
@@ -90,3 +111,587 @@ func TestExecuteAnsiblePlaybook_DryRun(t *testing.T) {
 	}
 }
 
+// ✅ Test that a dynamic inventory script path is passed to -i unchanged
+func TestExecuteAnsiblePlaybook_DynamicInventory(t *testing.T) {
+	execCommand = mockExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	output := captureOutput(func() {
+		ExecuteAnsiblePlaybook("./inventories/ec2.py", "test_playbook.yml", nil, false)
+	})
+
+	expected := "🔄 Executing: ansible-playbook -i ./inventories/ec2.py test_playbook.yml"
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected output %q, got %q", expected, output)
+	}
+}
+
+// ✅ Test become method/user only appear when become is enabled
+func TestBuildArgs_BecomeOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		expected []string
+	}{
+		{
+			name:     "become disabled ignores method and user",
+			opts:     Options{Inventory: "inv.yml", Playbook: "site.yml", BecomeMethod: "doas", BecomeUser: "postgres"},
+			expected: []string{"-i", "inv.yml", "site.yml"},
+		},
+		{
+			name:     "become only",
+			opts:     Options{Inventory: "inv.yml", Playbook: "site.yml", Become: true},
+			expected: []string{"-i", "inv.yml", "site.yml", "--become"},
+		},
+		{
+			name:     "become with method and user",
+			opts:     Options{Inventory: "inv.yml", Playbook: "site.yml", Become: true, BecomeMethod: "su", BecomeUser: "postgres"},
+			expected: []string{"-i", "inv.yml", "site.yml", "--become", "--become-method", "su", "--become-user", "postgres"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := BuildArgs(tt.opts)
+			if strings.Join(args, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("Expected args %v, got %v", tt.expected, args)
+			}
+		})
+	}
+}
+
+// ✅ Test forks, vault password file and a custom binary
+func TestRun_ConfiguredDefaults(t *testing.T) {
+	execCommand = mockExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	output := captureOutput(func() {
+		Run(Options{
+			Binary:            "/opt/ansible/bin/ansible-playbook",
+			Inventory:         "test_inventory.yml",
+			Playbook:          "test_playbook.yml",
+			Forks:             25,
+			VaultPasswordFile: "vault.txt",
+		})
+	})
+
+	expected := "🔄 Executing: /opt/ansible/bin/ansible-playbook -i test_inventory.yml test_playbook.yml --forks 25 --vault-password-file vault.txt"
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected output %q, got %q", expected, output)
+	}
+}
+
+// ✅ Test that plain output has no emoji in the executing banner
+func TestExecuteAnsiblePlaybook_Plain(t *testing.T) {
+	execCommand = mockExecCommand
+	defer func() { execCommand = exec.Command }()
+	output.SetPlain(true)
+	defer output.SetPlain(false)
+
+	captured := captureOutput(func() {
+		ExecuteAnsiblePlaybook("test_inventory.yml", "test_playbook.yml", nil, false)
+	})
+
+	expected := "Executing: ansible-playbook -i test_inventory.yml test_playbook.yml\n"
+	if captured != expected {
+		t.Errorf("Expected output %q, got %q", expected, captured)
+	}
+	for _, r := range captured {
+		if output.IsEmoji(r) {
+			t.Errorf("Found emoji %q in plain output %q", r, captured)
+		}
+	}
+}
+
+// ✅ Test that quiet mode hides the banner but still runs the command
+func TestRun_Quiet(t *testing.T) {
+	var ran []string
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		ran = append(ran, name)
+		return mockExecCommand(name, arg...)
+	}
+	defer func() { execCommand = exec.Command }()
+	output.SetQuiet(true)
+	defer output.SetQuiet(false)
+
+	captured := captureOutput(func() {
+		Run(Options{Inventory: "test_inventory.yml", Playbook: "test_playbook.yml"})
+	})
+
+	if strings.Contains(captured, "Executing") {
+		t.Errorf("Expected no executing banner in quiet mode, got %q", captured)
+	}
+	if len(ran) != 1 || ran[0] != "ansible-playbook" {
+		t.Errorf("Expected ansible-playbook to still run, got %v", ran)
+	}
+}
+
+// ✅ captureLogger records log messages
+type captureLogger struct {
+	messages []string
+}
+
+func (c *captureLogger) Info(format string, args ...any) {
+	c.messages = append(c.messages, fmt.Sprintf(format, args...))
+}
+
+func (c *captureLogger) Warn(format string, args ...any) {
+	c.messages = append(c.messages, fmt.Sprintf(format, args...))
+}
+
+func (c *captureLogger) Error(format string, args ...any) {
+	c.messages = append(c.messages, fmt.Sprintf(format, args...))
+}
+
+// ✅ Test that executor messages are routed through an injected logger
+func TestRun_Logger(t *testing.T) {
+	execCommand = mockExecCommand
+	defer func() { execCommand = exec.Command }()
+	capture := &captureLogger{}
+	output.SetLogger(capture)
+	defer output.SetLogger(nil)
+
+	stdout := captureOutput(func() {
+		Run(Options{Inventory: "test_inventory.yml", Playbook: "test_playbook.yml"})
+	})
+
+	if strings.Contains(stdout, "Executing") {
+		t.Errorf("Expected the banner to go to the logger, got stdout %q", stdout)
+	}
+	expected := "🔄 Executing: ansible-playbook -i test_inventory.yml test_playbook.yml"
+	if len(capture.messages) != 1 || capture.messages[0] != expected {
+		t.Errorf("Expected logger messages [%q], got %q", expected, capture.messages)
+	}
+}
+
+// ✅ Test that a failing playbook run is reported to the caller
+func TestRun_ReturnsExitError(t *testing.T) {
+	execCommand = mockExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	if err := Run(Options{Inventory: "test_inventory.yml", Playbook: "test_playbook.yml"}); err != nil {
+		t.Errorf("Expected no error for a successful run, got %v", err)
+	}
+
+	t.Setenv("MOCK_EXIT_CODE", "2")
+	var err error
+	captureOutput(func() {
+		err = Run(Options{Inventory: "test_inventory.yml", Playbook: "test_playbook.yml"})
+	})
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 2 {
+		t.Errorf("Expected exit code 2, got %v", err)
+	}
+}
+
+// ✅ Test that a missing ansible and a failed play get different guidance
+func TestRun_ErrorGuidance(t *testing.T) {
+	defer func() { execCommand = exec.Command }()
+	capture := &captureLogger{}
+	output.SetLogger(capture)
+	defer output.SetLogger(nil)
+
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		return exec.Command("gosible-test-missing-ansible", arg...)
+	}
+	var execErr *exec.Error
+	if err := Run(Options{Inventory: "inv.yml", Playbook: "site.yml"}); !errors.As(err, &execErr) {
+		t.Errorf("Expected an *exec.Error, got %v", err)
+	}
+	if logs := strings.Join(capture.messages, "\n"); !strings.Contains(logs, "Is Ansible installed?") || strings.Contains(logs, "exit code") {
+		t.Errorf("Expected install guidance, got:\n%s", logs)
+	}
+
+	capture.messages = nil
+	execCommand = mockExecCommand
+	t.Setenv("MOCK_EXIT_CODE", "2")
+	var exitErr *exec.ExitError
+	if err := Run(Options{Inventory: "inv.yml", Playbook: "site.yml"}); !errors.As(err, &exitErr) {
+		t.Errorf("Expected an *exec.ExitError, got %v", err)
+	}
+	logs := strings.Join(capture.messages, "\n")
+	if !strings.Contains(logs, "Playbook site.yml failed with exit code 2: one or more hosts had failures") || strings.Contains(logs, "installed") {
+		t.Errorf("Expected failed play guidance, got:\n%s", logs)
+	}
+}
+
+// ✅ Test tags, limit and verbosity arguments
+func TestBuildArgs_TagsLimitVerbosity(t *testing.T) {
+	args := BuildArgs(Options{
+		Inventory: "inv.yml",
+		Playbook:  "site.yml",
+		Tags:      "deploy,config",
+		Limit:     "web",
+		Verbosity: 3,
+	})
+
+	expected := "-i inv.yml site.yml --tags deploy,config --limit web -vvv"
+	if got := strings.Join(args, " "); got != expected {
+		t.Errorf("Expected args %q, got %q", expected, got)
+	}
+}
+
+// ✅ Test that a slow playbook produces heartbeat lines
+func TestRun_Heartbeat(t *testing.T) {
+	execCommand = mockExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("MOCK_SLEEP", "300ms")
+	capture := &captureLogger{}
+	output.SetLogger(capture)
+	defer output.SetLogger(nil)
+
+	err := Run(Options{Inventory: "test_inventory.yml", Playbook: "test_playbook.yml", Heartbeat: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	heartbeats := 0
+	for _, message := range capture.messages {
+		if strings.HasPrefix(message, "⏳ Still running test_playbook.yml") {
+			heartbeats++
+		}
+	}
+	if heartbeats == 0 {
+		t.Errorf("Expected at least one heartbeat, got %q", capture.messages)
+	}
+}
+
+// ✅ Test that the private key is passed as an extra var, which outranks a key
+// set in the inventory, and that a missing key is an error
+func TestRun_PrivateKey(t *testing.T) {
+	var ran [][]string
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		ran = append(ran, arg)
+		return mockExecCommand(name, arg...)
+	}
+	defer func() { execCommand = exec.Command }()
+
+	dir := t.TempDir()
+	key := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(key, []byte("key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	inv := filepath.Join(dir, "inv.yml")
+	content := "all:\n  hosts:\n    web1:\n      ansible_ssh_private_key_file: ~/.ssh/inventory_key\n"
+	if err := os.WriteFile(inv, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	captureOutput(func() {
+		if err := Run(Options{Inventory: inv, Playbook: "site.yml", PrivateKey: key}); err != nil {
+			t.Errorf("Run returned error: %v", err)
+		}
+	})
+	expected := "-i " + inv + " site.yml --extra-vars ansible_ssh_private_key_file=" + key
+	if len(ran) != 1 || strings.Join(ran[0], " ") != expected {
+		t.Errorf("Expected args %q, got %v", expected, ran)
+	}
+	if strings.Contains(strings.Join(ran[0], " "), "--private-key") {
+		t.Error("Expected no --private-key, which the inventory's key would beat")
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	err := Run(Options{Inventory: inv, Playbook: "site.yml", PrivateKey: missing})
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a missing file error, got %v", err)
+	}
+	if len(ran) != 1 {
+		t.Error("Expected ansible not to run with a missing key")
+	}
+}
+
+// ✅ Test that a relative private key is made absolute and quoted when needed
+func TestBuildArgs_PrivateKeyRelative(t *testing.T) {
+	args := BuildArgs(Options{Inventory: "inv.yml", Playbook: "site.yml", PrivateKey: "keys/my key"})
+
+	wd, _ := os.Getwd()
+	expected := "ansible_ssh_private_key_file='" + filepath.Join(wd, "keys/my key") + "'"
+	if got := args[len(args)-1]; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+// ✅ Test that a remote user override is passed as an ansible_user extra var,
+// which outranks a user set in the inventory where --user wouldn't
+func TestBuildArgs_RemoteUser(t *testing.T) {
+	args := BuildArgs(Options{Inventory: "inv.yml", Playbook: "site.yml", RemoteUser: "admin"})
+
+	expected := "-i inv.yml site.yml --extra-vars ansible_user=admin"
+	if got := strings.Join(args, " "); got != expected {
+		t.Errorf("Expected args %q, got %q", expected, got)
+	}
+}
+
+// ✅ Test that the remote user reaches ansible as an extra var when the
+// inventory sets its own ansible_user
+func TestRun_RemoteUserOverridesInventory(t *testing.T) {
+	var ran [][]string
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		ran = append(ran, arg)
+		return mockExecCommand(name, arg...)
+	}
+	defer func() { execCommand = exec.Command }()
+
+	inv := filepath.Join(t.TempDir(), "inv.yml")
+	if err := os.WriteFile(inv, []byte("all:\n  hosts:\n    web1:\n      ansible_user: ubuntu\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	captureOutput(func() {
+		if err := Run(Options{Inventory: inv, Playbook: "site.yml", RemoteUser: "admin"}); err != nil {
+			t.Errorf("Run returned error: %v", err)
+		}
+	})
+	if len(ran) != 1 {
+		t.Fatalf("Expected one run, got %v", ran)
+	}
+	args := strings.Join(ran[0], " ")
+	if !strings.Contains(args, "--extra-vars ansible_user=admin") || strings.Contains(args, "--user") {
+		t.Errorf("Expected the user as an ansible_user extra var, got %q", args)
+	}
+}
+
+// ✅ Test that --force-handlers is only passed when requested
+func TestBuildArgs_ForceHandlers(t *testing.T) {
+	if args := strings.Join(BuildArgs(Options{Inventory: "inv.yml", Playbook: "site.yml"}), " "); strings.Contains(args, "--force-handlers") {
+		t.Errorf("Expected no --force-handlers by default, got %q", args)
+	}
+
+	args := BuildArgs(Options{Inventory: "inv.yml", Playbook: "site.yml", ForceHandlers: true})
+	expected := "-i inv.yml site.yml --force-handlers"
+	if got := strings.Join(args, " "); got != expected {
+		t.Errorf("Expected args %q, got %q", expected, got)
+	}
+}
+
+// ✅ Test that the playbook runs in the working directory with gosible's paths kept
+func TestRun_WorkingDir(t *testing.T) {
+	execCommand = mockExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("MOCK_PRINT_CWD", "1")
+	dir := t.TempDir()
+
+	out := captureOutput(func() {
+		if err := Run(Options{Inventory: "inv.yml", Playbook: "site.yml", WorkingDir: dir}); err != nil {
+			t.Errorf("Run returned error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "cwd="+dir+"\n") {
+		t.Errorf("Expected the playbook to run in %s, got:\n%s", dir, out)
+	}
+
+	cwd, _ := os.Getwd()
+	resolved := absolutePaths(Options{Inventory: "inv.yml", Playbook: "/srv/site.yml", ExtraVars: []string{"@vars.yml", "k=v"}})
+	if resolved.Inventory != filepath.Join(cwd, "inv.yml") || resolved.Playbook != "/srv/site.yml" ||
+		resolved.ExtraVars[0] != "@"+filepath.Join(cwd, "vars.yml") || resolved.ExtraVars[1] != "k=v" {
+		t.Errorf("Unexpected resolved paths %+v", resolved)
+	}
+}
+
+// ✅ Test that vault id files are made absolute, leaving `prompt` alone
+func TestAbsolutePaths_VaultIDs(t *testing.T) {
+	cwd, _ := os.Getwd()
+	resolved := absolutePaths(Options{VaultIDs: []string{"dev@vault/dev.txt", "prod@prompt", "prompt", "secret.txt", "ops@/etc/vault"}})
+
+	expected := []string{
+		"dev@" + filepath.Join(cwd, "vault/dev.txt"),
+		"prod@prompt",
+		"prompt",
+		filepath.Join(cwd, "secret.txt"),
+		"ops@/etc/vault",
+	}
+	if !reflect.DeepEqual(resolved.VaultIDs, expected) {
+		t.Errorf("Expected vault ids %v, got %v", expected, resolved.VaultIDs)
+	}
+}
+
+// ✅ Test that a retry file limit is made absolute, and host patterns aren't
+func TestAbsolutePaths_RetryLimit(t *testing.T) {
+	cwd, _ := os.Getwd()
+	if got := absolutePaths(Options{Limit: "@deploy/site.retry"}).Limit; got != "@"+filepath.Join(cwd, "deploy/site.retry") {
+		t.Errorf("Expected an absolute retry file limit, got %q", got)
+	}
+	if got := absolutePaths(Options{Limit: "web*"}).Limit; got != "web*" {
+		t.Errorf("Expected a host pattern limit to be unchanged, got %q", got)
+	}
+}
+
+// ✅ Test that host key checking is forced through the child's environment,
+// and inherited by default
+func TestRun_HostKeyChecking(t *testing.T) {
+	execCommand = mockExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("MOCK_PRINT_ENV", "ANSIBLE_HOST_KEY_CHECKING")
+	t.Setenv("ANSIBLE_HOST_KEY_CHECKING", "inherited")
+
+	for setting, expected := range map[HostKeyChecking]string{
+		HostKeyCheckingDefault: "inherited",
+		HostKeyCheckingOn:      "True",
+		HostKeyCheckingOff:     "False",
+	} {
+		out := captureOutput(func() {
+			if err := Run(Options{Inventory: "inv.yml", Playbook: "site.yml", HostKeyChecking: setting}); err != nil {
+				t.Errorf("Run returned error: %v", err)
+			}
+		})
+		if !strings.Contains(out, "ANSIBLE_HOST_KEY_CHECKING="+expected+"\n") {
+			t.Errorf("Expected ANSIBLE_HOST_KEY_CHECKING=%s for setting %d, got:\n%s", expected, setting, out)
+		}
+	}
+}
+
+// ✅ Test that a missing working directory stops the run
+func TestRun_MissingWorkingDir(t *testing.T) {
+	execCommand = mockExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	err := Run(Options{Inventory: "inv.yml", Playbook: "site.yml", WorkingDir: filepath.Join(t.TempDir(), "missing")})
+	if err == nil || !strings.Contains(err.Error(), "working directory") {
+		t.Errorf("Expected a working directory error, got %v", err)
+	}
+}
+
+// ✅ Test extra var validation
+func TestValidateExtraVars(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		valid bool
+	}{
+		{"version=1.2", true},
+		{"greeting=hello world", true},
+		{"empty=", true},
+		{"@vars.yml", true},
+		{`{"version": "1.2", "replicas": 3}`, true},
+		{"keyvalue", false},
+		{"=value", false},
+		{"bad key=value", false},
+		{"@", false},
+		{`{"version": }`, false},
+	} {
+		err := ValidateExtraVars([]string{tc.value})
+		if tc.valid && err != nil {
+			t.Errorf("Expected %q to be valid, got %v", tc.value, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("Expected %q to be rejected", tc.value)
+		}
+	}
+}
+
+// ✅ Test that an invalid extra var stops the run before ansible starts
+func TestRun_InvalidExtraVar(t *testing.T) {
+	var ran int
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		ran++
+		return mockExecCommand(name, arg...)
+	}
+	defer func() { execCommand = exec.Command }()
+
+	err := Run(Options{Inventory: "inv.yml", Playbook: "site.yml", ExtraVars: []string{"keyvalue"}})
+	if err == nil || !strings.Contains(err.Error(), "keyvalue") {
+		t.Errorf("Expected an error naming the bad extra var, got %v", err)
+	}
+	if ran != 0 {
+		t.Error("Expected ansible not to run")
+	}
+}
+
+// ✅ Test that @file and JSON extra vars are forwarded unchanged, one argument each
+func TestBuildArgs_FileAndJSONExtraVars(t *testing.T) {
+	vars := []string{"@vars.yml", `{"k":"v"}`}
+	args := BuildArgs(Options{Inventory: "inv.yml", Playbook: "site.yml", ExtraVars: vars})
+
+	expected := []string{"-i", "inv.yml", "site.yml", "--extra-vars", "@vars.yml", "--extra-vars", `{"k":"v"}`}
+	if strings.Join(args, "\x00") != strings.Join(expected, "\x00") {
+		t.Errorf("Expected args %q, got %q", expected, args)
+	}
+	if err := ValidateExtraVars(vars); err != nil {
+		t.Errorf("Expected @file and JSON extra vars to validate, got %v", err)
+	}
+}
+
+// ✅ Test the syntax check flag
+func TestBuildArgs_SyntaxCheck(t *testing.T) {
+	args := BuildArgs(Options{Inventory: "inv.yml", Playbook: "site.yml", SyntaxCheck: true})
+
+	expected := "-i inv.yml site.yml --syntax-check"
+	if got := strings.Join(args, " "); got != expected {
+		t.Errorf("Expected args %q, got %q", expected, got)
+	}
+}
+
+// ✅ Test retry file naming
+func TestRetryFile(t *testing.T) {
+	for playbook, expected := range map[string]string{
+		"site.yml":           "site.retry",
+		"deploy/app.yaml":    "deploy/app.retry",
+		"playbooks/db":       "playbooks/db.retry",
+		"/srv/ansible/x.yml": "/srv/ansible/x.retry",
+	} {
+		if got := RetryFile(playbook); got != expected {
+			t.Errorf("RetryFile(%q) = %q, expected %q", playbook, got, expected)
+		}
+	}
+}
+
+// ✅ Test the connection timeout flag, and that zero leaves it out
+func TestBuildArgs_SSHTimeout(t *testing.T) {
+	args := BuildArgs(Options{Inventory: "inv.yml", Playbook: "site.yml", SSHTimeout: 30})
+	if got := strings.Join(args, " "); got != "-i inv.yml site.yml --timeout 30" {
+		t.Errorf("Expected --timeout 30, got %q", got)
+	}
+
+	args = BuildArgs(Options{Inventory: "inv.yml", Playbook: "site.yml"})
+	if got := strings.Join(args, " "); strings.Contains(got, "--timeout") {
+		t.Errorf("Expected no --timeout by default, got %q", got)
+	}
+}
+
+// ✅ Test that the become password reaches ansible through a private vars file
+func TestRun_BecomePasswordFile(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "sudo_pass")
+	if err := os.WriteFile(passwordFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var args []string
+	var varsFile, vars string
+	var mode os.FileMode
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		args = arg
+		varsFile = strings.TrimPrefix(arg[len(arg)-1], "@")
+		if data, err := os.ReadFile(varsFile); err == nil {
+			vars = string(data)
+		}
+		if info, err := os.Stat(varsFile); err == nil {
+			mode = info.Mode().Perm()
+		}
+		return mockExecCommand(name, arg...)
+	}
+	defer func() { execCommand = exec.Command }()
+
+	captureOutput(func() {
+		if err := Run(Options{Inventory: "inv.yml", Playbook: "site.yml", Become: true, BecomePasswordFile: passwordFile}); err != nil {
+			t.Errorf("Run returned error: %v", err)
+		}
+	})
+
+	if vars != `{"ansible_become_password":"s3cret"}` || mode != 0o600 {
+		t.Errorf("Expected a 0600 vars file with the trimmed password, got %q (mode %v)", vars, mode)
+	}
+	if strings.Contains(strings.Join(args, " "), "s3cret") {
+		t.Errorf("Expected the password to stay out of the arguments, got %q", args)
+	}
+	if _, err := os.Stat(varsFile); !os.IsNotExist(err) {
+		t.Errorf("Expected the vars file to be removed after the run, got %v", err)
+	}
+}
+
+// ✅ Test that each vault identity becomes its own --vault-id, in order
+func TestBuildArgs_VaultIDs(t *testing.T) {
+	args := BuildArgs(Options{Inventory: "inv.yml", Playbook: "site.yml", VaultIDs: []string{"dev@prompt", "prod@~/.vault_prod"}})
+
+	expected := "-i inv.yml site.yml --vault-id dev@prompt --vault-id prod@~/.vault_prod"
+	if got := strings.Join(args, " "); got != expected {
+		t.Errorf("Expected args %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bxtal-lsn/gosible/internal/output"
+)
+
+// ✅ Executable used for ad-hoc modules such as setup
+const AdHocBinary = "ansible"
+
+// ✅ Build the `ansible <host> -m setup` arguments, with an optional fact filter
+func FactsArgs(inventory string, host string, filter string) []string {
+	args := []string{host, "-i", inventory, "-m", "setup"}
+	if filter != "" {
+		args = append(args, "-a", "filter="+filter)
+	}
+	return args
+}
+
+// ✅ Run the setup module and return ansible's output
+// Ansible's own errors go straight to stderr. Like Ping, a non-zero exit
+// because some hosts failed still returns the output, so the facts of the
+// others and the reason for each failure can be parsed from it; only a failure
+// to run ansible at all is an error.
+func GatherFacts(inventory string, host string, filter string) ([]byte, error) {
+	args := FactsArgs(inventory, host, filter)
+	cmd := execCommand(AdHocBinary, args...)
+	cmd.Stderr = os.Stderr
+
+	output.Info("🔄 Executing: %s", FormatCommand(AdHocBinary, args))
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return out, fmt.Errorf("error gathering facts: %w", err)
+	}
+	return out, nil
+}
+
+// ✅ Start of one host's result in ad-hoc output, e.g. `web1 | SUCCESS => {`
+// or `web2 | UNREACHABLE! => {`
+var adHocResult = regexp.MustCompile(`(?m)^(\S+) \| [A-Z]+!? => `)
+
+// ✅ Parse ad-hoc setup output into the ansible_facts of each host
+func ParseFacts(out []byte) (map[string]map[string]any, error) {
+	facts := map[string]map[string]any{}
+	for _, match := range adHocResult.FindAllSubmatchIndex(out, -1) {
+		host := string(out[match[2]:match[3]])
+
+		var result struct {
+			Facts map[string]any `json:"ansible_facts"`
+		}
+		decoder := json.NewDecoder(bytes.NewReader(out[match[1]:]))
+		if err := decoder.Decode(&result); err != nil {
+			return nil, fmt.Errorf("error parsing facts for %s: %w", host, err)
+		}
+		if result.Facts != nil {
+			facts[host] = result.Facts
+		}
+	}
+	if len(facts) == 0 {
+		return nil, fmt.Errorf("no facts found in ansible output")
+	}
+	return facts, nil
+}
+
+// ✅ Hosts in ad-hoc setup output that returned no facts, with the reason like
+// ParsePing gives it, sorted by host
+// Results that can't be parsed are left to ParseFacts to report.
+func FactFailures(out []byte) []PingResult {
+	var failures []PingResult
+	for _, match := range adHocResult.FindAllSubmatchIndex(out, -1) {
+		host := string(out[match[2]:match[3]])
+		header := string(out[match[0]:match[1]])
+
+		var result struct {
+			Facts       map[string]any `json:"ansible_facts"`
+			Msg         string         `json:"msg"`
+			Unreachable bool           `json:"unreachable"`
+		}
+		decoder := json.NewDecoder(bytes.NewReader(out[match[1]:]))
+		if err := decoder.Decode(&result); err != nil || result.Facts != nil {
+			continue
+		}
+
+		failure := PingResult{Host: host, Message: strings.TrimSpace(result.Msg)}
+		if result.Unreachable || strings.Contains(header, "UNREACHABLE") {
+			failure.Reason = classifyPingFailure(failure.Message, PingUnreachable)
+		} else {
+			failure.Reason = classifyPingFailure(failure.Message, PingFailed)
+		}
+		failures = append(failures, failure)
+	}
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Host < failures[j].Host })
+	return failures
+}
@@ -0,0 +1,127 @@
+package executor
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ✅ Test the setup module arguments, with and without a filter
+func TestFactsArgs(t *testing.T) {
+	expected := []string{"web1", "-i", "inv.yml", "-m", "setup"}
+	if got := FactsArgs("inv.yml", "web1", ""); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	expected = append(expected, "-a", "filter=ansible_distribution*")
+	if got := FactsArgs("inv.yml", "web1", "ansible_distribution*"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+// ✅ Test that GatherFacts runs `ansible` with the setup module
+func TestGatherFacts(t *testing.T) {
+	var name string
+	var args []string
+	execCommand = func(n string, arg ...string) *exec.Cmd {
+		name, args = n, arg
+		return mockExecCommand(n, arg...)
+	}
+	defer func() { execCommand = exec.Command }()
+
+	captureOutput(func() {
+		if _, err := GatherFacts("inv.yml", "web1", ""); err != nil {
+			t.Errorf("GatherFacts returned error: %v", err)
+		}
+	})
+
+	expected := []string{"web1", "-i", "inv.yml", "-m", "setup"}
+	if name != "ansible" || !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected ansible %q, got %s %q", expected, name, args)
+	}
+}
+
+// ✅ Test parsing facts for several hosts from ad-hoc output
+func TestParseFacts(t *testing.T) {
+	out := []byte(`web1 | SUCCESS => {
+    "ansible_facts": {
+        "ansible_distribution": "Ubuntu",
+        "ansible_processor_vcpus": 2
+    },
+    "changed": false
+}
+db1 | SUCCESS => {
+    "ansible_facts": {
+        "ansible_distribution": "Debian"
+    },
+    "changed": false
+}
+web2 | UNREACHABLE! => {
+    "changed": false,
+    "msg": "Failed to connect to the host via ssh",
+    "unreachable": true
+}
+`)
+
+	facts, err := ParseFacts(out)
+	if err != nil {
+		t.Fatalf("ParseFacts returned error: %v", err)
+	}
+
+	expected := map[string]map[string]any{
+		"web1": {"ansible_distribution": "Ubuntu", "ansible_processor_vcpus": float64(2)},
+		"db1":  {"ansible_distribution": "Debian"},
+	}
+	if !reflect.DeepEqual(facts, expected) {
+		t.Errorf("Expected %v, got %v", expected, facts)
+	}
+
+	if _, err := ParseFacts([]byte("ERROR! the playbook could not be found")); err == nil {
+		t.Error("Expected an error for output without facts")
+	}
+}
+
+// ✅ Test that a non-zero exit from unreachable hosts still returns the output
+func TestGatherFacts_HostFailures(t *testing.T) {
+	execCommand = mockExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("MOCK_OUTPUT", "web2 | UNREACHABLE! => {\"msg\": \"timed out\", \"unreachable\": true}\n")
+	t.Setenv("MOCK_EXIT_CODE", "2")
+
+	var out []byte
+	captureOutput(func() {
+		var err error
+		if out, err = GatherFacts("inv.yml", "all", ""); err != nil {
+			t.Errorf("GatherFacts returned error: %v", err)
+		}
+	})
+	if !strings.Contains(string(out), "UNREACHABLE") {
+		t.Errorf("Expected ansible's output, got %q", out)
+	}
+}
+
+// ✅ Test that hosts without facts are reported with the reason
+func TestFactFailures(t *testing.T) {
+	out := []byte(`web1 | SUCCESS => {
+    "ansible_facts": {"ansible_distribution": "Ubuntu"}
+}
+web3 | FAILED! => {
+    "msg": "MODULE FAILURE: /usr/bin/python: not found"
+}
+web2 | UNREACHABLE! => {
+    "msg": "Failed to connect to the host via ssh: Permission denied (publickey).",
+    "unreachable": true
+}
+`)
+	expected := []PingResult{
+		{Host: "web2", Reason: PingAuth, Message: "Failed to connect to the host via ssh: Permission denied (publickey)."},
+		{Host: "web3", Reason: PingFailed, Message: "MODULE FAILURE: /usr/bin/python: not found"},
+	}
+	if got := FactFailures(out); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+	if facts, err := ParseFacts(out); err != nil || len(facts) != 1 {
+		t.Errorf("Expected the reachable host's facts, got %v, %v", facts, err)
+	}
+}
@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bxtal-lsn/gosible/internal/output"
+)
+
+// ✅ Executable used to install roles and collections
+const GalaxyBinary = "ansible-galaxy"
+
+// ✅ Conventional name of the dependency file next to playbooks
+const DefaultRequirementsFile = "requirements.yml"
+
+// ✅ Build the ansible-galaxy arguments to install a requirements file
+// Roles use `install -r`, collections `collection install -r`
+func GalaxyInstallArgs(requirements string, collections bool) []string {
+	if collections {
+		return []string{"collection", "install", "-r", requirements}
+	}
+	return []string{"install", "-r", requirements}
+}
+
+// ✅ Install the roles or collections listed in a requirements file
+func RunGalaxyInstall(requirements string, collections bool) error {
+	if _, err := os.Stat(requirements); err != nil {
+		err = fmt.Errorf("requirements file %s: %w", requirements, err)
+		output.Error("❌ %v", err)
+		return err
+	}
+
+	args := GalaxyInstallArgs(requirements, collections)
+	cmd := execCommand(GalaxyBinary, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	output.Info("📦 Executing: %s", FormatCommand(GalaxyBinary, args))
+	if err := cmd.Run(); err != nil {
+		output.Error("❌ Error installing %s: %v", requirements, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// ✅ Test that RunGalaxyInstall runs ansible-galaxy for roles and collections
+func TestRunGalaxyInstall(t *testing.T) {
+	var name string
+	var args []string
+	execCommand = func(n string, arg ...string) *exec.Cmd {
+		name, args = n, arg
+		return mockExecCommand(n, arg...)
+	}
+	defer func() { execCommand = exec.Command }()
+
+	requirements := filepath.Join(t.TempDir(), "requirements.yml")
+	if err := os.WriteFile(requirements, []byte("roles: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		collections bool
+		expected    []string
+	}{
+		{false, []string{"install", "-r", requirements}},
+		{true, []string{"collection", "install", "-r", requirements}},
+	} {
+		captureOutput(func() {
+			if err := RunGalaxyInstall(requirements, tc.collections); err != nil {
+				t.Errorf("RunGalaxyInstall returned error: %v", err)
+			}
+		})
+		if name != "ansible-galaxy" || !reflect.DeepEqual(args, tc.expected) {
+			t.Errorf("Expected ansible-galaxy %q, got %s %q", tc.expected, name, args)
+		}
+	}
+}
+
+// ✅ Test that a missing requirements file is rejected before running anything
+func TestRunGalaxyInstall_MissingFile(t *testing.T) {
+	ran := false
+	execCommand = func(n string, arg ...string) *exec.Cmd {
+		ran = true
+		return mockExecCommand(n, arg...)
+	}
+	defer func() { execCommand = exec.Command }()
+
+	var err error
+	captureOutput(func() {
+		err = RunGalaxyInstall(filepath.Join(t.TempDir(), "requirements.yml"), false)
+	})
+	if err == nil || ran {
+		t.Errorf("Expected an error and no run, got %v (ran: %t)", err, ran)
+	}
+}
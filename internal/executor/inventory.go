@@ -0,0 +1,32 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bxtal-lsn/gosible/internal/output"
+)
+
+// ✅ Executable that shows an inventory the way ansible parses it
+const InventoryBinary = "ansible-inventory"
+
+// ✅ Build the `ansible-inventory --graph` arguments
+func InventoryGraphArgs(inventory string) []string {
+	return []string{"-i", inventory, "--graph"}
+}
+
+// ✅ Print the tree of groups, child groups and hosts ansible resolves
+// Ansible's own parser is the ground truth, so dynamic inventories and nested
+// children show up exactly as a playbook run would see them
+func ShowInventory(inventory string) error {
+	args := InventoryGraphArgs(inventory)
+	cmd := execCommand(InventoryBinary, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	output.Info("🔎 Executing: %s", FormatCommand(InventoryBinary, args))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ansible-inventory could not read %s: %w", inventory, err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package executor
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ✅ Test that ShowInventory runs `ansible-inventory --graph` on the inventory
+func TestShowInventory(t *testing.T) {
+	var name string
+	var args []string
+	execCommand = func(n string, arg ...string) *exec.Cmd {
+		name, args = n, arg
+		return mockExecCommand(n, arg...)
+	}
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("MOCK_OUTPUT", "@all:\n  |--@web:\n  |  |--web1\n")
+
+	out := captureOutput(func() {
+		if err := ShowInventory("inv.yml"); err != nil {
+			t.Errorf("ShowInventory returned error: %v", err)
+		}
+	})
+
+	expected := []string{"-i", "inv.yml", "--graph"}
+	if name != "ansible-inventory" || !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected ansible-inventory %q, got %s %q", expected, name, args)
+	}
+	if !strings.Contains(out, "|--web1") {
+		t.Errorf("Expected ansible-inventory's output to be shown, got %q", out)
+	}
+
+	t.Setenv("MOCK_EXIT_CODE", "2")
+	captureOutput(func() {
+		if err := ShowInventory("broken.yml"); err == nil || !strings.Contains(err.Error(), "broken.yml") {
+			t.Errorf("Expected an error naming the inventory, got %v", err)
+		}
+	})
+}
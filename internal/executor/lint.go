@@ -0,0 +1,36 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/bxtal-lsn/gosible/internal/output"
+)
+
+// ✅ Allow overriding exec.LookPath for testing
+var lookPath = exec.LookPath
+
+// ✅ Executable used when RunLint isn't given one
+const LintBinary = "ansible-lint"
+
+// ✅ Run ansible-lint on the playbooks, streaming its output
+// A missing binary is reported with install instructions; lint findings come
+// back as an *exec.ExitError carrying ansible-lint's exit code
+func RunLint(binary string, playbooks []string) error {
+	if binary == "" {
+		binary = LintBinary
+	}
+	if _, err := lookPath(binary); err != nil {
+		err = fmt.Errorf("%s not found, install it with `pip install ansible-lint`: %w", binary, err)
+		output.Error("❌ %v", err)
+		return err
+	}
+
+	cmd := execCommand(binary, playbooks...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	output.Info("🧹 Executing: %s", FormatCommand(binary, playbooks))
+	return cmd.Run()
+}
@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+// ✅ Test that RunLint runs ansible-lint on the given playbooks
+func TestRunLint(t *testing.T) {
+	var name string
+	var args []string
+	execCommand = func(n string, arg ...string) *exec.Cmd {
+		name, args = n, arg
+		return mockExecCommand(n, arg...)
+	}
+	defer func() { execCommand = exec.Command }()
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	defer func() { lookPath = exec.LookPath }()
+
+	captureOutput(func() {
+		if err := RunLint("", []string{"site.yml", "db.yml"}); err != nil {
+			t.Errorf("RunLint returned error: %v", err)
+		}
+	})
+
+	if name != "ansible-lint" || !reflect.DeepEqual(args, []string{"site.yml", "db.yml"}) {
+		t.Errorf("Expected ansible-lint site.yml db.yml, got %s %q", name, args)
+	}
+}
+
+// ✅ Test that a missing ansible-lint is reported without running anything
+func TestRunLint_NotInstalled(t *testing.T) {
+	ran := false
+	execCommand = func(n string, arg ...string) *exec.Cmd {
+		ran = true
+		return mockExecCommand(n, arg...)
+	}
+	defer func() { execCommand = exec.Command }()
+	lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+	defer func() { lookPath = exec.LookPath }()
+
+	var err error
+	captureOutput(func() { err = RunLint("", []string{"site.yml"}) })
+	if !errors.Is(err, exec.ErrNotFound) || ran {
+		t.Errorf("Expected a not found error and no run, got %v (ran: %t)", err, ran)
+	}
+}
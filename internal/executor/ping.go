@@ -0,0 +1,110 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/bxtal-lsn/gosible/internal/output"
+)
+
+// ✅ Why a host failed the connection test
+const (
+	PingAuth        = "auth"        // The host answered but refused the credentials
+	PingTimeout     = "timeout"     // The connection timed out
+	PingUnreachable = "unreachable" // Any other connection failure: refused, no route, unknown host, ...
+	PingFailed      = "failed"      // Connected, but the ping module itself failed, e.g. without python
+)
+
+// ✅ PingResult is one host's outcome of the ping module
+// Reason is empty for reachable hosts; Message is ansible's msg, if any.
+type PingResult struct {
+	Host      string `json:"host"`
+	Reachable bool   `json:"reachable"`
+	Reason    string `json:"reason,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// ✅ Build the `ansible <pattern> -m ping` arguments
+func PingArgs(inventory string, pattern string) []string {
+	return []string{pattern, "-i", inventory, "-m", "ping"}
+}
+
+// ✅ Run the ping module and return ansible's output
+// Ansible exits non-zero when any host fails, so output is returned with that
+// error for the caller to parse; only a failure to run ansible at all is an error.
+func Ping(inventory string, pattern string) ([]byte, error) {
+	args := PingArgs(inventory, pattern)
+	cmd := execCommand(AdHocBinary, args...)
+	cmd.Stderr = os.Stderr
+
+	output.Info("🔄 Executing: %s", FormatCommand(AdHocBinary, args))
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return out, fmt.Errorf("error running the ping module: %w", err)
+	}
+	return out, nil
+}
+
+// ✅ Phrases in ansible's msg that name the kind of failure, checked in order
+var pingReasons = []struct {
+	reason  string
+	phrases []string
+}{
+	{PingAuth, []string{"permission denied", "authentication", "invalid credentials", "incorrect password", "access is denied"}},
+	{PingTimeout, []string{"timed out", "timeout"}},
+}
+
+// ✅ Parse ad-hoc ping output into one result per host, sorted by host
+func ParsePing(out []byte) ([]PingResult, error) {
+	var results []PingResult
+	for _, match := range adHocResult.FindAllSubmatchIndex(out, -1) {
+		host := string(out[match[2]:match[3]])
+		header := string(out[match[0]:match[1]])
+
+		var result struct {
+			Ping        string `json:"ping"`
+			Msg         string `json:"msg"`
+			Unreachable bool   `json:"unreachable"`
+		}
+		decoder := json.NewDecoder(bytes.NewReader(out[match[1]:]))
+		if err := decoder.Decode(&result); err != nil {
+			return nil, fmt.Errorf("error parsing the ping result for %s: %w", host, err)
+		}
+
+		ping := PingResult{Host: host, Message: strings.TrimSpace(result.Msg)}
+		switch {
+		case strings.Contains(header, "| SUCCESS") && result.Ping == "pong":
+			ping.Reachable = true
+		case result.Unreachable || strings.Contains(header, "UNREACHABLE"):
+			ping.Reason = classifyPingFailure(ping.Message, PingUnreachable)
+		default:
+			ping.Reason = classifyPingFailure(ping.Message, PingFailed)
+		}
+		results = append(results, ping)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no ping results found in ansible output")
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Host < results[j].Host })
+	return results, nil
+}
+
+// ✅ Name the kind of failure from ansible's message, or fall back
+func classifyPingFailure(msg string, fallback string) string {
+	lower := strings.ToLower(msg)
+	for _, kind := range pingReasons {
+		for _, phrase := range kind.phrases {
+			if strings.Contains(lower, phrase) {
+				return kind.reason
+			}
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+// ✅ Canned `ansible -m ping` output covering each kind of result
+const cannedPing = `web1 | SUCCESS => {
+    "ansible_facts": {
+        "discovered_interpreter_python": "/usr/bin/python3"
+    },
+    "changed": false,
+    "ping": "pong"
+}
+db1 | UNREACHABLE! => {
+    "changed": false,
+    "msg": "Failed to connect to the host via ssh: ubuntu@10.0.0.7: Permission denied (publickey).",
+    "unreachable": true
+}
+web3 | UNREACHABLE! => {
+    "changed": false,
+    "msg": "Failed to connect to the host via ssh: ssh: connect to host 10.0.0.9 port 22: Connection timed out",
+    "unreachable": true
+}
+web2 | UNREACHABLE! => {
+    "changed": false,
+    "msg": "Failed to connect to the host via ssh: ssh: Could not resolve hostname web2: Name or service not known",
+    "unreachable": true
+}
+cache1 | FAILED! => {
+    "changed": false,
+    "module_stderr": "/bin/sh: 1: /usr/bin/python: not found\n",
+    "msg": "The module failed to execute correctly, you probably need to set the interpreter.\nSee stdout/stderr for the exact error",
+    "rc": 127
+}
+`
+
+// ✅ Test that ping output is classified per host and sorted
+func TestParsePing(t *testing.T) {
+	results, err := ParsePing([]byte(cannedPing))
+	if err != nil {
+		t.Fatalf("ParsePing returned error: %v", err)
+	}
+
+	reasons := map[string]string{}
+	for _, result := range results {
+		if result.Reachable != (result.Reason == "") {
+			t.Errorf("Expected only reasonless results to be reachable, got %+v", result)
+		}
+		reasons[result.Host] = result.Reason
+	}
+	expected := map[string]string{"web1": "", "db1": PingAuth, "web3": PingTimeout, "web2": PingUnreachable, "cache1": PingFailed}
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Errorf("Expected reasons %v, got %v", expected, reasons)
+	}
+	if results[0].Host != "cache1" || results[len(results)-1].Host != "web3" {
+		t.Errorf("Expected results sorted by host, got %+v", results)
+	}
+
+	if _, err := ParsePing([]byte("ERROR! Unable to parse inv.yml as an inventory source")); err == nil {
+		t.Error("Expected an error for output without results")
+	}
+}
+
+// ✅ Test that Ping runs the ping module and keeps output when hosts fail
+func TestPing(t *testing.T) {
+	var name string
+	var args []string
+	execCommand = func(n string, arg ...string) *exec.Cmd {
+		name, args = n, arg
+		return mockExecCommand(n, arg...)
+	}
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("MOCK_OUTPUT", cannedPing)
+	t.Setenv("MOCK_EXIT_CODE", "2")
+
+	var out []byte
+	captureOutput(func() {
+		var err error
+		if out, err = Ping("inv.yml", "web"); err != nil {
+			t.Errorf("Expected failed hosts not to be an error, got %v", err)
+		}
+	})
+
+	expected := []string{"web", "-i", "inv.yml", "-m", "ping"}
+	if name != "ansible" || !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected ansible %q, got %s %q", expected, name, args)
+	}
+	if string(out) != cannedPing {
+		t.Errorf("Expected ansible's output to be returned, got %q", out)
+	}
+}
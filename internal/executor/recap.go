@@ -0,0 +1,129 @@
+package executor
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ✅ HostStats holds one host's counters from the PLAY RECAP
+type HostStats struct {
+	Ok          int
+	Changed     int
+	Unreachable int
+	Failed      int
+	Skipped     int
+	Rescued     int
+	Ignored     int
+}
+
+// ✅ Recap maps each host in the PLAY RECAP to its counters
+type Recap map[string]HostStats
+
+// ✅ Run results derived from a recap
+const (
+	ResultNoChanges = "no changes"
+	ResultChanged   = "changed"
+)
+
+var (
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	recapLine  = regexp.MustCompile(`^(\S+)\s+:\s+(ok=\d+.*)$`)
+)
+
+// ✅ Parse the PLAY RECAP block out of ansible-playbook's output
+// Colour codes are ignored; output without a recap gives an empty Recap
+func ParseRecap(out string) Recap {
+	recap := Recap{}
+	inRecap := false
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(ansiEscape.ReplaceAllString(line, ""))
+		if strings.HasPrefix(line, "PLAY RECAP") {
+			inRecap = true
+			continue
+		}
+		if !inRecap {
+			continue
+		}
+		if host, stats, ok := parseRecapLine(line); ok {
+			recap[host] = stats
+		}
+	}
+	return recap
+}
+
+// ✅ Total number of changed tasks across all hosts
+func (r Recap) Changed() int {
+	total := 0
+	for _, stats := range r {
+		total += stats.Changed
+	}
+	return total
+}
+
+// ✅ Hosts reporting at least one change, sorted by name
+func (r Recap) ChangedHosts() []string {
+	var hosts []string
+	for host, stats := range r {
+		if stats.Changed > 0 {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// ✅ Hosts with failed tasks or that were unreachable, sorted by name
+func (r Recap) FailedHosts() []string {
+	var hosts []string
+	for host, stats := range r {
+		if stats.Failed > 0 || stats.Unreachable > 0 {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// ✅ ResultNoChanges when every host reported changed=0, ResultChanged otherwise
+func (r Recap) Result() string {
+	if r.Changed() == 0 {
+		return ResultNoChanges
+	}
+	return ResultChanged
+}
+
+// ✅ Parse one `host : ok=1 changed=0 ...` recap line
+func parseRecapLine(line string) (string, HostStats, bool) {
+	match := recapLine.FindStringSubmatch(line)
+	if match == nil {
+		return "", HostStats{}, false
+	}
+
+	var stats HostStats
+	for _, field := range strings.Fields(match[2]) {
+		key, value, _ := strings.Cut(field, "=")
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "ok":
+			stats.Ok = n
+		case "changed":
+			stats.Changed = n
+		case "unreachable":
+			stats.Unreachable = n
+		case "failed":
+			stats.Failed = n
+		case "skipped":
+			stats.Skipped = n
+		case "rescued":
+			stats.Rescued = n
+		case "ignored":
+			stats.Ignored = n
+		}
+	}
+	return match[1], stats, true
+}
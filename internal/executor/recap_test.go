@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+const cleanRecap = "PLAY [web] *****\n\n" +
+	"TASK [nginx : install] *****\n" +
+	"ok: [web1]\n\n" +
+	"PLAY RECAP *****\n" +
+	"web1                       : ok=3    changed=0    unreachable=0    failed=0    skipped=1    rescued=0    ignored=0   \n" +
+	"web2                       : ok=3    changed=0    unreachable=0    failed=0    skipped=1    rescued=0    ignored=0   \n\n"
+
+const changedRecap = "PLAY RECAP *****\n" +
+	"\x1b[0;33mweb1\x1b[0m                       : \x1b[0;32mok=4   \x1b[0m \x1b[0;33mchanged=2   \x1b[0m unreachable=0    failed=0    skipped=0    rescued=0    ignored=0   \n" +
+	"web2                       : ok=3    changed=0    unreachable=0    failed=1    skipped=0    rescued=0    ignored=0"
+
+// ✅ Test that a recap with changed=0 everywhere is classified as no changes
+func TestParseRecap_NoChanges(t *testing.T) {
+	recap := ParseRecap(cleanRecap)
+
+	expected := Recap{
+		"web1": {Ok: 3, Skipped: 1},
+		"web2": {Ok: 3, Skipped: 1},
+	}
+	if !reflect.DeepEqual(recap, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, recap)
+	}
+	if recap.Result() != ResultNoChanges {
+		t.Errorf("Expected %q, got %q", ResultNoChanges, recap.Result())
+	}
+}
+
+// ✅ Test that colour codes are ignored and changes are counted
+func TestParseRecap_Changed(t *testing.T) {
+	recap := ParseRecap(changedRecap)
+
+	if recap["web1"] != (HostStats{Ok: 4, Changed: 2}) || recap["web2"] != (HostStats{Ok: 3, Failed: 1}) {
+		t.Errorf("Unexpected stats: %+v", recap)
+	}
+	if recap.Result() != ResultChanged || recap.Changed() != 2 {
+		t.Errorf("Expected %q with 2 changes, got %q with %d", ResultChanged, recap.Result(), recap.Changed())
+	}
+	if hosts := recap.ChangedHosts(); !reflect.DeepEqual(hosts, []string{"web1"}) {
+		t.Errorf("Expected [web1] to have changed, got %v", hosts)
+	}
+	if hosts := recap.FailedHosts(); !reflect.DeepEqual(hosts, []string{"web2"}) {
+		t.Errorf("Expected [web2] to have failed, got %v", hosts)
+	}
+	if len(ParseRecap("ok: [web1]\n")) != 0 {
+		t.Error("Expected output without a recap to parse to an empty recap")
+	}
+}
+
+// ✅ Test that Run hands the recap to OnRecap, also when the playbook fails
+func TestRun_OnRecap(t *testing.T) {
+	execCommand = mockExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("MOCK_OUTPUT", changedRecap)
+	t.Setenv("MOCK_EXIT_CODE", "2")
+
+	var recap Recap
+	captureOutput(func() {
+		Run(Options{Inventory: "inv.yml", Playbook: "site.yml", OnRecap: func(r Recap) { recap = r }})
+	})
+	if recap.Changed() != 2 {
+		t.Errorf("Expected the recap with 2 changes, got %+v", recap)
+	}
+}
@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// ✅ recapFilter passes through only the PLAY RECAP block and failure lines
+// Output is buffered per line, so Flush must be called once the run ends to
+// write out a final line without a newline
+type recapFilter struct {
+	w       io.Writer
+	pending []byte
+	inRecap bool
+}
+
+func newRecapFilter(w io.Writer) *recapFilter {
+	return &recapFilter{w: w}
+}
+
+func (f *recapFilter) Write(p []byte) (int, error) {
+	f.pending = append(f.pending, p...)
+	for {
+		i := bytes.IndexByte(f.pending, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := f.pending[:i+1]
+		if err := f.writeLine(line); err != nil {
+			return len(p), err
+		}
+		f.pending = f.pending[i+1:]
+	}
+}
+
+// ✅ Write out a trailing partial line
+func (f *recapFilter) Flush() error {
+	if len(f.pending) == 0 {
+		return nil
+	}
+	err := f.writeLine(f.pending)
+	f.pending = nil
+	return err
+}
+
+func (f *recapFilter) writeLine(line []byte) error {
+	text := string(line)
+	if strings.HasPrefix(text, "PLAY RECAP") {
+		f.inRecap = true
+	}
+	if !f.inRecap && !isFailureLine(text) {
+		return nil
+	}
+	_, err := f.w.Write(line)
+	return err
+}
+
+// ✅ Failures are kept so a summary-only run still says what went wrong
+func isFailureLine(line string) bool {
+	return strings.HasPrefix(line, "fatal:") ||
+		strings.HasPrefix(line, "ERROR!") ||
+		strings.Contains(line, "FAILED!")
+}
@@ -0,0 +1,39 @@
+package executor
+
+import (
+	"bytes"
+	"testing"
+)
+
+// ✅ Test that only failures and the recap survive the filter, across split writes
+func TestRecapFilter(t *testing.T) {
+	out := "PLAY [web] *****\n\n" +
+		"TASK [Gathering Facts] *****\n" +
+		"ok: [web1]\n" +
+		"fatal: [web2]: UNREACHABLE! => {\"changed\": false}\n" +
+		"TASK [nginx : install] *****\n" +
+		"changed: [web1]\n\n" +
+		"PLAY RECAP *****\n" +
+		"web1 : ok=2 changed=1 unreachable=0 failed=0\n" +
+		"web2 : ok=0 changed=0 unreachable=1 failed=0"
+
+	var buf bytes.Buffer
+	filter := newRecapFilter(&buf)
+	for i := 0; i < len(out); i += 7 { // Chunks don't line up with lines
+		end := min(i+7, len(out))
+		if _, err := filter.Write([]byte(out[i:end])); err != nil {
+			t.Fatalf("Write returned error: %v", err)
+		}
+	}
+	if err := filter.Flush(); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+
+	expected := "fatal: [web2]: UNREACHABLE! => {\"changed\": false}\n" +
+		"PLAY RECAP *****\n" +
+		"web1 : ok=2 changed=1 unreachable=0 failed=0\n" +
+		"web2 : ok=0 changed=0 unreachable=1 failed=0"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
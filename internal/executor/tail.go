@@ -0,0 +1,50 @@
+package executor
+
+// ✅ Output kept for recap parsing when Options.CaptureLimit isn't set
+// Enough for the recap of a couple of thousand hosts
+const DefaultCaptureLimit = 256 * 1024
+
+// ✅ tailBuffer keeps only the last limit bytes written to it
+// It never holds more than twice the limit, however much is written
+type tailBuffer struct {
+	limit     int
+	buf       []byte
+	truncated bool
+}
+
+func newTailBuffer(limit int) *tailBuffer {
+	if limit <= 0 {
+		limit = DefaultCaptureLimit
+	}
+	return &tailBuffer{limit: limit}
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	if len(p) >= t.limit {
+		t.truncated = t.truncated || len(t.buf) > 0 || len(p) > t.limit
+		t.buf = append(t.buf[:0], p[len(p)-t.limit:]...)
+		return len(p), nil
+	}
+
+	t.buf = append(t.buf, p...)
+	// Compact only once the buffer doubles, so copying stays amortized
+	if len(t.buf) > 2*t.limit {
+		n := copy(t.buf, t.buf[len(t.buf)-t.limit:])
+		t.buf = t.buf[:n]
+		t.truncated = true
+	}
+	return len(p), nil
+}
+
+// ✅ The retained tail, at most limit bytes
+func (t *tailBuffer) Bytes() []byte {
+	if len(t.buf) > t.limit {
+		return t.buf[len(t.buf)-t.limit:]
+	}
+	return t.buf
+}
+
+// ✅ Report whether earlier output was dropped
+func (t *tailBuffer) Truncated() bool {
+	return t.truncated || len(t.buf) > t.limit
+}
@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// ✅ Test that only the tail is retained once the cap is exceeded
+func TestTailBuffer(t *testing.T) {
+	tail := newTailBuffer(64)
+	for i := 0; i < 100; i++ {
+		tail.Write([]byte("ok: [web1] => {\"msg\": \"noise\"}\n"))
+		if len(tail.buf) > 128 {
+			t.Fatalf("Expected at most twice the cap to be held, got %d bytes", len(tail.buf))
+		}
+	}
+	tail.Write([]byte("PLAY RECAP ***\nweb1 : ok=2 changed=1 failed=0\n"))
+
+	got := string(tail.Bytes())
+	if len(got) != 64 || !tail.Truncated() || !strings.HasSuffix(got, "web1 : ok=2 changed=1 failed=0\n") {
+		t.Errorf("Expected the last 64 bytes, got %d bytes: %q", len(got), got)
+	}
+
+	small := newTailBuffer(64)
+	small.Write([]byte("short"))
+	if string(small.Bytes()) != "short" || small.Truncated() {
+		t.Errorf("Expected output under the cap to be kept whole, got %q", small.Bytes())
+	}
+	small.Write([]byte(strings.Repeat("x", 100)))
+	if string(small.Bytes()) != strings.Repeat("x", 64) || !small.Truncated() {
+		t.Errorf("Expected a write over the cap to keep its tail, got %q", small.Bytes())
+	}
+}
+
+// ✅ Test that the recap is still found when the output exceeds the cap
+func TestRun_CaptureLimit(t *testing.T) {
+	execCommand = mockExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("MOCK_OUTPUT", strings.Repeat("changed: [web1] => {\"verbose\": true}\n", 500)+changedRecap)
+
+	var recap Recap
+	captureOutput(func() {
+		Run(Options{Inventory: "inv.yml", Playbook: "site.yml", CaptureLimit: 1024, OnRecap: func(r Recap) { recap = r }})
+	})
+	if recap.Changed() != 2 || len(recap) != 2 {
+		t.Errorf("Expected the recap from the tail, got %+v", recap)
+	}
+}
@@ -0,0 +1,47 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bxtal-lsn/gosible/internal/output"
+)
+
+// ✅ Executable used for vault operations
+const VaultBinary = "ansible-vault"
+
+// ✅ Supported ansible-vault actions
+const (
+	VaultEncrypt = "encrypt"
+	VaultDecrypt = "decrypt"
+)
+
+// ✅ Build the ansible-vault arguments for action on file
+func VaultArgs(action string, file string, passwordFile string) []string {
+	args := []string{action}
+	if passwordFile != "" {
+		args = append(args, "--vault-password-file", passwordFile)
+	}
+	return append(args, file)
+}
+
+// ✅ Run ansible-vault encrypt/decrypt on file
+// Without a password file ansible-vault prompts for the password on the terminal
+func RunVault(action string, file string, passwordFile string) error {
+	if action != VaultEncrypt && action != VaultDecrypt {
+		return fmt.Errorf("unsupported vault action %q", action)
+	}
+
+	args := VaultArgs(action, file, passwordFile)
+	cmd := execCommand(VaultBinary, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	output.Info("🔐 Executing: %s", FormatCommand(VaultBinary, args))
+	if err := cmd.Run(); err != nil {
+		output.Error("❌ Error running %s %s: %v", VaultBinary, action, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+// ✅ Test the ansible-vault argument slices
+func TestVaultArgs(t *testing.T) {
+	for _, tc := range []struct {
+		action       string
+		passwordFile string
+		expected     []string
+	}{
+		{VaultEncrypt, "", []string{"encrypt", "secrets.yml"}},
+		{VaultDecrypt, "", []string{"decrypt", "secrets.yml"}},
+		{VaultEncrypt, "~/.vault_pass", []string{"encrypt", "--vault-password-file", "~/.vault_pass", "secrets.yml"}},
+	} {
+		if got := VaultArgs(tc.action, "secrets.yml", tc.passwordFile); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("VaultArgs(%q, %q) = %q, expected %q", tc.action, tc.passwordFile, got, tc.expected)
+		}
+	}
+}
+
+// ✅ Test that RunVault executes ansible-vault with the built arguments
+func TestRunVault(t *testing.T) {
+	var name string
+	var args []string
+	execCommand = func(n string, arg ...string) *exec.Cmd {
+		name, args = n, arg
+		return mockExecCommand(n, arg...)
+	}
+	defer func() { execCommand = exec.Command }()
+
+	captureOutput(func() {
+		if err := RunVault(VaultDecrypt, "secrets.yml", "vault.txt"); err != nil {
+			t.Errorf("RunVault returned error: %v", err)
+		}
+	})
+
+	expected := []string{"decrypt", "--vault-password-file", "vault.txt", "secrets.yml"}
+	if name != "ansible-vault" || !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected ansible-vault %q, got %s %q", expected, name, args)
+	}
+
+	if err := RunVault("rekey", "secrets.yml", ""); err == nil {
+		t.Error("Expected an error for an unsupported action")
+	}
+}
@@ -0,0 +1,59 @@
+package inventory
+
+import (
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ✅ Matches the instance number at the end of a name, e.g. `-1` or `03`
+var instanceNumber = regexp.MustCompile(`[-_]?\d+$`)
+
+// ✅ Suggest a group from a conventionally numbered name
+// `web-1`, `web02` and `web-3.example.com` give `web`; `db-replica-2` gives
+// `db_replica`, since dashes aren't valid in ansible group names. Names
+// without a trailing number and IP addresses give no suggestion.
+func InferGroup(name string) string {
+	if net.ParseIP(name) != nil {
+		return ""
+	}
+	short, _, _ := strings.Cut(name, ".")
+	prefix := instanceNumber.ReplaceAllString(short, "")
+	if prefix == short {
+		return ""
+	}
+	prefix = strings.Trim(prefix, "-_")
+	return strings.ReplaceAll(prefix, "-", "_")
+}
+
+// ✅ Group the names InferGroup has a suggestion for, keyed by group
+// Names keep their order; names without a suggestion are left out
+func InferGroups(names []string) map[string][]string {
+	groups := map[string][]string{}
+	for _, name := range names {
+		if group := InferGroup(name); group != "" {
+			groups[group] = append(groups[group], name)
+		}
+	}
+	return groups
+}
+
+// ✅ Sorted group names of an InferGroups result
+func sortedGroupNames(groups map[string][]string) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ✅ Describe suggested groups for display, e.g. `db (db-1), web (web-1, web-2)`
+func DescribeGroups(groups map[string][]string) string {
+	parts := make([]string, 0, len(groups))
+	for _, name := range sortedGroupNames(groups) {
+		parts = append(parts, name+" ("+strings.Join(groups[name], ", ")+")")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,37 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ✅ Test group suggestions from instance names
+func TestInferGroup(t *testing.T) {
+	for name, expected := range map[string]string{
+		"web-1":             "web",
+		"web02":             "web",
+		"db_3":              "db",
+		"web-3.example.com": "web",
+		"db-replica-2":      "db_replica",
+		"bastion":           "",
+		"10.0.0.5":          "",
+		"42":                "",
+	} {
+		if got := InferGroup(name); got != expected {
+			t.Errorf("InferGroup(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+// ✅ Test that names are grouped by their prefix
+func TestInferGroups(t *testing.T) {
+	groups := InferGroups([]string{"web-1", "db-1", "bastion", "web-2"})
+
+	expected := map[string][]string{"web": {"web-1", "web-2"}, "db": {"db-1"}}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("Expected %v, got %v", expected, groups)
+	}
+	if got := DescribeGroups(groups); got != "db (db-1), web (web-1, web-2)" {
+		t.Errorf("Unexpected description %q", got)
+	}
+}
@@ -0,0 +1,122 @@
+package inventory
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ✅ Lines of unchanged context shown around each change
+const diffContext = 3
+
+// ✅ One line of a diff: kept (' '), removed ('-') or added ('+'), with its
+// zero-based position in the old and new text
+type diffLine struct {
+	kind     byte
+	text     string
+	old, new int
+}
+
+// ✅ Render a unified diff turning before into after, like `diff -u`, or ""
+// when they're the same
+// Inventories are small, so a plain longest-common-subsequence diff is enough.
+func Diff(before, after, fromName, toName string) string {
+	if before == after {
+		return ""
+	}
+	lines := diffLines(splitLines(before), splitLines(after))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+	for start := 0; start < len(lines); {
+		// Find the next change and the end of its hunk, merging changes whose
+		// context would overlap or touch
+		first := start
+		for first < len(lines) && lines[first].kind == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		end := first
+		for i := first; i < len(lines) && i <= end+2*diffContext+1; i++ {
+			if lines[i].kind != ' ' {
+				end = i
+			}
+		}
+		from := max(first-diffContext, start)
+		to := min(end+1+diffContext, len(lines))
+		writeHunk(&b, lines[from:to])
+		start = to
+	}
+	return b.String()
+}
+
+// ✅ Write one hunk with its @@ header
+func writeHunk(b *strings.Builder, hunk []diffLine) {
+	oldCount, newCount := 0, 0
+	for _, line := range hunk {
+		if line.kind != '+' {
+			oldCount++
+		}
+		if line.kind != '-' {
+			newCount++
+		}
+	}
+	// Ranges are one-based; an empty range names the line before it
+	oldStart, newStart := hunk[0].old+1, hunk[0].new+1
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, line := range hunk {
+		fmt.Fprintf(b, "%c%s\n", line.kind, line.text)
+	}
+}
+
+// ✅ Line up a and b along their longest common subsequence
+// Removals are listed before additions where lines were replaced.
+func diffLines(a, b []string) []diffLine {
+	// common[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i], i, j})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i], i, j})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j], i, j})
+			j++
+		}
+	}
+	return lines
+}
+
+// ✅ Split text into lines without their newlines
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
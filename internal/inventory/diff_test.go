@@ -0,0 +1,31 @@
+package inventory
+
+import "testing"
+
+// ✅ Test that Diff renders unified hunks with context and merges nearby changes
+func TestDiff(t *testing.T) {
+	if got := Diff("a\nb\n", "a\nb\n", "old", "new"); got != "" {
+		t.Errorf("Expected no diff for equal content, got %q", got)
+	}
+
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n18\n19\n20\n"
+	after := "1\ntwo\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n18\n19\n20\n21\n"
+	expected := "--- old\n+++ new\n" +
+		"@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n" +
+		"@@ -18,3 +18,4 @@\n 18\n 19\n 20\n+21\n"
+	if got := Diff(before, after, "old", "new"); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	// Changes six lines apart share a hunk, as their context would touch
+	after = "1\n2\nthree\n4\n5\n6\n7\n8\n9\nten\n11\n12\n13\n"
+	expected = "--- old\n+++ new\n" +
+		"@@ -1,13 +1,13 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n 7\n 8\n 9\n-10\n+ten\n 11\n 12\n 13\n"
+	if got := Diff("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n", after, "old", "new"); got != expected {
+		t.Errorf("Expected one merged hunk:\n%s\ngot:\n%s", expected, got)
+	}
+
+	if got := Diff("", "a\n", "old", "new"); got != "--- old\n+++ new\n@@ -0,0 +1,1 @@\n+a\n" {
+		t.Errorf("Unexpected diff from empty content: %q", got)
+	}
+}
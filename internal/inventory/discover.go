@@ -0,0 +1,332 @@
+package inventory
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/bxtal-lsn/gosible/internal/output"
+	"github.com/bxtal-lsn/gosible/internal/prompt"
+)
+
+// ✅ Allow overriding exec.LookPath for testing
+var lookPath = exec.LookPath
+
+// ✅ Instance is a running machine or container found during discovery
+type Instance struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"` // Name of the provider that found it
+	State   string `json:"state"`
+	Address string `json:"address,omitempty"` // IP, empty when not reachable over the network
+
+	// ✅ Ansible connection needed to reach it, empty for SSH
+	ConnectionType string `json:"connection,omitempty"`
+
+	// ✅ Why the instance failed VerifyInstances, empty when reachable or unchecked
+	Unreachable string `json:"unreachable,omitempty"`
+}
+
+// ✅ Discovery sources
+const (
+	SourceMultipass = "multipass"
+	SourceDocker    = "docker"
+	SourceVagrant   = "vagrant"
+	SourceLXD       = "lxd"
+)
+
+// ✅ HostConfig seeds an inventory entry for the instance
+// SSH instances are addressed by IP when one is known; instances with another
+// connection type, such as docker containers, are addressed by name
+func (i Instance) HostConfig() HostConfig {
+	if i.ConnectionType != "" && i.ConnectionType != ConnectionSSH {
+		return HostConfig{Host: i.Name, Connection: i.ConnectionType}
+	}
+	if i.Address != "" {
+		return HostConfig{Host: i.Address}
+	}
+	return HostConfig{Host: i.Name}
+}
+
+// ✅ Label shows the instance with its source in selection lists
+func (i Instance) Label() string {
+	label := fmt.Sprintf("[%s] %s", i.Source, i.Name)
+	if i.Address != "" && i.Address != i.Name {
+		label += fmt.Sprintf(" (%s)", i.Address)
+	}
+	if i.Unreachable != "" {
+		label += " ⚠️ unreachable: " + i.Unreachable
+	}
+	return label
+}
+
+// ✅ Provider lists instances by running a command and parsing its output
+// Parse should return an error when the output isn't in the expected format
+type Provider struct {
+	Name    string
+	Command []string
+	Parse   func(out []byte) ([]Instance, error)
+}
+
+// ✅ ProviderStatus reports the outcome of querying a single provider
+type ProviderStatus struct {
+	Provider  string
+	Installed bool
+	Instances []Instance
+	Err       error
+}
+
+// ✅ Providers queried by DiscoverAllInstances, in order
+var Providers = []Provider{
+	{Name: SourceMultipass, Command: []string{"multipass", "list", "--format", "csv"}, Parse: parseMultipassCSV},
+	{Name: SourceDocker, Command: []string{"docker", "ps", "--format", "{{.Names}}"}, Parse: parseDockerNames},
+	{Name: SourceVagrant, Command: []string{"vagrant", "status", "--machine-readable"}, Parse: parseVagrantStatus},
+	{Name: SourceLXD, Command: []string{"lxc", "list", "--format", "csv", "--columns", "ns4"}, Parse: parseLXCList},
+}
+
+// ✅ Add a discovery provider
+func RegisterProvider(provider Provider) {
+	Providers = append(Providers, provider)
+}
+
+// ✅ Query every provider and report how each one went
+// Providers are queried concurrently, but statuses come back in the order of
+// Providers so the instance list is the same whichever answers first.
+// Providers whose command isn't installed are marked as such and skipped.
+func DiscoverByProvider() []ProviderStatus {
+	statuses := make([]ProviderStatus, len(Providers))
+	var wg sync.WaitGroup
+	for i, provider := range Providers {
+		wg.Add(1)
+		go func(i int, provider Provider) {
+			defer wg.Done()
+			statuses[i] = queryProvider(provider)
+		}(i, provider)
+	}
+	wg.Wait()
+	return statuses
+}
+
+// ✅ Run a single provider's command and parse its instances
+func queryProvider(provider Provider) ProviderStatus {
+	status := ProviderStatus{Provider: provider.Name}
+	if _, err := lookPath(provider.Command[0]); err != nil {
+		return status
+	}
+	status.Installed = true
+
+	out, err := execCommand(provider.Command[0], provider.Command[1:]...).Output()
+	if err != nil {
+		status.Err = fmt.Errorf("%s: %s failed: %w", provider.Name, provider.Command[0], err)
+	} else if status.Instances, err = provider.Parse(out); err != nil {
+		status.Err = fmt.Errorf("%s: %w", provider.Name, err)
+	}
+	return status
+}
+
+// ✅ Discover all running instances from every provider without prompting
+// Providers that aren't installed are skipped. Instances from working
+// providers are returned even when another provider fails.
+func DiscoverAllInstances() ([]Instance, error) {
+	var instances []Instance
+	var errs []error
+	for _, status := range DiscoverByProvider() {
+		instances = append(instances, status.Instances...)
+		if status.Err != nil {
+			errs = append(errs, status.Err)
+		}
+	}
+	return instances, errors.Join(errs...)
+}
+
+// ✅ Auto-discover instances and let the user pick
+// With verify set, unreachable instances are marked in the list. Use
+// Instance.HostConfig to turn the selection into inventory entries.
+func DiscoverInstances(reader *bufio.Reader, verify bool) []Instance {
+	output.Info("🔍 Checking for running instances...")
+	var instances []Instance
+	for _, status := range DiscoverByProvider() {
+		switch {
+		case !status.Installed:
+			output.Info("⏭️ %s not found, skipping", status.Provider)
+		case status.Err != nil:
+			output.Warn("⚠️ %v", status.Err)
+		default:
+			output.Info("✅ %s: found %d running instance(s)", status.Provider, len(status.Instances))
+		}
+		instances = append(instances, status.Instances...)
+	}
+	if verify && len(instances) > 0 {
+		output.Info("📡 Checking that the instances are reachable...")
+		instances = VerifyInstances(instances)
+	}
+
+	// ✅ Prompt user to select instances
+	if len(instances) > 0 {
+		output.Println("\n🔍 Found the following instances:")
+		for i, instance := range instances {
+			output.Printf("[%d] %s\n", i+1, instance.Label())
+		}
+		indices := askSelection(reader, "\nSelect instances to add (space-separated numbers, or type 'all' for all):", len(instances))
+
+		selectedInstances := []Instance{}
+		for _, i := range indices {
+			selectedInstances = append(selectedInstances, instances[i])
+		}
+		return selectedInstances
+	}
+
+	output.Warn("⚠️ No running instances found.")
+	return []Instance{}
+}
+
+// ✅ Ask until the answer is a valid selection of count items
+func askSelection(reader *bufio.Reader, question string, count int) []int {
+	for {
+		output.Prompt(question)
+		input, err := reader.ReadString('\n')
+		indices, parseErr := prompt.ParseIndices(input, count)
+		if parseErr == nil {
+			return indices
+		}
+		output.Warn("⚠️ %v", parseErr)
+		if err != nil {
+			return nil // No more input to retry with
+		}
+	}
+}
+
+// ✅ Parse `multipass list --format csv` output by header name
+// Column order differs between multipass releases, so positions are looked up
+// from the header row. Instances without an IP are skipped and only the first
+// address of a multi-IP instance is used.
+func parseMultipassCSV(out []byte) ([]Instance, error) {
+	reader := csv.NewReader(strings.NewReader(string(out)))
+	reader.FieldsPerRecord = -1 // Rows may be ragged across versions
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected multipass output: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	// ✅ Locate the columns we need; without a header we can't trust positions
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	nameCol, hasName := columns["Name"]
+	stateCol, hasState := columns["State"]
+	ipCol, hasIP := columns["IPv4"]
+	if !hasName || !hasState || !hasIP {
+		return nil, errors.New("unexpected multipass output: missing Name, State or IPv4 column")
+	}
+
+	var instances []Instance
+	for _, record := range records[1:] {
+		if len(record) <= nameCol || len(record) <= stateCol || len(record) <= ipCol {
+			continue
+		}
+		state := strings.TrimSpace(record[stateCol])
+		if state != "Running" {
+			continue
+		}
+		ips := strings.FieldsFunc(record[ipCol], func(r rune) bool {
+			return r == ',' || r == ';' || r == ' '
+		})
+		if len(ips) == 0 || ips[0] == "--" {
+			continue
+		}
+		instances = append(instances, Instance{
+			Name:    strings.TrimSpace(record[nameCol]),
+			Source:  SourceMultipass,
+			State:   state,
+			Address: ips[0],
+		})
+	}
+	return instances, nil
+}
+
+// ✅ Parse `docker ps --format {{.Names}}` output, one container per line
+func parseDockerNames(out []byte) ([]Instance, error) {
+	var instances []Instance
+	for _, line := range strings.Split(string(out), "\n") {
+		name := strings.TrimSpace(line)
+		if name == "" {
+			continue
+		}
+		instances = append(instances, Instance{
+			Name:           name,
+			Source:         SourceDocker,
+			State:          "running", // `docker ps` only lists running containers
+			ConnectionType: ConnectionDocker,
+		})
+	}
+	return instances, nil
+}
+
+// ✅ Parse `vagrant status --machine-readable` output
+// Lines are `timestamp,target,type,data...`; running machines report a
+// `state` line with data `running`
+func parseVagrantStatus(out []byte) ([]Instance, error) {
+	var instances []Instance
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("unexpected vagrant output line: %q", line)
+		}
+		if len(fields) < 4 || fields[1] == "" || fields[2] != "state" {
+			continue
+		}
+		if fields[3] != "running" {
+			continue
+		}
+		instances = append(instances, Instance{
+			Name:   fields[1],
+			Source: SourceVagrant,
+			State:  fields[3],
+		})
+	}
+	return instances, nil
+}
+
+// ✅ Parse `lxc list --format csv --columns ns4` output
+// The IPv4 column holds entries like `10.0.0.5 (eth0)`, newline-separated
+// when a container has several addresses; the first one is used
+func parseLXCList(out []byte) ([]Instance, error) {
+	reader := csv.NewReader(strings.NewReader(string(out)))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected lxc output: %w", err)
+	}
+
+	var instances []Instance
+	for _, record := range records {
+		if len(record) < 3 {
+			return nil, fmt.Errorf("unexpected lxc output row: %q", strings.Join(record, ","))
+		}
+		if strings.TrimSpace(record[1]) != "RUNNING" {
+			continue
+		}
+		address := ""
+		if ips := strings.Fields(record[2]); len(ips) > 0 {
+			address = ips[0]
+		}
+		instances = append(instances, Instance{
+			Name:    strings.TrimSpace(record[0]),
+			Source:  SourceLXD,
+			State:   strings.TrimSpace(record[1]),
+			Address: address,
+		})
+	}
+	return instances, nil
+}
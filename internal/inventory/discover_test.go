@@ -0,0 +1,330 @@
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ✅ Mock provider commands, treating the listed ones as not installed
+func mockDiscovery(t *testing.T, missing ...string) {
+	t.Helper()
+	oldExecCommand, oldLookPath := execCommand, lookPath
+	execCommand = mockExecCommand
+	lookPath = func(file string) (string, error) {
+		for _, name := range missing {
+			if name == file {
+				return "", fmt.Errorf("%s: executable file not found in $PATH", file)
+			}
+		}
+		return "/usr/bin/" + file, nil
+	}
+	t.Cleanup(func() { execCommand, lookPath = oldExecCommand, oldLookPath })
+}
+
+// ✅ Capture stdout and stderr output
+func captureOutput(f func()) string {
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stdout, os.Stderr = w, w
+
+	f()
+
+	w.Close()
+	os.Stdout, os.Stderr = oldStdout, oldStderr
+
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+	return buf.String()
+}
+
+// ✅ Test `DiscoverInstances`
+func TestDiscoverInstances(t *testing.T) {
+	// ✅ Override `execCommand`
+	mockDiscovery(t)
+
+	// ✅ Simulate user input selecting "all"
+	reader := bufio.NewReader(strings.NewReader("all\n"))
+
+	// ✅ Pass the reader to `DiscoverInstances`
+	instances := DiscoverInstances(reader, false)
+
+	// ✅ Check that instances carry their source and connection
+	expected := []Instance{
+		{Name: "instance1", Source: SourceMultipass, State: "Running", Address: "10.0.0.5"},
+		{Name: "instance2", Source: SourceMultipass, State: "Running", Address: "10.0.0.6"},
+		{Name: "container1", Source: SourceDocker, State: "running", ConnectionType: ConnectionDocker},
+		{Name: "container2", Source: SourceDocker, State: "running", ConnectionType: ConnectionDocker},
+	}
+	if !reflect.DeepEqual(instances, expected) {
+		t.Errorf("Expected instances %+v, got %+v", expected, instances)
+	}
+}
+
+// ✅ Test that docker-discovered hosts default to the docker connection
+func TestDiscoverInstances_DockerConnection(t *testing.T) {
+	mockDiscovery(t)
+
+	reader := bufio.NewReader(strings.NewReader("all\n"))
+	instances := DiscoverInstances(reader, false)
+
+	for _, instance := range instances {
+		host := instance.HostConfig()
+		want := ""
+		if instance.Source == SourceDocker {
+			want = ConnectionDocker
+		}
+		if host.Connection != want {
+			t.Errorf("Expected %s to have connection %q, got %q", host.Host, want, host.Connection)
+		}
+	}
+}
+
+// ✅ Test multipass CSV parsing by header name with multiple IPs
+func TestDiscoverInstances_MultipassColumns(t *testing.T) {
+	mockDiscovery(t)
+
+	// Newer releases reorder columns and may list several addresses
+	t.Setenv("MOCK_MULTIPASS_OUTPUT", "Name,Release,IPv4,State\n"+
+		"multi,22.04 LTS,\"10.0.0.7,172.17.0.1\",Running\n"+
+		"noip,22.04 LTS,--,Running\n"+
+		"empty,22.04 LTS,,Running\n"+
+		"stopped,22.04 LTS,10.0.0.8,Stopped\n")
+
+	instances := DiscoverInstances(bufio.NewReader(strings.NewReader("all\n")), false)
+
+	var multipassHosts []string
+	for _, instance := range instances {
+		if instance.Source == SourceMultipass {
+			multipassHosts = append(multipassHosts, instance.HostConfig().Host)
+		}
+	}
+	if len(multipassHosts) != 1 || multipassHosts[0] != "10.0.0.7" {
+		t.Errorf("Expected only the first IP of the running instance, got %v", multipassHosts)
+	}
+}
+
+// ✅ Test that output without a header row yields no multipass instances
+func TestParseMultipassCSV_Headerless(t *testing.T) {
+	instances, err := parseMultipassCSV([]byte("instance1,Running,10.0.0.5\n"))
+	if len(instances) != 0 {
+		t.Errorf("Expected no instances from headerless output, got %v", instances)
+	}
+	if err == nil {
+		t.Error("Expected an error for output without a header row")
+	}
+}
+
+// ✅ Test non-interactive discovery returns structured instances
+func TestDiscoverAllInstances(t *testing.T) {
+	mockDiscovery(t)
+
+	instances, err := DiscoverAllInstances()
+	if err != nil {
+		t.Fatalf("DiscoverAllInstances returned error: %v", err)
+	}
+
+	expected := []Instance{
+		{Name: "instance1", Source: SourceMultipass, State: "Running", Address: "10.0.0.5"},
+		{Name: "instance2", Source: SourceMultipass, State: "Running", Address: "10.0.0.6"},
+		{Name: "container1", Source: SourceDocker, State: "running", ConnectionType: ConnectionDocker},
+		{Name: "container2", Source: SourceDocker, State: "running", ConnectionType: ConnectionDocker},
+	}
+	if !reflect.DeepEqual(instances, expected) {
+		t.Errorf("Expected instances %+v, got %+v", expected, instances)
+	}
+}
+
+// ✅ Test that missing providers are skipped rather than failing discovery
+func TestDiscoverAllInstances_NoProviders(t *testing.T) {
+	mockDiscovery(t, "multipass", "docker", "vagrant", "lxc")
+
+	instances, err := DiscoverAllInstances()
+	if err != nil {
+		t.Fatalf("DiscoverAllInstances returned error: %v", err)
+	}
+	if len(instances) != 0 {
+		t.Errorf("Expected no instances, got %+v", instances)
+	}
+}
+
+// ✅ Test per-provider status when one provider is missing and one is empty
+func TestDiscoverByProvider_MissingAndEmpty(t *testing.T) {
+	mockDiscovery(t, "multipass")
+	t.Setenv("MOCK_DOCKER_OUTPUT", "")
+
+	statuses := DiscoverByProvider()
+	byName := map[string]ProviderStatus{}
+	for _, status := range statuses {
+		byName[status.Provider] = status
+	}
+
+	if status := byName[SourceMultipass]; status.Installed {
+		t.Errorf("Expected multipass to be reported as not installed, got %+v", status)
+	}
+	if status := byName[SourceDocker]; !status.Installed || status.Err != nil || len(status.Instances) != 0 {
+		t.Errorf("Expected docker to be installed with no instances, got %+v", status)
+	}
+
+	// ✅ The interactive flow only reports "none found" when nothing was found
+	output := captureOutput(func() {
+		DiscoverInstances(bufio.NewReader(strings.NewReader("")), false)
+	})
+	for _, expected := range []string{"multipass not found, skipping", "docker: found 0 running instance(s)", "No running instances found"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+// ✅ Test that malformed output from an installed provider is an error
+func TestDiscoverAllInstances_MalformedOutput(t *testing.T) {
+	mockDiscovery(t)
+	t.Setenv("MOCK_MULTIPASS_OUTPUT", "this is not csv from multipass\n")
+
+	instances, err := DiscoverAllInstances()
+	if err == nil || !strings.Contains(err.Error(), "multipass") {
+		t.Fatalf("Expected a multipass error, got %v", err)
+	}
+
+	// ✅ Other providers still contribute their instances
+	if len(instances) != 2 || instances[0].Source != SourceDocker {
+		t.Errorf("Expected the docker instances despite the error, got %+v", instances)
+	}
+}
+
+// ✅ Test vagrant machine-readable status parsing
+func TestDiscoverAllInstances_Vagrant(t *testing.T) {
+	mockDiscovery(t)
+
+	t.Setenv("MOCK_MULTIPASS_OUTPUT", "")
+	t.Setenv("MOCK_DOCKER_OUTPUT", "")
+	t.Setenv("MOCK_VAGRANT_OUTPUT", "1700000000,web,metadata,provider,virtualbox\n"+
+		"1700000000,web,provider-name,virtualbox\n"+
+		"1700000000,web,state,running\n"+
+		"1700000000,db,state,poweroff\n"+
+		"1700000000,,ui,info,Current machine states:\n")
+
+	instances, err := DiscoverAllInstances()
+	if err != nil {
+		t.Fatalf("DiscoverAllInstances returned error: %v", err)
+	}
+
+	expected := []Instance{{Name: "web", Source: SourceVagrant, State: "running"}}
+	if !reflect.DeepEqual(instances, expected) {
+		t.Errorf("Expected instances %+v, got %+v", expected, instances)
+	}
+	if label := instances[0].Label(); label != "[vagrant] web" {
+		t.Errorf("Expected label %q, got %q", "[vagrant] web", label)
+	}
+}
+
+// ✅ Test lxc CSV list parsing
+func TestDiscoverAllInstances_LXD(t *testing.T) {
+	mockDiscovery(t)
+
+	t.Setenv("MOCK_MULTIPASS_OUTPUT", "")
+	t.Setenv("MOCK_DOCKER_OUTPUT", "")
+	t.Setenv("MOCK_LXC_OUTPUT", "app,RUNNING,\"10.10.0.5 (eth0)\n172.17.0.1 (docker0)\"\n"+
+		"old,STOPPED,\n")
+
+	instances, err := DiscoverAllInstances()
+	if err != nil {
+		t.Fatalf("DiscoverAllInstances returned error: %v", err)
+	}
+
+	expected := []Instance{{Name: "app", Source: SourceLXD, State: "RUNNING", Address: "10.10.0.5"}}
+	if !reflect.DeepEqual(instances, expected) {
+		t.Errorf("Expected instances %+v, got %+v", expected, instances)
+	}
+	if host := instances[0].HostConfig().Host; host != "10.10.0.5" {
+		t.Errorf("Expected host 10.10.0.5, got %q", host)
+	}
+	if label := instances[0].Label(); label != "[lxd] app (10.10.0.5)" {
+		t.Errorf("Expected label %q, got %q", "[lxd] app (10.10.0.5)", label)
+	}
+}
+
+// ✅ Test that registered providers take part in discovery
+func TestRegisterProvider(t *testing.T) {
+	mockDiscovery(t)
+	oldProviders := Providers
+	defer func() { Providers = oldProviders }()
+
+	Providers = nil
+	RegisterProvider(Provider{
+		Name:    "custom",
+		Command: []string{"custom", "list"},
+		Parse: func(out []byte) ([]Instance, error) {
+			return []Instance{{Name: strings.TrimSpace(string(out)), Source: "custom", State: "running"}}, nil
+		},
+	})
+	t.Setenv("MOCK_CUSTOM_OUTPUT", "box1\n")
+
+	instances, err := DiscoverAllInstances()
+	if err != nil {
+		t.Fatalf("DiscoverAllInstances returned error: %v", err)
+	}
+	if len(instances) != 1 || instances[0].Label() != "[custom] box1" {
+		t.Errorf("Expected the custom provider's instance, got %+v", instances)
+	}
+}
+
+// ✅ Test that providers are queried concurrently and merged in provider order
+func TestDiscoverByProvider_Concurrent(t *testing.T) {
+	mockDiscovery(t, "lxc")
+	oldProviders := Providers
+	defer func() { Providers = oldProviders }()
+
+	// The slow provider only finishes once the fast one has, which would
+	// never happen if they ran one after the other
+	fastDone := make(chan struct{})
+	var closeOnce sync.Once
+	slow := Provider{Name: "slow", Command: []string{"slow"}, Parse: func(out []byte) ([]Instance, error) {
+		select {
+		case <-fastDone:
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("providers weren't queried concurrently")
+		}
+		return []Instance{{Name: "b1", Source: "slow"}, {Name: "a1", Source: "slow"}}, nil
+	}}
+	fast := Provider{Name: "fast", Command: []string{"fast"}, Parse: func(out []byte) ([]Instance, error) {
+		defer closeOnce.Do(func() { close(fastDone) })
+		return []Instance{{Name: "f1", Source: "fast"}}, nil
+	}}
+	broken := Provider{Name: "broken", Command: []string{"broken"}, Parse: func(out []byte) ([]Instance, error) {
+		return nil, errors.New("unexpected output")
+	}}
+	Providers = []Provider{slow, broken, fast, Providers[3]}
+
+	statuses := DiscoverByProvider()
+	var names []string
+	for _, status := range statuses {
+		names = append(names, status.Provider)
+	}
+	if !reflect.DeepEqual(names, []string{"slow", "broken", "fast", SourceLXD}) {
+		t.Errorf("Expected statuses in provider order, got %v", names)
+	}
+	if statuses[1].Err == nil || statuses[0].Err != nil || statuses[2].Err != nil || statuses[3].Installed {
+		t.Errorf("Expected only the broken provider to fail and lxc to be missing, got %+v", statuses)
+	}
+
+	instances, err := DiscoverAllInstances()
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Expected the broken provider's error, got %v", err)
+	}
+	var labels []string
+	for _, instance := range instances {
+		labels = append(labels, instance.Label())
+	}
+	if expected := []string{"[slow] b1", "[slow] a1", "[fast] f1"}; !reflect.DeepEqual(labels, expected) {
+		t.Errorf("Expected instances %v, got %v", expected, labels)
+	}
+}
@@ -0,0 +1,34 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ✅ Report whether path is an executable file, i.e. a dynamic inventory script
+func IsExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
+
+// ✅ Check that a dynamic inventory script emits JSON for `--list`
+func ValidateInventoryScript(path string) error {
+	if !IsExecutable(path) {
+		return fmt.Errorf("%s is not an executable inventory script", path)
+	}
+
+	out, err := execCommand(path, "--list").Output()
+	if err != nil {
+		return fmt.Errorf("error running %s --list: %w", path, err)
+	}
+
+	var parsed map[string]any
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return fmt.Errorf("%s --list did not emit a JSON object: %w", path, err)
+	}
+	return nil
+}
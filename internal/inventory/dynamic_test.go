@@ -0,0 +1,48 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ✅ Test executable detection for dynamic inventory scripts
+func TestIsExecutable(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "ec2.py")
+	static := filepath.Join(dir, "inv.yml")
+	os.WriteFile(script, []byte("#!/bin/sh\n"), 0o755)
+	os.WriteFile(static, []byte("---\n"), 0o644)
+
+	if !IsExecutable(script) {
+		t.Errorf("Expected %s to be executable", script)
+	}
+	if IsExecutable(static) {
+		t.Errorf("Expected %s not to be executable", static)
+	}
+	if IsExecutable(dir) {
+		t.Error("Expected a directory not to count as an executable script")
+	}
+}
+
+// ✅ Test validating a script's `--list` output
+func TestValidateInventoryScript(t *testing.T) {
+	oldExecCommand := execCommand
+	execCommand = mockExecCommand
+	defer func() { execCommand = oldExecCommand }()
+
+	script := filepath.Join(t.TempDir(), "ec2.py")
+	os.WriteFile(script, []byte("#!/bin/sh\n"), 0o755)
+
+	// The mock keys canned output on the command's base name
+	envName := "MOCK_EC2.PY_OUTPUT"
+	t.Setenv(envName, `{"all": {"hosts": ["web1"]}, "_meta": {"hostvars": {}}}`)
+	if err := ValidateInventoryScript(script); err != nil {
+		t.Errorf("Expected valid JSON to pass, got %v", err)
+	}
+
+	t.Setenv(envName, "not json")
+	if err := ValidateInventoryScript(script); err == nil {
+		t.Error("Expected an error for non-JSON output")
+	}
+}
@@ -0,0 +1,285 @@
+package inventory
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ✅ Add hosts to an existing inventory file and rewrite it
+// A host already defined in the same group is replaced in place, new hosts
+// are added after the existing ones. The file is re-rendered, so files with
+// anything HostConfig doesn't model (comments, group or all vars, non-string
+// host vars, nested groups) are rejected rather than rewritten without it. A
+// host in several groups is added to each of them, and groups without hosts
+// are kept.
+func AppendHostsToInventory(path string, hosts []HostConfig) error {
+	content, err := RenderAppendHosts(path, hosts)
+	if err != nil {
+		return err
+	}
+	return OverwriteInventoryFile(path, content, false)
+}
+
+// ✅ Render the inventory AppendHostsToInventory would write, without writing it
+func RenderAppendHosts(path string, hosts []HostConfig) (string, error) {
+	inv, err := loadRewritable(path)
+	if err != nil {
+		return "", err
+	}
+
+	merged := inv.Hosts
+	for _, host := range splitGroups(hosts) {
+		if i := indexOfHost(merged, host.Host, host.Group); i >= 0 {
+			merged[i] = host
+			continue
+		}
+		merged = append(merged, host)
+	}
+
+	return renderInventory(merged, emptyGroups(inv))
+}
+
+// ✅ Remove a host from every group of an inventory file and rewrite it
+// Groups left without hosts are dropped, groups that had none are kept. Like AppendHostsToInventory, files
+// with comments, group or all vars, non-string host vars or nested groups are
+// rejected rather than rewritten without them.
+func RemoveHostFromInventory(path string, host string) error {
+	content, err := RenderRemoveHost(path, host)
+	if err != nil {
+		return err
+	}
+	return OverwriteInventoryFile(path, content, false)
+}
+
+// ✅ Render the inventory RemoveHostFromInventory would write, without writing it
+func RenderRemoveHost(path string, host string) (string, error) {
+	inv, err := loadRewritable(path)
+	if err != nil {
+		return "", err
+	}
+
+	remaining := make([]HostConfig, 0, len(inv.Hosts))
+	for _, h := range inv.Hosts {
+		if h.Host != host {
+			remaining = append(remaining, h)
+		}
+	}
+	if len(remaining) == len(inv.Hosts) {
+		return "", fmt.Errorf("host %q not found in %s", host, path)
+	}
+
+	return renderInventory(remaining, emptyGroups(inv))
+}
+
+// ✅ Load an inventory that can safely be re-rendered by RenderInventory
+// Files RenderInventory wouldn't reproduce are rejected, so rewriting never
+// drops what the parsed hosts don't carry.
+func loadRewritable(path string) (*Inventory, error) {
+	inv, err := LoadInventory(path)
+	if err != nil {
+		return nil, err
+	}
+	if inv.Format == FormatINI {
+		return nil, fmt.Errorf("%s is an INI inventory, which can't be rewritten yet; edit it by hand", path)
+	}
+	if len(inv.Children) > 0 {
+		return nil, fmt.Errorf("%s has nested groups, which can't be rewritten yet; edit it by hand", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading inventory file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing inventory: %w", err)
+	}
+	if hasComments(&doc) {
+		return nil, fmt.Errorf("%s has comments, which would be lost when rewriting it; edit it by hand", path)
+	}
+	loss, err := rewriteLoss(data, inv)
+	if err != nil {
+		return nil, err
+	}
+	if loss != "" {
+		return nil, fmt.Errorf("%s has %s, which gosible can't rewrite; edit it by hand", path, loss)
+	}
+	return inv, nil
+}
+
+// ✅ Groups defined in the inventory without any host
+func emptyGroups(inv *Inventory) []string {
+	populated := map[string]bool{}
+	for _, host := range inv.Hosts {
+		populated[host.Group] = true
+	}
+	var empty []string
+	for _, group := range inv.Groups {
+		if !populated[group] {
+			empty = append(empty, group)
+		}
+	}
+	return empty
+}
+
+// ✅ Whether any node of a parsed YAML document carries a comment
+func hasComments(node *yaml.Node) bool {
+	if node.HeadComment != "" || node.LineComment != "" || node.FootComment != "" {
+		return true
+	}
+	for _, child := range node.Content {
+		if hasComments(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// ✅ What re-rendering inv would lose from data, or "" if nothing
+// Both are compared as plain YAML values, ignoring layout and empty sections.
+func rewriteLoss(data []byte, inv *Inventory) (string, error) {
+	rendered, err := renderInventory(inv.Hosts, emptyGroups(inv))
+	if err != nil {
+		return "", err
+	}
+	var original, rewritten any
+	if err := yaml.Unmarshal(data, &original); err != nil {
+		return "", fmt.Errorf("error parsing inventory: %w", err)
+	}
+	if err := yaml.Unmarshal([]byte(rendered), &rewritten); err != nil {
+		return "", fmt.Errorf("error parsing rendered inventory: %w", err)
+	}
+	before, after := normalizeInventory(original), normalizeInventory(rewritten)
+	if reflect.DeepEqual(before, after) {
+		return "", nil
+	}
+	if loss := groupLoss("all", before, after); loss != "" {
+		return loss, nil
+	}
+	return "settings that don't round-trip", nil
+}
+
+// ✅ Describe the first setting of a normalized group that differs once
+// rewritten, e.g. `vars in group "web"`
+// Group settings are checked before hosts, as group vars applied to the hosts
+// make them differ too.
+func groupLoss(name string, before, after map[string]any) string {
+	for _, key := range sortedKeys(before) {
+		if key != "hosts" && key != "children" && !reflect.DeepEqual(before[key], after[key]) {
+			return fmt.Sprintf("%s in group %q", key, name)
+		}
+	}
+	children, _ := before["children"].(map[string]any)
+	rewrittenChildren, _ := after["children"].(map[string]any)
+	for _, child := range sortedKeys(children) {
+		group, _ := children[child].(map[string]any)
+		rewritten, _ := rewrittenChildren[child].(map[string]any)
+		if loss := groupLoss(child, group, rewritten); loss != "" {
+			return loss
+		}
+	}
+	hosts, _ := before["hosts"].(map[string]any)
+	rewrittenHosts, _ := after["hosts"].(map[string]any)
+	for _, host := range sortedKeys(hosts) {
+		if !reflect.DeepEqual(hosts[host], rewrittenHosts[host]) {
+			return fmt.Sprintf("vars of host %q in group %q that aren't plain strings", host, name)
+		}
+	}
+	return ""
+}
+
+// ✅ Sorted keys of a YAML mapping, so the first difference is stable
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ✅ Fold top-level groups into `all: children` and drop empty sections, so
+// equivalent inventories compare equal
+func normalizeInventory(value any) map[string]any {
+	root, _ := value.(map[string]any)
+	all := normalizeGroup(root["all"])
+	for name, group := range root {
+		if name == "all" {
+			continue
+		}
+		children, _ := all["children"].(map[string]any)
+		if children == nil {
+			children = map[string]any{}
+			all["children"] = children
+		}
+		children[name] = normalizeGroup(group)
+	}
+	return all
+}
+
+// ✅ Normalize a group: empty `hosts` and `children` are dropped, hosts
+// without vars are nil and child groups are normalized in turn
+// Any other key, such as `vars`, is kept as is.
+func normalizeGroup(value any) map[string]any {
+	group, _ := value.(map[string]any)
+	normalized := map[string]any{}
+	for key, section := range group {
+		entries, isMap := section.(map[string]any)
+		switch {
+		case (key == "hosts" || key == "children") && section == nil:
+		case (key == "hosts" || key == "children") && isMap && len(entries) == 0:
+		case key == "hosts" && isMap:
+			hosts := map[string]any{}
+			for name, vars := range entries {
+				if settings, ok := vars.(map[string]any); ok && len(settings) == 0 {
+					vars = nil
+				}
+				hosts[name] = vars
+			}
+			normalized[key] = hosts
+		case key == "children" && isMap:
+			children := map[string]any{}
+			for name, child := range entries {
+				children[name] = normalizeGroup(child)
+			}
+			normalized[key] = children
+		default:
+			normalized[key] = section
+		}
+	}
+	return normalized
+}
+
+// ✅ Turn hosts in several groups into one single-group entry per group
+// The first group's entry carries the settings, like RenderInventory writes them
+func splitGroups(hosts []HostConfig) []HostConfig {
+	var split []HostConfig
+	for _, host := range hosts {
+		names := host.GroupNames()
+		if len(names) == 0 {
+			split = append(split, host)
+			continue
+		}
+		first := host
+		first.Group, first.Groups = names[0], nil
+		split = append(split, first)
+		for _, name := range names[1:] {
+			split = append(split, HostConfig{Host: host.Host, Group: name})
+		}
+	}
+	return split
+}
+
+// ✅ Position of the host in group, or -1
+func indexOfHost(hosts []HostConfig, name string, group string) int {
+	for i, host := range hosts {
+		if host.Host == name && host.Group == group {
+			return i
+		}
+	}
+	return -1
+}
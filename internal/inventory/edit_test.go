@@ -0,0 +1,267 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ✅ Test appending hosts to an inventory created earlier
+func TestAppendHostsToInventory(t *testing.T) {
+	path, err := CreateInventoryFile(t.TempDir(), []HostConfig{
+		{Host: "10.0.0.5", SSHUser: "ubuntu"},
+		{Host: "db1", Group: "db", SSHUser: "root"},
+	})
+	if err != nil {
+		t.Fatalf("CreateInventoryFile returned error: %v", err)
+	}
+
+	err = AppendHostsToInventory(path, []HostConfig{
+		{Host: "web1", Group: "web", SSHUser: "ubuntu"},
+		{Host: "db1", Group: "db", SSHUser: "postgres"}, // Replaces the existing entry
+		{Host: "10.0.0.6"},
+	})
+	if err != nil {
+		t.Fatalf("AppendHostsToInventory returned error: %v", err)
+	}
+
+	inv, err := LoadInventory(path)
+	if err != nil {
+		t.Fatalf("LoadInventory returned error: %v", err)
+	}
+	expected := []HostConfig{
+		{Host: "10.0.0.5", SSHUser: "ubuntu"},
+		{Host: "10.0.0.6"},
+		{Host: "db1", Group: "db", SSHUser: "postgres"},
+		{Host: "web1", Group: "web", SSHUser: "ubuntu"},
+	}
+	if !reflect.DeepEqual(inv.Hosts, expected) {
+		t.Errorf("Expected hosts %+v, got %+v", expected, inv.Hosts)
+	}
+}
+
+// ✅ Test that a host added with several groups joins each of them
+func TestAppendHostsToInventory_MultipleGroups(t *testing.T) {
+	path, err := CreateInventoryFile(t.TempDir(), []HostConfig{{Host: "db1", Group: "prod", SSHUser: "root"}})
+	if err != nil {
+		t.Fatalf("CreateInventoryFile returned error: %v", err)
+	}
+
+	if err := AppendHostsToInventory(path, []HostConfig{{Host: "web1", Groups: []string{"web", "prod"}, SSHUser: "ubuntu"}}); err != nil {
+		t.Fatalf("AppendHostsToInventory returned error: %v", err)
+	}
+
+	inv, err := LoadInventory(path)
+	if err != nil {
+		t.Fatalf("LoadInventory returned error: %v", err)
+	}
+	expected := []HostConfig{
+		{Host: "db1", Group: "prod", SSHUser: "root"},
+		{Host: "web1", Group: "prod"},
+		{Host: "web1", Group: "web", SSHUser: "ubuntu"},
+	}
+	if !reflect.DeepEqual(inv.Hosts, expected) {
+		t.Errorf("Expected hosts %+v, got %+v", expected, inv.Hosts)
+	}
+}
+
+// ✅ Test that nested groups are rejected instead of being flattened
+func TestAppendHostsToInventory_NestedGroups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inv.yml")
+	content := "all:\n  children:\n    prod:\n      children:\n        web:\n          hosts:\n            web1:\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AppendHostsToInventory(path, []HostConfig{{Host: "web2", Group: "web"}}); err == nil {
+		t.Error("Expected an error for an inventory with nested groups")
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Errorf("Expected the inventory to be left alone, got:\n%s", data)
+	}
+}
+
+// ✅ Inventories with content RenderInventory can't reproduce, which must be
+// refused rather than rewritten without it
+var unmodeledInventories = []struct {
+	name    string
+	content string
+	reason  string // Part of the error naming what can't be rewritten
+}{
+	{"all vars", "all:\n  vars:\n    ansible_user: deploy\n  hosts:\n    web1:\n", `vars in group "all"`},
+	{"group vars", "all:\n  children:\n    web:\n      vars:\n        http_port: 80\n      hosts:\n        web1:\n", `vars in group "web"`},
+	{"int host var", "all:\n  hosts:\n    web1:\n      http_port: 8080\n", `host "web1"`},
+	{"list host var", "all:\n  hosts:\n    web1:\n      packages: [nginx, curl]\n", `host "web1"`},
+	{"comment", "# Managed by hand\nall:\n  hosts:\n    web1:\n", "comments"},
+}
+
+// ✅ Test that adding to an inventory with unmodeled content leaves it alone
+func TestAppendHostsToInventory_UnmodeledContent(t *testing.T) {
+	for _, tc := range unmodeledInventories {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "inv.yml")
+			if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := RenderAppendHosts(path, []HostConfig{{Host: "web2"}}); err == nil || !strings.Contains(err.Error(), tc.reason) {
+				t.Errorf("Expected RenderAppendHosts to refuse the inventory naming %s, got %v", tc.reason, err)
+			}
+			if err := AppendHostsToInventory(path, []HostConfig{{Host: "web2"}}); err == nil {
+				t.Error("Expected AppendHostsToInventory to refuse the inventory")
+			}
+			if data, _ := os.ReadFile(path); string(data) != tc.content {
+				t.Errorf("Expected the inventory to be left alone, got:\n%s", data)
+			}
+		})
+	}
+}
+
+// ✅ Test that a hand-written inventory with only modeled content, including
+// a top-level group, can still be added to
+func TestAppendHostsToInventory_HandWritten(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inv.yml")
+	content := "all:\n  hosts:\n    web1:\n      ansible_user: ubuntu\n      role: frontend\ndb:\n  hosts:\n    db1: {}\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AppendHostsToInventory(path, []HostConfig{{Host: "web2"}}); err != nil {
+		t.Fatalf("AppendHostsToInventory returned error: %v", err)
+	}
+	inv, err := LoadInventory(path)
+	if err != nil {
+		t.Fatalf("LoadInventory returned error: %v", err)
+	}
+	if len(inv.Hosts) != 3 {
+		t.Errorf("Expected 3 hosts, got %+v", inv.Hosts)
+	}
+}
+
+// ✅ Test that groups defined without hosts survive adding and removing hosts
+func TestEditInventory_EmptyGroups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inv.yml")
+	content := "all:\n  hosts:\n    web1:\n  children:\n    db: {}\n    web:\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AppendHostsToInventory(path, []HostConfig{{Host: "web2", Group: "web"}}); err != nil {
+		t.Fatalf("AppendHostsToInventory returned error: %v", err)
+	}
+	if err := RemoveHostFromInventory(path, "web1"); err != nil {
+		t.Fatalf("RemoveHostFromInventory returned error: %v", err)
+	}
+	inv, err := LoadInventory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []HostConfig{{Host: "web2", Group: "web"}}
+	if !reflect.DeepEqual(inv.Hosts, expected) {
+		t.Errorf("Expected hosts %+v, got %+v", expected, inv.Hosts)
+	}
+	if !reflect.DeepEqual(inv.Groups, []string{"db", "web"}) {
+		t.Errorf("Expected the empty db group to be kept, got %v", inv.Groups)
+	}
+}
+
+// ✅ Test removing ungrouped and grouped hosts, and dropping emptied groups
+func TestRemoveHostFromInventory(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		host           string
+		expectedHosts  []string
+		expectedGroups []string
+	}{
+		{"ungrouped", "10.0.0.5", []string{"db1", "web1", "web2"}, []string{"db", "web"}},
+		{"grouped", "web1", []string{"10.0.0.5", "db1", "web2"}, []string{"db", "web"}},
+		{"last in group", "db1", []string{"10.0.0.5", "web1", "web2"}, []string{"web"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := CreateInventoryFile(t.TempDir(), []HostConfig{
+				{Host: "10.0.0.5"},
+				{Host: "web1", Group: "web"},
+				{Host: "web2", Group: "web"},
+				{Host: "db1", Group: "db"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := RemoveHostFromInventory(path, tc.host); err != nil {
+				t.Fatalf("RemoveHostFromInventory returned error: %v", err)
+			}
+
+			inv, err := LoadInventory(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, host := range inv.Hosts {
+				names = append(names, host.Host)
+			}
+			if !reflect.DeepEqual(names, tc.expectedHosts) {
+				t.Errorf("Expected hosts %v, got %v", tc.expectedHosts, names)
+			}
+			if !reflect.DeepEqual(inv.Groups, tc.expectedGroups) {
+				t.Errorf("Expected groups %v, got %v", tc.expectedGroups, inv.Groups)
+			}
+		})
+	}
+}
+
+// ✅ Test that a host defined in several groups is removed from all of them
+func TestRemoveHostFromInventory_SeveralGroups(t *testing.T) {
+	path, err := CreateInventoryFile(t.TempDir(), []HostConfig{
+		{Host: "web1", Group: "web"},
+		{Host: "web1", Group: "monitored"},
+		{Host: "db1", Group: "db"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RemoveHostFromInventory(path, "web1"); err != nil {
+		t.Fatalf("RemoveHostFromInventory returned error: %v", err)
+	}
+	inv, _ := LoadInventory(path)
+	expected := []HostConfig{{Host: "db1", Group: "db"}}
+	if !reflect.DeepEqual(inv.Hosts, expected) {
+		t.Errorf("Expected hosts %+v, got %+v", expected, inv.Hosts)
+	}
+}
+
+// ✅ Test that removing an unknown host is an error
+func TestRemoveHostFromInventory_Missing(t *testing.T) {
+	path, err := CreateInventoryFile(t.TempDir(), []HostConfig{{Host: "web1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveHostFromInventory(path, "web9"); err == nil {
+		t.Error("Expected an error for a host that isn't in the inventory")
+	}
+}
+
+// ✅ Test that removing from an inventory with unmodeled content leaves it alone
+func TestRemoveHostFromInventory_UnmodeledContent(t *testing.T) {
+	for _, tc := range unmodeledInventories {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "inv.yml")
+			if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := RenderRemoveHost(path, "web1"); err == nil {
+				t.Error("Expected RenderRemoveHost to refuse the inventory")
+			}
+			if err := RemoveHostFromInventory(path, "web1"); err == nil {
+				t.Error("Expected RemoveHostFromInventory to refuse the inventory")
+			}
+			if data, _ := os.ReadFile(path); string(data) != tc.content {
+				t.Errorf("Expected the inventory to be left alone, got:\n%s", data)
+			}
+		})
+	}
+}
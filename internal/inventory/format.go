@@ -0,0 +1,63 @@
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ✅ Format is the syntax of an inventory file
+type Format string
+
+// ✅ Supported inventory formats; FormatAuto detects one from the content
+const (
+	FormatAuto Format = "auto"
+	FormatYAML Format = "yaml"
+	FormatINI  Format = "ini"
+)
+
+// ✅ Format LoadInventory reads files as, set with SetFormat
+var format = FormatAuto
+
+// ✅ Matches a YAML mapping key such as `all:` or `web: {}`
+var yamlKey = regexp.MustCompile(`^[^\s=#]+:(\s|$)`)
+
+// ✅ Make LoadInventory read every file as the given format
+// An empty format means FormatAuto
+func SetFormat(f Format) error {
+	switch f {
+	case "":
+		format = FormatAuto
+	case FormatAuto, FormatYAML, FormatINI:
+		format = f
+	default:
+		return fmt.Errorf("unknown inventory format %q, expected auto, yaml or ini", f)
+	}
+	return nil
+}
+
+// ✅ Guess whether inventory content is YAML or INI from its first
+// significant line: `---`, `all:` or another `key:` means YAML, a `[group]`
+// header or a bare `host key=value` line means INI
+// Empty content counts as YAML.
+func DetectFormat(data []byte) Format {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			continue
+		case line == "---" || strings.HasPrefix(line, "%YAML") || strings.HasPrefix(line, "{"):
+			return FormatYAML
+		case strings.HasPrefix(line, "["):
+			return FormatINI
+		case yamlKey.MatchString(line):
+			return FormatYAML
+		default:
+			return FormatINI
+		}
+	}
+	return FormatYAML
+}
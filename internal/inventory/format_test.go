@@ -0,0 +1,54 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ✅ Test format detection from sample content
+func TestDetectFormat(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		content  string
+		expected Format
+	}{
+		{"document start", "---\nall:\n  hosts:\n", FormatYAML},
+		{"all key", "# generated\n\nall:\n  hosts:\n    web1:\n", FormatYAML},
+		{"top-level group", "web:\n  hosts:\n", FormatYAML},
+		{"json", `{"all": {"hosts": {}}}`, FormatYAML},
+		{"empty", "\n# nothing yet\n", FormatYAML},
+		{"group header", "; comment\n[web]\nweb1\n", FormatINI},
+		{"ungrouped host vars", "web1 ansible_host=10.0.0.5\n[db]\ndb1\n", FormatINI},
+		{"host and port", "10.0.0.5:2222\n", FormatINI},
+	} {
+		if got := DetectFormat([]byte(tc.content)); got != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, got)
+		}
+	}
+}
+
+// ✅ Test that LoadInventory follows detection unless a format is forced
+func TestLoadInventory_Format(t *testing.T) {
+	defer SetFormat(FormatAuto)
+	dir := t.TempDir()
+	ini := filepath.Join(dir, "hosts")
+	os.WriteFile(ini, []byte("[web]\nweb1\n"), 0o644)
+	yml := filepath.Join(dir, "hosts.yml")
+	os.WriteFile(yml, []byte("web1:\n"), 0o644) // Reads like YAML but isn't an inventory
+
+	if inv, err := LoadInventory(ini); err != nil || inv.Format != FormatINI {
+		t.Errorf("Expected the INI format to be detected, got %+v, %v", inv, err)
+	}
+
+	if err := SetFormat(FormatINI); err != nil {
+		t.Fatal(err)
+	}
+	if inv, err := LoadInventory(yml); err != nil || inv.Format != FormatINI || inv.Hosts[0].Host != "web1:" {
+		t.Errorf("Expected --inventory-format ini to override detection, got %+v, %v", inv, err)
+	}
+
+	if err := SetFormat("toml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,193 @@
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ✅ Load an INI inventory file into one HostConfig per host definition
+// See ParseINIInventory for how sections and variables are read
+func LoadINIInventory(path string) ([]HostConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading inventory file: %w", err)
+	}
+	inv, err := ParseINIInventory(data)
+	if err != nil {
+		return nil, err
+	}
+	return inv.Hosts, nil
+}
+
+// ✅ Parse INI inventory content
+// Hosts above the first section are ungrouped. `[group:vars]` values apply to
+// the hosts of the group and of its child groups, and `[all:vars]` to every
+// host, unless the host line sets them itself; `[group:children]` nests
+// groups. Like ansible, section vars are strings while host line values are
+// Python literals, so only host line values that are strings land in Vars.
+func ParseINIInventory(data []byte) (*Inventory, error) {
+	inv := &Inventory{Children: map[string][]string{}, Format: FormatINI}
+	vars := newPendingVars()
+	seen := map[string]bool{}
+	addGroup := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			inv.Groups = append(inv.Groups, name)
+		}
+	}
+
+	section, kind := "", ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("error parsing inventory: line %d: unterminated section header %q", lineNo, line)
+			}
+			name, suffix, _ := strings.Cut(strings.TrimSpace(line[1:len(line)-1]), ":")
+			if suffix != "" && suffix != "vars" && suffix != "children" {
+				return nil, fmt.Errorf("error parsing inventory: line %d: unknown section type %q", lineNo, suffix)
+			}
+			section, kind = name, suffix
+			if section == "all" || section == "ungrouped" {
+				section = ""
+			}
+			addGroup(section)
+			continue
+		}
+
+		switch kind {
+		case "vars":
+			key, value, found := strings.Cut(line, "=")
+			if !found || strings.TrimSpace(key) == "" {
+				return nil, fmt.Errorf("error parsing inventory: line %d: expected key=value in [%s:vars]", lineNo, groupLabel(section))
+			}
+			vars.groups[section] = append(vars.groups[section], [2]string{strings.TrimSpace(key), strings.TrimSpace(value)})
+		case "children":
+			addGroup(line)
+			if section != "" {
+				inv.Children[section] = append(inv.Children[section], line)
+			}
+		default:
+			host, keys, err := parseINIHost(line, section)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing inventory: line %d: %w", lineNo, err)
+			}
+			inv.Hosts = append(inv.Hosts, host)
+			vars.hosts = append(vars.hosts, keys)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading inventory: %w", err)
+	}
+
+	inv.applyGroupVars(vars)
+	return inv, nil
+}
+
+// ✅ Parse a `host[:port] key=value ...` line
+// Returns the keys set on the line so group vars don't override them
+func parseINIHost(line string, group string) (HostConfig, map[string]bool, error) {
+	fields := splitINIFields(line)
+	host := HostConfig{Host: fields[0], Group: group}
+	if name, port, found := strings.Cut(fields[0], ":"); found && !strings.Contains(port, ":") {
+		if _, err := strconv.Atoi(port); err == nil {
+			host.Host, host.SSHPort = name, port
+		}
+	}
+
+	keys := map[string]bool{}
+	for _, field := range fields[1:] {
+		key, raw, found := strings.Cut(field, "=")
+		if !found || key == "" {
+			return HostConfig{}, nil, fmt.Errorf("expected key=value after host %q, got %q", host.Host, field)
+		}
+		keys[key] = true
+		value, isString := iniInlineValue(raw)
+		if host.setConnectionVar(key, value) {
+			continue
+		}
+		if isString {
+			host.setVar(key, value)
+		}
+	}
+	return host, keys, nil
+}
+
+// ✅ Unquote a host line value, reporting whether ansible reads it as a string
+// Quoted text is a string; numbers, True/False/None, lists, dicts and tuples
+// aren't
+func iniInlineValue(raw string) (string, bool) {
+	if len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') && raw[len(raw)-1] == raw[0] {
+		return raw[1 : len(raw)-1], true
+	}
+	if raw == "True" || raw == "False" || raw == "None" {
+		return raw, false
+	}
+	if _, err := strconv.ParseFloat(raw, 64); err == nil {
+		return raw, false
+	}
+	if strings.HasPrefix(raw, "[") || strings.HasPrefix(raw, "{") || strings.HasPrefix(raw, "(") {
+		return raw, false
+	}
+	return raw, true
+}
+
+// ✅ Split a host line on whitespace outside quotes, keeping the quotes
+// A `#` starting a field begins a comment, as in ansible's parser
+func splitINIFields(line string) []string {
+	var fields []string
+	var current strings.Builder
+	var quote rune
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			current.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			current.WriteRune(r)
+		case r == ' ' || r == '\t':
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+		case r == '#' && current.Len() == 0:
+			return fields
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
+
+// ✅ Every group group is nested in, nearest first
+func ancestors(group string, parents map[string][]string) []string {
+	var found []string
+	seen := map[string]bool{group: true}
+	queue := []string{group}
+	for len(queue) > 0 {
+		for _, parent := range parents[queue[0]] {
+			if !seen[parent] {
+				seen[parent] = true
+				found = append(found, parent)
+				queue = append(queue, parent)
+			}
+		}
+		queue = queue[1:]
+	}
+	return found
+}
@@ -0,0 +1,98 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleINI = `# Ungrouped hosts come first
+bastion ansible_host=203.0.113.10 ansible_user=admin
+
+[web]
+web1 ansible_host=10.0.0.5 role=frontend
+web2:2222 http_port=8080 motd="hello world"  # inline comment
+
+[db]
+db1 ansible_become=true tier='primary'
+
+[prod:children]
+web
+db
+
+[all:vars]
+ansible_user=ubuntu
+ntp_server=pool.ntp.org
+
+[prod:vars]
+env=production
+role=generic
+
+[web:vars]
+ansible_python_interpreter=/usr/bin/python3
+`
+
+// ✅ Test groups, host vars and group vars from an INI inventory
+func TestParseINIInventory(t *testing.T) {
+	inv, err := ParseINIInventory([]byte(sampleINI))
+	if err != nil {
+		t.Fatalf("ParseINIInventory returned error: %v", err)
+	}
+
+	expected := []HostConfig{
+		{Host: "bastion", Address: "203.0.113.10", SSHUser: "admin",
+			Vars: map[string]string{"ntp_server": "pool.ntp.org"}},
+		{Host: "web1", Group: "web", Address: "10.0.0.5", SSHUser: "ubuntu", PythonInterpreter: "/usr/bin/python3",
+			Vars: map[string]string{"role": "frontend", "ntp_server": "pool.ntp.org", "env": "production"}},
+		{Host: "web2", Group: "web", SSHPort: "2222", SSHUser: "ubuntu", PythonInterpreter: "/usr/bin/python3",
+			Vars: map[string]string{"motd": "hello world", "ntp_server": "pool.ntp.org", "env": "production", "role": "generic"}},
+		{Host: "db1", Group: "db", Become: true, SSHUser: "ubuntu",
+			Vars: map[string]string{"tier": "primary", "ntp_server": "pool.ntp.org", "env": "production", "role": "generic"}},
+	}
+	if !reflect.DeepEqual(inv.Hosts, expected) {
+		t.Errorf("Expected hosts:\n%+v\ngot:\n%+v", expected, inv.Hosts)
+	}
+	if !reflect.DeepEqual(inv.Groups, []string{"web", "db", "prod"}) {
+		t.Errorf("Expected groups [web db prod], got %v", inv.Groups)
+	}
+	if !reflect.DeepEqual(inv.Children, map[string][]string{"prod": {"web", "db"}}) {
+		t.Errorf("Expected prod to contain web and db, got %v", inv.Children)
+	}
+}
+
+// ✅ Test that malformed INI inventories are rejected with the line number
+func TestParseINIInventory_Invalid(t *testing.T) {
+	for _, content := range []string{
+		"[web\nweb1\n",
+		"[web:hosts]\n",
+		"[web:vars]\nno_equals\n",
+		"web1 stray\n",
+	} {
+		if _, err := ParseINIInventory([]byte(content)); err == nil || !strings.Contains(err.Error(), "line ") {
+			t.Errorf("Expected a line-numbered error for %q, got %v", content, err)
+		}
+	}
+}
+
+// ✅ Test that INI files load through LoadINIInventory and LoadInventory but
+// aren't rewritten as YAML
+func TestLoadINIInventory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte(sampleINI), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := LoadINIInventory(path)
+	if err != nil || len(hosts) != 4 {
+		t.Fatalf("Expected 4 hosts, got %+v, %v", hosts, err)
+	}
+	inv, err := LoadInventory(path)
+	if err != nil || inv.Format != FormatINI || !reflect.DeepEqual(inv.Hosts, hosts) {
+		t.Errorf("Expected LoadInventory to detect and parse INI, got %+v, %v", inv, err)
+	}
+	if err := AppendHostsToInventory(path, []HostConfig{{Host: "web3"}}); err == nil {
+		t.Error("Expected appending to an INI inventory to be refused")
+	}
+}
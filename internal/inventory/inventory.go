@@ -1,88 +1,236 @@
 package inventory
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // ✅ HostConfig stores per-host settings
 type HostConfig struct {
 	Host       string
+	Address    string // ansible_host, when the connection address differs from the name
 	Group      string
+	Groups     []string // Further groups the host belongs to, see GroupNames
 	SSHUser    string
 	SSHKeyFile string
 	SSHPort    string
 	Become     bool
+	Connection string // ssh (default), local, docker or winrm
+
+	// ✅ Per-host escalation, only written when Become is set
+	BecomeUser   string
+	BecomeMethod string
+
+	PythonInterpreter string // ansible_python_interpreter, ansible discovers one when empty
+
+	// ✅ SSH hosts: a bastion to connect through as [user@]host[:port], and any
+	// further ssh options; both are written to ansible_ssh_common_args
+	ProxyJump     string
+	SSHCommonArgs string
+
+	// ✅ Windows hosts: ansible_winrm_transport and ansible_password, which may
+	// be a reference to a vaulted variable rather than the password itself
+	WinRMTransport string
+	Password       string
+
+	// ✅ Further host variables, always written as strings, sorted by name
+	Vars map[string]string
+}
+
+// ✅ Supported values for HostConfig.Connection
+const (
+	ConnectionSSH    = "ssh"
+	ConnectionLocal  = "local"
+	ConnectionDocker = "docker"
+	ConnectionWinRM  = "winrm"
+)
+
+// ✅ Every group the host belongs to: Group first, then Groups, without
+// duplicates or empty names
+func (h HostConfig) GroupNames() []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range append([]string{h.Group}, h.Groups...) {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// ✅ Defaults for hosts managed over WinRM: HTTPS on its standard port, NTLM
+// for local accounts, and the password read from a vaulted variable
+const (
+	DefaultWinRMPort      = "5986"
+	DefaultWinRMTransport = "ntlm"
+	DefaultWinRMPassword  = "{{ vault_winrm_password }}"
+)
+
+// ✅ Turn a host into a Windows host managed over WinRM
+// SSH-only settings, such as a template's key or python interpreter, are dropped
+func (h HostConfig) Windows() HostConfig {
+	h.Connection = ConnectionWinRM
+	h.SSHKeyFile = ""
+	h.PythonInterpreter = ""
+	h.WinRMTransport = DefaultWinRMTransport
+	h.SSHPort = DefaultWinRMPort
+	h.Password = DefaultWinRMPassword
+	return h
+}
+
+// ✅ Prefix of the ssh option naming a jump host
+const proxyJumpOption = "-o ProxyJump="
+
+// ✅ The host's ansible_ssh_common_args: the jump host first, then any
+// other arguments
+func (h HostConfig) SSHArgs() string {
+	if h.ProxyJump == "" {
+		return h.SSHCommonArgs
+	}
+	return strings.TrimSpace(proxyJumpOption + h.ProxyJump + " " + h.SSHCommonArgs)
+}
+
+// ✅ Report whether the host is reached over SSH (the default connection)
+func (h HostConfig) UsesSSH() bool {
+	return h.Connection == "" || h.Connection == ConnectionSSH
 }
 
+// ✅ Name used for generated inventories; numbered variants avoid clobbering
+const DefaultInventoryFilename = "inv.yml"
+
 // ✅ Define an overridable `execCommand` function for testing
 var execCommand = exec.Command
 
 // ✅ Function to create an inventory file with per-host settings
+// `~` in SSH key paths is expanded; use RenderInventory and WriteInventoryFile
+// to keep paths literal
 func CreateInventoryFile(directory string, hosts []HostConfig) (string, error) {
-	// Ensure directory exists
-	if err := os.MkdirAll(directory, 0o755); err != nil {
-		return "", fmt.Errorf("error creating directory: %w", err)
+	content, err := RenderInventory(ExpandHostPaths(hosts))
+	if err != nil {
+		return "", err
 	}
+	return WriteInventoryFile(directory, content)
+}
 
-	// ✅ Generate unique inventory filename
-	inventoryFile := getUniqueInventoryFilename(directory)
+// ✅ Expand `~` to the user's home directory in a path
+// `~user` paths and paths without a leading `~` are returned unchanged, as is
+// everything when the home directory is unknown
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
 
-	// Write inventory content
+// ✅ Return a copy of hosts with `~` expanded in their SSH key paths
+// Ansible doesn't expand `~` in every context, so key auth can fail otherwise
+func ExpandHostPaths(hosts []HostConfig) []HostConfig {
+	expanded := make([]HostConfig, len(hosts))
+	for i, host := range hosts {
+		host.SSHKeyFile = ExpandHome(host.SSHKeyFile)
+		expanded[i] = host
+	}
+	return expanded
+}
+
+// ✅ Render the YAML inventory for the given hosts without touching disk
+// Ordering contract: ungrouped hosts and the hosts of each group keep their
+// order in hosts, groups are sorted by name. The same input always renders the
+// same output, so generated inventories diff cleanly.
+// A host in several groups is listed under each of them; its variables are
+// written under the first group only, since ansible merges them per host.
+func RenderInventory(hosts []HostConfig) (string, error) {
+	return renderInventory(hosts, nil)
+}
+
+// ✅ Render the inventory, also listing emptyGroups that no host is in
+func renderInventory(hosts []HostConfig, emptyGroups []string) (string, error) {
 	var inventoryContent strings.Builder
 	inventoryContent.WriteString("---\nall:\n  hosts:\n")
 
-	// ✅ Collect ungrouped hosts
+	// ✅ Collect ungrouped hosts; appending keeps the input order
 	ungroupedHosts := []HostConfig{}
 	groups := map[string][]HostConfig{}
 
 	for _, host := range hosts {
-		if host.Group == "" {
+		if strings.TrimSpace(host.Host) == "" {
+			return "", fmt.Errorf("host name is required")
+		}
+		names := host.GroupNames()
+		if len(names) == 0 {
 			ungroupedHosts = append(ungroupedHosts, host)
-		} else {
-			groups[host.Group] = append(groups[host.Group], host)
+			continue
+		}
+		groups[names[0]] = append(groups[names[0]], host)
+		for _, name := range names[1:] {
+			groups[name] = append(groups[name], HostConfig{Host: host.Host})
+		}
+	}
+	for _, name := range emptyGroups {
+		if _, ok := groups[name]; !ok {
+			groups[name] = nil
 		}
 	}
 
 	// ✅ Write ungrouped hosts under `all: hosts`
 	for _, host := range ungroupedHosts {
 		inventoryContent.WriteString(fmt.Sprintf("    %s:\n", host.Host))
-		inventoryContent.WriteString(fmt.Sprintf("      ansible_user: %s\n", host.SSHUser))
-		inventoryContent.WriteString(fmt.Sprintf("      ansible_ssh_private_key_file: %s\n", host.SSHKeyFile))
-		if host.SSHPort != "" {
-			inventoryContent.WriteString(fmt.Sprintf("      ansible_port: %s\n", host.SSHPort))
-		}
-		if host.Become {
-			inventoryContent.WriteString("      ansible_become: true\n")
-		}
+		writeHostVars(&inventoryContent, host, "      ")
 	}
 
 	// ✅ Write grouped hosts under `children:` (fixed recursive children issue)
+	// Groups are sorted by name so the output is stable across runs
 	if len(groups) > 0 {
+		groupNames := make([]string, 0, len(groups))
+		for groupName := range groups {
+			groupNames = append(groupNames, groupName)
+		}
+		sort.Strings(groupNames)
+
 		inventoryContent.WriteString("\n  children:\n")
-		for groupName, groupHosts := range groups {
+		for _, groupName := range groupNames {
+			groupHosts := groups[groupName]
+			if len(groupHosts) == 0 {
+				inventoryContent.WriteString(fmt.Sprintf("    %s: {}\n", groupName))
+				continue
+			}
 			inventoryContent.WriteString(fmt.Sprintf("    %s:\n      hosts:\n", groupName))
 			for _, host := range groupHosts {
 				inventoryContent.WriteString(fmt.Sprintf("        %s:\n", host.Host))
-				inventoryContent.WriteString(fmt.Sprintf("          ansible_user: %s\n", host.SSHUser))
-				inventoryContent.WriteString(fmt.Sprintf("          ansible_ssh_private_key_file: %s\n", host.SSHKeyFile))
-				if host.SSHPort != "" {
-					inventoryContent.WriteString(fmt.Sprintf("          ansible_port: %s\n", host.SSHPort))
-				}
-				if host.Become {
-					inventoryContent.WriteString("          ansible_become: true\n")
-				}
+				writeHostVars(&inventoryContent, host, "          ")
 			}
 		}
 	}
 
+	return inventoryContent.String(), nil
+}
+
+// ✅ Save rendered inventory content under a unique filename in directory
+func WriteInventoryFile(directory string, content string) (string, error) {
+	// Ensure directory exists
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return "", fmt.Errorf("error creating directory: %w", err)
+	}
+
+	// ✅ Generate unique inventory filename
+	inventoryFile := getUniqueInventoryFilename(directory)
+
 	// ✅ Save inventory file
-	err := os.WriteFile(inventoryFile, []byte(inventoryContent.String()), 0o644)
+	err := os.WriteFile(inventoryFile, []byte(content), 0o644)
 	if err != nil {
 		return "", fmt.Errorf("error writing inventory file: %w", err)
 	}
@@ -90,10 +238,125 @@ func CreateInventoryFile(directory string, hosts []HostConfig) (string, error) {
 	return inventoryFile, nil
 }
 
+// ✅ Save rendered inventory content to path, replacing any existing file
+// With backup set, an existing file is first copied to `<path>.bak`, or to a
+// timestamped `.bak` when that name is already taken
+func OverwriteInventoryFile(path string, content string, backup bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+
+	if backup {
+		if _, err := BackupInventoryFile(path); err != nil {
+			return err
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("error writing inventory file: %w", err)
+	}
+	return nil
+}
+
+// ✅ Copy an existing inventory file aside, returning the backup path
+// Returns an empty path when there's nothing to back up
+func BackupInventoryFile(path string) (string, error) {
+	original, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("error reading inventory file for backup: %w", err)
+	}
+
+	backupFile := path + ".bak"
+	if fileExists(backupFile) {
+		backupFile = fmt.Sprintf("%s.%s.bak", path, time.Now().Format("20060102-150405"))
+	}
+	if err := os.WriteFile(backupFile, original, 0o644); err != nil {
+		return "", fmt.Errorf("error writing inventory backup: %w", err)
+	}
+	return backupFile, nil
+}
+
+// ✅ Write the per-host variables, indented to sit under the host key
+// String settings go through YAMLString so they stay strings; the port is
+// written as a number
+func writeHostVars(b *strings.Builder, host HostConfig, indent string) {
+	if host.Address != "" && host.Address != host.Host {
+		b.WriteString(fmt.Sprintf("%sansible_host: %s\n", indent, YAMLString(host.Address)))
+	}
+	if host.Connection != "" {
+		b.WriteString(fmt.Sprintf("%sansible_connection: %s\n", indent, YAMLString(host.Connection)))
+	}
+	if host.WinRMTransport != "" {
+		b.WriteString(fmt.Sprintf("%sansible_winrm_transport: %s\n", indent, YAMLString(host.WinRMTransport)))
+	}
+	if host.SSHUser != "" {
+		b.WriteString(fmt.Sprintf("%sansible_user: %s\n", indent, YAMLString(host.SSHUser)))
+	}
+	if host.Password != "" {
+		b.WriteString(fmt.Sprintf("%sansible_password: %s\n", indent, YAMLString(host.Password)))
+	}
+	// SSH keys mean nothing to docker/local connections
+	if host.SSHKeyFile != "" && host.UsesSSH() {
+		b.WriteString(fmt.Sprintf("%sansible_ssh_private_key_file: %s\n", indent, YAMLString(host.SSHKeyFile)))
+	}
+	if host.SSHPort != "" {
+		b.WriteString(fmt.Sprintf("%sansible_port: %s\n", indent, host.SSHPort))
+	}
+	// Always quoted, as a leading - would otherwise read like a list item
+	if args := host.SSHArgs(); args != "" && host.UsesSSH() {
+		b.WriteString(fmt.Sprintf("%sansible_ssh_common_args: %s\n", indent, yamlQuoted(args)))
+	}
+	if host.PythonInterpreter != "" {
+		b.WriteString(fmt.Sprintf("%sansible_python_interpreter: %s\n", indent, YAMLString(host.PythonInterpreter)))
+	}
+	if host.Become {
+		b.WriteString(fmt.Sprintf("%sansible_become: true\n", indent))
+		if host.BecomeUser != "" {
+			b.WriteString(fmt.Sprintf("%sansible_become_user: %s\n", indent, YAMLString(host.BecomeUser)))
+		}
+		if host.BecomeMethod != "" {
+			b.WriteString(fmt.Sprintf("%sansible_become_method: %s\n", indent, YAMLString(host.BecomeMethod)))
+		}
+	}
+
+	names := make([]string, 0, len(host.Vars))
+	for name := range host.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString(fmt.Sprintf("%s%s: %s\n", indent, YAMLString(name), YAMLString(host.Vars[name])))
+	}
+}
+
+// ✅ Render a value as a single-quoted YAML scalar
+func yamlQuoted(value string) string {
+	if strings.ContainsAny(value, "\r\n") {
+		return strconv.Quote(value)
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// ✅ Render a value as a YAML scalar that ansible reads back as the same string
+// Values its YAML 1.1 parser would turn into booleans, numbers or null (yes,
+// on, 123, null, ...) are quoted, as is anything containing YAML syntax
+func YAMLString(value string) string {
+	if strings.ContainsAny(value, "\r\n") {
+		return strconv.Quote(value) // Instead of a block scalar
+	}
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return strconv.Quote(value)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
 // ✅ Function to generate a unique filename if `inventory.yml` exists
 func getUniqueInventoryFilename(directory string) string {
-	baseName := "inv"
-	ext := ".yml"
+	ext := filepath.Ext(DefaultInventoryFilename)
+	baseName := strings.TrimSuffix(DefaultInventoryFilename, ext)
 	filename := filepath.Join(directory, baseName+ext)
 
 	// ✅ Check if file exists and increment name if necessary
@@ -111,62 +374,3 @@ func fileExists(filename string) bool {
 	_, err := os.Stat(filename)
 	return !os.IsNotExist(err)
 }
-
-// ✅ Auto-discover Multipass/Docker instances
-func DiscoverInstances(reader *bufio.Reader) []string {
-	var instances []string
-
-	// ✅ Detect Multipass instances
-	fmt.Println("\n🔍 Checking for running Multipass instances...")
-	out, err := exec.Command("multipass", "list", "--format", "csv").Output()
-	if err == nil {
-		lines := strings.Split(string(out), "\n")
-		for _, line := range lines[1:] { // Skip header row
-			fields := strings.Split(line, ",")
-			if len(fields) > 2 && strings.TrimSpace(fields[1]) == "Running" {
-				instances = append(instances, strings.TrimSpace(fields[2])) // Extract IP
-			}
-		}
-	}
-
-	// ✅ Detect Docker containers
-	fmt.Println("\n🐳 Checking for running Docker containers...")
-	out, err = exec.Command("docker", "ps", "--format", "{{.Names}}").Output()
-	if err == nil {
-		lines := strings.Split(string(out), "\n")
-		for _, line := range lines {
-			if len(line) > 0 {
-				instances = append(instances, strings.TrimSpace(line)) // Use container name
-			}
-		}
-	}
-
-	// ✅ Prompt user to select instances
-	if len(instances) > 0 {
-		fmt.Println("\n🔍 Found the following instances:")
-		for i, instance := range instances {
-			fmt.Printf("[%d] %s\n", i+1, instance)
-		}
-		fmt.Println("\nSelect instances to add (space-separated numbers, or type 'all' for all):")
-		fmt.Print("> ")
-
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(input)
-
-		if input == "all" {
-			return instances
-		}
-
-		selectedInstances := []string{}
-		indices := strings.Fields(input)
-		for _, index := range indices {
-			if i, err := strconv.Atoi(index); err == nil && i > 0 && i <= len(instances) {
-				selectedInstances = append(selectedInstances, instances[i-1])
-			}
-		}
-		return selectedInstances
-	}
-
-	fmt.Println("⚠️ No running Multipass or Docker instances found.")
-	return []string{}
-}
@@ -1,9 +1,10 @@
 package inventory
 
 import (
-	"bufio"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -22,6 +23,11 @@ func TestHelperProcess(t *testing.T) {
 	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
 		return
 	}
+	// ✅ Canned output can be overridden per command, e.g. MOCK_DOCKER_OUTPUT
+	if output, ok := os.LookupEnv("MOCK_" + strings.ToUpper(filepath.Base(os.Args[3])) + "_OUTPUT"); ok {
+		os.Stdout.Write([]byte(output))
+		os.Exit(0)
+	}
 	switch os.Args[3] {
 	case "multipass":
 		os.Stdout.Write([]byte("Name,State,IPv4\ninstance1,Running,10.0.0.5\ninstance2,Running,10.0.0.6\n"))
@@ -31,31 +37,412 @@ func TestHelperProcess(t *testing.T) {
 	os.Exit(0)
 }
 
-// ✅ Test `DiscoverInstances`
-func TestDiscoverInstances(t *testing.T) {
-	// ✅ Override `execCommand`
-	oldExecCommand := execCommand
-	execCommand = mockExecCommand
-	defer func() { execCommand = oldExecCommand }()
+// ✅ Test that the connection type is written to the inventory
+func TestCreateInventoryFile_Connection(t *testing.T) {
+	dir := t.TempDir()
+	hosts := []HostConfig{
+		{Host: "container1", Connection: ConnectionDocker},
+		{Host: "winhost", Group: "windows", SSHUser: "admin", Connection: ConnectionWinRM},
+	}
+
+	inventoryFile, err := CreateInventoryFile(dir, hosts)
+	if err != nil {
+		t.Fatalf("CreateInventoryFile returned error: %v", err)
+	}
+	data, err := os.ReadFile(inventoryFile)
+	if err != nil {
+		t.Fatalf("Could not read inventory file: %v", err)
+	}
+	content := string(data)
+
+	for _, expected := range []string{
+		"    container1:\n      ansible_connection: docker\n",
+		"        winhost:\n          ansible_connection: winrm\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected inventory to contain %q, got:\n%s", expected, content)
+		}
+	}
+}
+
+// ✅ Test that per-host become user and method are only written with become
+func TestRenderInventory_BecomeUserAndMethod(t *testing.T) {
+	hosts := []HostConfig{
+		{Host: "app1", Become: true, BecomeUser: "deploy", BecomeMethod: "doas"},
+		{Host: "app2", BecomeUser: "deploy", BecomeMethod: "doas"},
+	}
+
+	content, err := RenderInventory(hosts)
+	if err != nil {
+		t.Fatalf("RenderInventory returned error: %v", err)
+	}
+
+	expected := "    app1:\n      ansible_become: true\n      ansible_become_user: deploy\n      ansible_become_method: doas\n" +
+		"    app2:\n"
+	if !strings.Contains(content, expected) {
+		t.Errorf("Expected inventory to contain %q, got:\n%s", expected, content)
+	}
+	if strings.Count(content, "ansible_become_user") != 1 {
+		t.Errorf("Expected become settings only for app1, got:\n%s", content)
+	}
+}
+
+// ✅ Test that values YAML would read as another type are quoted
+func TestYAMLString(t *testing.T) {
+	for value, expected := range map[string]string{
+		"yes":              `"yes"`,
+		"on":               `"on"`,
+		"123":              `"123"`,
+		"null":             `"null"`,
+		"":                 `""`,
+		"ubuntu":           "ubuntu",
+		"/usr/bin/python3": "/usr/bin/python3",
+		"a: b":             "'a: b'",
+		"two\nlines":       `"two\nlines"`,
+	} {
+		if got := YAMLString(value); got != expected {
+			t.Errorf("YAMLString(%q) = %s, expected %s", value, got, expected)
+		}
+	}
+}
+
+// ✅ Test that host vars are written sorted and quoted where needed
+func TestRenderInventory_Vars(t *testing.T) {
+	content, err := RenderInventory([]HostConfig{
+		{Host: "web1", SSHUser: "yes", Vars: map[string]string{"release": "123", "enabled": "on", "role": "nginx"}},
+	})
+	if err != nil {
+		t.Fatalf("RenderInventory returned error: %v", err)
+	}
+
+	expected := "    web1:\n      ansible_user: \"yes\"\n      enabled: \"on\"\n      release: \"123\"\n      role: nginx\n"
+	if !strings.Contains(content, expected) {
+		t.Errorf("Expected inventory to contain %q, got:\n%s", expected, content)
+	}
+}
+
+// ✅ Test that a jump host and other ssh options become one quoted common args line
+func TestRenderInventory_ProxyJump(t *testing.T) {
+	hosts := []HostConfig{
+		{Host: "db1", SSHUser: "admin", ProxyJump: "ops@bastion:2222", SSHCommonArgs: "-o StrictHostKeyChecking=no"},
+		{Host: "web1", ProxyJump: "bastion"},
+		{Host: "app", Connection: ConnectionDocker, ProxyJump: "bastion"},
+	}
+	content, err := RenderInventory(hosts)
+	if err != nil {
+		t.Fatalf("RenderInventory returned error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"    db1:\n      ansible_user: admin\n      ansible_ssh_common_args: '-o ProxyJump=ops@bastion:2222 -o StrictHostKeyChecking=no'\n",
+		"    web1:\n      ansible_ssh_common_args: '-o ProxyJump=bastion'\n",
+		"    app:\n      ansible_connection: docker\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected inventory to contain %q, got:\n%s", expected, content)
+		}
+	}
+	if strings.Count(content, "ansible_ssh_common_args") != 2 {
+		t.Errorf("Expected no ssh args for the docker host, got:\n%s", content)
+	}
+
+	inv, err := ParseInventory([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseInventory returned error: %v", err)
+	}
+	if !reflect.DeepEqual(inv.Hosts[:2], hosts[:2]) {
+		t.Errorf("Expected %+v to load back, got %+v", hosts[:2], inv.Hosts[:2])
+	}
+}
+
+// ✅ Test that a Windows host gets the WinRM vars and none of the SSH ones
+func TestRenderInventory_Windows(t *testing.T) {
+	template := HostConfig{Host: "win1", SSHUser: "Administrator", SSHKeyFile: "~/.ssh/id_rsa", PythonInterpreter: "/usr/bin/python3"}
+	host := template.Windows()
+	content, err := RenderInventory([]HostConfig{host})
+	if err != nil {
+		t.Fatalf("RenderInventory returned error: %v", err)
+	}
+
+	expected := "    win1:\n" +
+		"      ansible_connection: winrm\n" +
+		"      ansible_winrm_transport: ntlm\n" +
+		"      ansible_user: Administrator\n" +
+		"      ansible_password: '{{ vault_winrm_password }}'\n" +
+		"      ansible_port: 5986\n"
+	if !strings.Contains(content, expected) {
+		t.Errorf("Expected inventory to contain %q, got:\n%s", expected, content)
+	}
+	if strings.Contains(content, "ansible_ssh_private_key_file") || strings.Contains(content, "ansible_python_interpreter") {
+		t.Errorf("Expected no SSH settings for a Windows host, got:\n%s", content)
+	}
+
+	inv, err := ParseInventory([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseInventory returned error: %v", err)
+	}
+	if len(inv.Hosts) != 1 || !reflect.DeepEqual(inv.Hosts[0], host) {
+		t.Errorf("Expected %+v to load back, got %+v", host, inv.Hosts)
+	}
+}
+
+// ✅ Test that a host in several groups is listed under each, with its vars once
+func TestRenderInventory_MultipleGroups(t *testing.T) {
+	hosts := []HostConfig{
+		{Host: "web1", Group: "web", Groups: []string{"prod", "web"}, SSHUser: "ubuntu"},
+		{Host: "db1", Group: "prod", SSHUser: "root"},
+	}
+
+	content, err := RenderInventory(hosts)
+	if err != nil {
+		t.Fatalf("RenderInventory returned error: %v", err)
+	}
+
+	expected := "---\nall:\n  hosts:\n" +
+		"\n  children:\n" +
+		"    prod:\n      hosts:\n" +
+		"        web1:\n" +
+		"        db1:\n          ansible_user: root\n" +
+		"    web:\n      hosts:\n" +
+		"        web1:\n          ansible_user: ubuntu\n"
+	if content != expected {
+		t.Errorf("Expected inventory:\n%s\ngot:\n%s", expected, content)
+	}
+}
+
+// ✅ Test rendering inventory content without writing a file
+func TestRenderInventory(t *testing.T) {
+	hosts := []HostConfig{
+		{Host: "10.0.0.5", SSHUser: "ubuntu", SSHKeyFile: "~/.ssh/id_rsa", SSHPort: "2222", Become: true},
+		{Host: "db1", Group: "db", SSHUser: "root", SSHKeyFile: "~/.ssh/db"},
+	}
+
+	content, err := RenderInventory(hosts)
+	if err != nil {
+		t.Fatalf("RenderInventory returned error: %v", err)
+	}
+
+	expected := "---\nall:\n  hosts:\n" +
+		"    10.0.0.5:\n" +
+		"      ansible_user: ubuntu\n" +
+		"      ansible_ssh_private_key_file: ~/.ssh/id_rsa\n" +
+		"      ansible_port: 2222\n" +
+		"      ansible_become: true\n" +
+		"\n  children:\n" +
+		"    db:\n      hosts:\n" +
+		"        db1:\n" +
+		"          ansible_user: root\n" +
+		"          ansible_ssh_private_key_file: ~/.ssh/db\n"
+	if content != expected {
+		t.Errorf("Expected inventory:\n%s\ngot:\n%s", expected, content)
+	}
+}
+
+// ✅ Test that hosts without a name are rejected
+func TestRenderInventory_EmptyHost(t *testing.T) {
+	if _, err := RenderInventory([]HostConfig{{Host: " "}}); err == nil {
+		t.Error("Expected an error for a host without a name")
+	}
+}
+
+// ✅ Test that overwriting with backup keeps the original content
+func TestOverwriteInventoryFile_Backup(t *testing.T) {
+	dir := t.TempDir()
+	original, err := CreateInventoryFile(dir, []HostConfig{{Host: "old-host", SSHUser: "ubuntu"}})
+	if err != nil {
+		t.Fatalf("CreateInventoryFile returned error: %v", err)
+	}
+	originalContent, _ := os.ReadFile(original)
+
+	content, err := RenderInventory([]HostConfig{{Host: "new-host", SSHUser: "ubuntu"}})
+	if err != nil {
+		t.Fatalf("RenderInventory returned error: %v", err)
+	}
+	if err := OverwriteInventoryFile(original, content, true); err != nil {
+		t.Fatalf("OverwriteInventoryFile returned error: %v", err)
+	}
+
+	backup, err := os.ReadFile(original + ".bak")
+	if err != nil {
+		t.Fatalf("Expected a backup file: %v", err)
+	}
+	if string(backup) != string(originalContent) {
+		t.Errorf("Expected backup to contain the original inventory, got:\n%s", backup)
+	}
+	current, _ := os.ReadFile(original)
+	if !strings.Contains(string(current), "new-host") {
+		t.Errorf("Expected the inventory to be overwritten, got:\n%s", current)
+	}
+
+	// ✅ A second overwrite keeps the first backup and adds a timestamped one
+	if err := OverwriteInventoryFile(original, content, true); err != nil {
+		t.Fatalf("OverwriteInventoryFile returned error: %v", err)
+	}
+	matches, _ := filepath.Glob(original + ".*.bak")
+	if len(matches) != 1 {
+		t.Errorf("Expected one timestamped backup, got %v", matches)
+	}
+}
+
+// ✅ Test that overwriting without backup leaves no backup file
+func TestOverwriteInventoryFile_NoBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultInventoryFilename)
+	if err := os.WriteFile(path, []byte("---\n"), 0o644); err != nil {
+		t.Fatalf("Could not write inventory: %v", err)
+	}
+	if err := OverwriteInventoryFile(path, "---\nall:\n", false); err != nil {
+		t.Fatalf("OverwriteInventoryFile returned error: %v", err)
+	}
+	if fileExists(path + ".bak") {
+		t.Error("Expected no backup file without opt-in")
+	}
+}
+
+// ✅ Test that docker hosts get a connection block without SSH settings
+func TestRenderInventory_DockerVsMultipass(t *testing.T) {
+	multipass := Instance{Name: "vm1", Source: SourceMultipass, State: "Running", Address: "10.0.0.5"}.HostConfig()
+	multipass.SSHUser = "ubuntu"
+	multipass.SSHKeyFile = "~/.ssh/id_rsa"
+	docker := Instance{Name: "container1", Source: SourceDocker, State: "running", ConnectionType: ConnectionDocker}.HostConfig()
+	docker.SSHKeyFile = "~/.ssh/id_rsa" // Ignored for non-SSH connections
+
+	content, err := RenderInventory([]HostConfig{multipass, docker})
+	if err != nil {
+		t.Fatalf("RenderInventory returned error: %v", err)
+	}
+
+	expected := "---\nall:\n  hosts:\n" +
+		"    10.0.0.5:\n" +
+		"      ansible_user: ubuntu\n" +
+		"      ansible_ssh_private_key_file: ~/.ssh/id_rsa\n" +
+		"    container1:\n" +
+		"      ansible_connection: docker\n"
+	if content != expected {
+		t.Errorf("Expected inventory:\n%s\ngot:\n%s", expected, content)
+	}
+}
+
+// ✅ Test tilde expansion in SSH key paths
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	for _, tc := range []struct {
+		path     string
+		expected string
+	}{
+		{"~/.ssh/id_rsa", filepath.Join(home, ".ssh", "id_rsa")},
+		{"~", home},
+		{"/etc/ssh/deploy_key", "/etc/ssh/deploy_key"},
+		{"~deploy/.ssh/id_rsa", "~deploy/.ssh/id_rsa"},
+		{"", ""},
+	} {
+		if got := ExpandHome(tc.path); got != tc.expected {
+			t.Errorf("ExpandHome(%q) = %q, expected %q", tc.path, got, tc.expected)
+		}
+	}
+}
 
-	// ✅ Simulate user input selecting "all"
-	reader := bufio.NewReader(strings.NewReader("all\n"))
+// ✅ Test that CreateInventoryFile writes expanded key paths
+func TestCreateInventoryFile_ExpandsKeyPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
 
-	// ✅ Pass the reader to `DiscoverInstances`
-	instances := DiscoverInstances(reader)
+	hosts := []HostConfig{
+		{Host: "10.0.0.5", SSHKeyFile: "~/.ssh/id_rsa"},
+		{Host: "10.0.0.6", SSHKeyFile: "/etc/ssh/deploy_key"},
+	}
+	inventoryFile, err := CreateInventoryFile(t.TempDir(), hosts)
+	if err != nil {
+		t.Fatalf("CreateInventoryFile returned error: %v", err)
+	}
+	data, _ := os.ReadFile(inventoryFile)
 
-	// ✅ Check that instances match expected mock data
-	expectedInstances := []string{"10.0.0.5", "10.0.0.6", "container1", "container2"}
-	for _, expected := range expectedInstances {
-		found := false
-		for _, instance := range instances {
-			if instance == expected {
-				found = true
-				break
-			}
+	for _, expected := range []string{
+		"ansible_ssh_private_key_file: " + filepath.Join(home, ".ssh", "id_rsa") + "\n",
+		"ansible_ssh_private_key_file: /etc/ssh/deploy_key\n",
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("Expected inventory to contain %q, got:\n%s", expected, data)
 		}
-		if !found {
-			t.Errorf("Expected instance %s not found in discovered instances: %v", expected, instances)
+	}
+	if hosts[0].SSHKeyFile != "~/.ssh/id_rsa" {
+		t.Error("Expected the caller's hosts to be left unchanged")
+	}
+}
+
+// ✅ Test that a separate connection address is written as ansible_host
+func TestRenderInventory_Address(t *testing.T) {
+	content, err := RenderInventory([]HostConfig{
+		{Host: "web1", Address: "10.0.0.5", SSHUser: "ubuntu"},
+		{Host: "10.0.0.6", Address: "10.0.0.6"},
+	})
+	if err != nil {
+		t.Fatalf("RenderInventory returned error: %v", err)
+	}
+
+	expected := "---\nall:\n  hosts:\n" +
+		"    web1:\n" +
+		"      ansible_host: 10.0.0.5\n" +
+		"      ansible_user: ubuntu\n" +
+		"    10.0.0.6:\n"
+	if content != expected {
+		t.Errorf("Expected inventory:\n%s\ngot:\n%s", expected, content)
+	}
+}
+
+// ✅ Test that groups are written in sorted order
+func TestRenderInventory_SortedGroups(t *testing.T) {
+	hosts := []HostConfig{
+		{Host: "web1", Group: "web"},
+		{Host: "db1", Group: "db"},
+		{Host: "cache1", Group: "cache"},
+		{Host: "app1", Group: "app"},
+	}
+
+	// Map iteration order varies, so render a few times
+	for i := 0; i < 5; i++ {
+		content, err := RenderInventory(hosts)
+		if err != nil {
+			t.Fatalf("RenderInventory returned error: %v", err)
+		}
+		inv, err := ParseInventory([]byte(content))
+		if err != nil {
+			t.Fatalf("ParseInventory returned error: %v", err)
 		}
+		expected := []string{"app", "cache", "db", "web"}
+		if !reflect.DeepEqual(inv.Groups, expected) {
+			t.Fatalf("Expected groups %v, got %v", expected, inv.Groups)
+		}
+	}
+}
+
+// ✅ Test that hosts keep their input order, also within groups
+func TestRenderInventory_HostOrder(t *testing.T) {
+	hosts := []HostConfig{
+		{Host: "zeta"},
+		{Host: "web3", Group: "web"},
+		{Host: "alpha"},
+		{Host: "web1", Group: "web"},
+		{Host: "mid"},
+		{Host: "web2", Group: "web"},
+	}
+
+	content, err := RenderInventory(hosts)
+	if err != nil {
+		t.Fatalf("RenderInventory returned error: %v", err)
+	}
+	inv, err := ParseInventory([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseInventory returned error: %v", err)
+	}
+
+	var order []string
+	for _, host := range inv.Hosts {
+		order = append(order, host.Host)
+	}
+	expected := []string{"zeta", "alpha", "mid", "web3", "web1", "web2"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected host order %v, got %v", expected, order)
 	}
 }
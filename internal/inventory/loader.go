@@ -0,0 +1,293 @@
+package inventory
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ✅ Inventory is the parsed content of an inventory file
+type Inventory struct {
+	Hosts    []HostConfig        // One entry per host definition, in file order
+	Groups   []string            // Every group defined, in file order, including empty ones
+	Children map[string][]string // Child groups keyed by parent group
+	Format   Format              // Syntax the inventory was parsed from
+}
+
+// ✅ Load an inventory file, detecting its format unless SetFormat chose one
+// Hosts under `all.hosts` are ungrouped; hosts under a group (at any depth of
+// `children`) carry that group's name. Group and `all` vars are applied to the
+// hosts below them unless a host sets them itself. A host defined more than
+// once yields one entry per definition so callers can detect duplicates.
+func LoadInventory(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading inventory file: %w", err)
+	}
+
+	f := format
+	if f == FormatAuto {
+		f = DetectFormat(data)
+	}
+	if f == FormatINI {
+		return ParseINIInventory(data)
+	}
+	return ParseInventory(data)
+}
+
+// ✅ Parse YAML inventory content
+func ParseInventory(data []byte) (*Inventory, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing inventory: %w", err)
+	}
+
+	inv := &Inventory{Children: map[string][]string{}, Format: FormatYAML}
+	vars := newPendingVars()
+	if len(doc.Content) == 0 {
+		return inv, nil // Empty document
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("error parsing inventory: expected a mapping at the top level, got %s", nodeKind(root))
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		name, group := root.Content[i].Value, root.Content[i+1]
+		if name == "all" {
+			if err := inv.parseGroup("", group, vars); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// Groups may also be declared at the top level, outside `all`
+		inv.Groups = append(inv.Groups, name)
+		if err := inv.parseGroup(name, group, vars); err != nil {
+			return nil, err
+		}
+	}
+
+	inv.applyGroupVars(vars)
+	return inv, nil
+}
+
+// ✅ Parse a group's `hosts`, `children` and `vars`; name is empty for `all`
+func (inv *Inventory) parseGroup(name string, node *yaml.Node, vars *pendingVars) error {
+	if isNull(node) {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("error parsing inventory: group %q must be a mapping, got %s", groupLabel(name), nodeKind(node))
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		switch key {
+		case "hosts":
+			if err := inv.parseHosts(name, value, vars); err != nil {
+				return err
+			}
+		case "vars":
+			if err := vars.parseGroupVars(name, value); err != nil {
+				return err
+			}
+		case "children":
+			if isNull(value) {
+				continue
+			}
+			if value.Kind != yaml.MappingNode {
+				return fmt.Errorf("error parsing inventory: children of %q must be a mapping, got %s", groupLabel(name), nodeKind(value))
+			}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				child := value.Content[j].Value
+				inv.Groups = append(inv.Groups, child)
+				if name != "" {
+					inv.Children[name] = append(inv.Children[name], child)
+				}
+				if err := inv.parseGroup(child, value.Content[j+1], vars); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ✅ Parse the `hosts` mapping of a group
+func (inv *Inventory) parseHosts(group string, node *yaml.Node, pending *pendingVars) error {
+	if isNull(node) {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("error parsing inventory: hosts of %q must be a mapping, got %s", groupLabel(group), nodeKind(node))
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		host := HostConfig{Host: node.Content[i].Value, Group: group}
+		vars := node.Content[i+1]
+		if !isNull(vars) && vars.Kind != yaml.MappingNode {
+			return fmt.Errorf("error parsing inventory: variables of host %q must be a mapping, got %s", host.Host, nodeKind(vars))
+		}
+		keys := map[string]bool{}
+		for j := 0; j+1 < len(vars.Content); j += 2 {
+			key, value := vars.Content[j].Value, vars.Content[j+1].Value
+			keys[key] = true
+			if host.setConnectionVar(key, value) {
+				continue
+			}
+			if plainString(vars.Content[j+1]) {
+				host.setVar(key, value)
+			}
+		}
+		inv.Hosts = append(inv.Hosts, host)
+		pending.hosts = append(pending.hosts, keys)
+	}
+	return nil
+}
+
+// ✅ Only plain strings that render back unchanged are kept as vars, so
+// rewriting never changes what ansible reads
+func plainString(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!str" &&
+		(node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 || YAMLString(node.Value) == node.Value)
+}
+
+// ✅ Group vars seen while parsing, applied to the hosts once every group is known
+type pendingVars struct {
+	groups map[string][][2]string // In file order, keyed by group ("" for all)
+	hosts  []map[string]bool      // Variables each host definition sets itself
+}
+
+func newPendingVars() *pendingVars {
+	return &pendingVars{groups: map[string][][2]string{}}
+}
+
+// ✅ Collect the `vars` mapping of a group
+// Connection settings HostConfig models and plain string vars are kept; other
+// values, such as numbers and lists, aren't modeled for hosts either.
+func (p *pendingVars) parseGroupVars(group string, node *yaml.Node) error {
+	if isNull(node) {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("error parsing inventory: vars of %q must be a mapping, got %s", groupLabel(group), nodeKind(node))
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		var probe HostConfig
+		if value.Kind == yaml.ScalarNode && !isNull(value) && (probe.setConnectionVar(key, value.Value) || plainString(value)) {
+			p.groups[group] = append(p.groups[group], [2]string{key, value.Value})
+		}
+	}
+	return nil
+}
+
+// ✅ Apply group vars from the widest group in: all, then ancestors, then the
+// host's own group; values the host sets itself always win
+func (inv *Inventory) applyGroupVars(vars *pendingVars) {
+	parents := map[string][]string{}
+	for parent, children := range inv.Children {
+		for _, child := range children {
+			parents[child] = append(parents[child], parent)
+		}
+	}
+	for i := range inv.Hosts {
+		host := &inv.Hosts[i]
+		groups := []string{""}
+		if host.Group != "" {
+			up := ancestors(host.Group, parents)
+			for j := len(up) - 1; j >= 0; j-- {
+				groups = append(groups, up[j])
+			}
+			groups = append(groups, host.Group)
+		}
+		for _, group := range groups {
+			for _, kv := range vars.groups[group] {
+				if vars.hosts[i][kv[0]] {
+					continue
+				}
+				if !host.setConnectionVar(kv[0], kv[1]) {
+					host.setVar(kv[0], kv[1])
+				}
+			}
+		}
+	}
+}
+
+// ✅ Store an ansible_* setting HostConfig models, reporting whether key was one
+func (h *HostConfig) setConnectionVar(key string, value string) bool {
+	switch key {
+	case "ansible_host":
+		h.Address = value
+	case "ansible_user":
+		h.SSHUser = value
+	case "ansible_ssh_private_key_file":
+		h.SSHKeyFile = value
+	case "ansible_port":
+		h.SSHPort = value
+	case "ansible_become":
+		h.Become = value == "true" || value == "yes" || value == "True"
+	case "ansible_become_user":
+		h.BecomeUser = value
+	case "ansible_become_method":
+		h.BecomeMethod = value
+	case "ansible_python_interpreter":
+		h.PythonInterpreter = value
+	case "ansible_connection":
+		h.Connection = value
+	case "ansible_ssh_common_args":
+		h.setSSHArgs(value)
+	case "ansible_winrm_transport":
+		h.WinRMTransport = value
+	case "ansible_password":
+		h.Password = value
+	default:
+		return false
+	}
+	return true
+}
+
+// ✅ Split ansible_ssh_common_args into a leading jump host and the rest
+func (h *HostConfig) setSSHArgs(value string) {
+	if rest, ok := strings.CutPrefix(strings.TrimSpace(value), proxyJumpOption); ok {
+		if jump, others, _ := strings.Cut(rest, " "); jump != "" {
+			h.ProxyJump, h.SSHCommonArgs = jump, strings.TrimSpace(others)
+			return
+		}
+	}
+	h.SSHCommonArgs = value
+}
+
+// ✅ Store any other variable in Vars
+func (h *HostConfig) setVar(key string, value string) {
+	if h.Vars == nil {
+		h.Vars = map[string]string{}
+	}
+	h.Vars[key] = value
+}
+
+// ✅ Helpers for readable parse errors
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+func nodeKind(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.MappingNode:
+		return "a mapping"
+	default:
+		return fmt.Sprintf("%q", node.Value)
+	}
+}
+
+func groupLabel(name string) string {
+	if name == "" {
+		return "all"
+	}
+	return name
+}
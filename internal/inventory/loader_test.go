@@ -0,0 +1,110 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// ✅ Test that a rendered inventory loads back into the same hosts
+func TestLoadInventory_RoundTrip(t *testing.T) {
+	hosts := []HostConfig{
+		{Host: "10.0.0.5", SSHUser: "ubuntu", SSHKeyFile: "~/.ssh/id_rsa", SSHPort: "2222", Become: true},
+		{Host: "container1", Connection: ConnectionDocker},
+		{Host: "web1", Address: "10.0.0.7"},
+		{Host: "app1", Become: true, BecomeUser: "deploy", BecomeMethod: "doas", PythonInterpreter: "/usr/bin/python3"},
+		{Host: "cache1", Vars: map[string]string{"enabled": "yes", "release": "123", "role": "redis"}},
+		{Host: "db1", Group: "db", SSHUser: "root", SSHKeyFile: "~/.ssh/db"},
+	}
+
+	inventoryFile, err := CreateInventoryFile(t.TempDir(), hosts)
+	if err != nil {
+		t.Fatalf("CreateInventoryFile returned error: %v", err)
+	}
+
+	inv, err := LoadInventory(inventoryFile)
+	if err != nil {
+		t.Fatalf("LoadInventory returned error: %v", err)
+	}
+	// Key paths come back expanded
+	if expected := ExpandHostPaths(hosts); !reflect.DeepEqual(inv.Hosts, expected) {
+		t.Errorf("Expected hosts %+v, got %+v", expected, inv.Hosts)
+	}
+	if !reflect.DeepEqual(inv.Groups, []string{"db"}) {
+		t.Errorf("Expected groups [db], got %v", inv.Groups)
+	}
+}
+
+// ✅ Test that group vars reach the hosts from the widest group in, and that
+// values a host sets itself win
+func TestParseInventory_GroupVars(t *testing.T) {
+	inv, err := ParseInventory([]byte("all:\n  vars:\n    ansible_user: deploy\n    tier: default\n    retries: 3\n" +
+		"  children:\n    prod:\n      vars:\n        tier: prod\n      children:\n        web:\n          hosts:\n" +
+		"            web1:\n            web2:\n              ansible_user: admin\n              tier: canary\n"))
+	if err != nil {
+		t.Fatalf("ParseInventory returned error: %v", err)
+	}
+	expected := []HostConfig{
+		{Host: "web1", Group: "web", SSHUser: "deploy", Vars: map[string]string{"tier": "prod"}},
+		{Host: "web2", Group: "web", SSHUser: "admin", Vars: map[string]string{"tier": "canary"}},
+	}
+	if !reflect.DeepEqual(inv.Hosts, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, inv.Hosts)
+	}
+}
+
+// ✅ Test that only host vars that stay strings when rewritten are kept
+func TestParseInventory_Vars(t *testing.T) {
+	inv, err := ParseInventory([]byte("all:\n  hosts:\n    web1:\n" +
+		"      role: nginx\n      quoted: 'on'\n      flag: yes\n      port_offset: 10\n      list: [a, b]\n"))
+	if err != nil {
+		t.Fatalf("ParseInventory returned error: %v", err)
+	}
+	expected := map[string]string{"role": "nginx", "quoted": "on"}
+	if len(inv.Hosts) != 1 || !reflect.DeepEqual(inv.Hosts[0].Vars, expected) {
+		t.Errorf("Expected vars %v, got %+v", expected, inv.Hosts)
+	}
+}
+
+// ✅ Test nested children and top-level groups
+func TestParseInventory_Children(t *testing.T) {
+	inv, err := ParseInventory([]byte(`
+all:
+  children:
+    prod:
+      children:
+        web:
+          hosts:
+            web1:
+standalone:
+  hosts:
+    box1:
+`))
+	if err != nil {
+		t.Fatalf("ParseInventory returned error: %v", err)
+	}
+
+	expectedHosts := []HostConfig{{Host: "web1", Group: "web"}, {Host: "box1", Group: "standalone"}}
+	if !reflect.DeepEqual(inv.Hosts, expectedHosts) {
+		t.Errorf("Expected hosts %+v, got %+v", expectedHosts, inv.Hosts)
+	}
+	if !reflect.DeepEqual(inv.Groups, []string{"prod", "web", "standalone"}) {
+		t.Errorf("Expected groups [prod web standalone], got %v", inv.Groups)
+	}
+	if !reflect.DeepEqual(inv.Children["prod"], []string{"web"}) {
+		t.Errorf("Expected prod to have child web, got %v", inv.Children["prod"])
+	}
+}
+
+// ✅ Test that malformed inventories are reported
+func TestLoadInventory_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inv.yml")
+	if err := os.WriteFile(path, []byte("all:\n  hosts:\n    - web1\n"), 0o644); err != nil {
+		t.Fatalf("Could not write inventory: %v", err)
+	}
+
+	if _, err := LoadInventory(path); err == nil {
+		t.Error("Expected an error for hosts given as a list")
+	}
+}
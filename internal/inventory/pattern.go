@@ -0,0 +1,141 @@
+package inventory
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ✅ MatchHosts returns the hosts an ansible host pattern selects, sorted
+// Supported: `all`/`*`, host and group names (child groups included), globs,
+// `~regex`, unions with `:` or `,`, `!` exclusions and `&` intersections.
+// `@file` patterns can't be resolved from the inventory and are an error.
+func (inv *Inventory) MatchHosts(pattern string) ([]string, error) {
+	selected := map[string]bool{}
+	var intersect, exclude [][]string
+	for _, term := range strings.FieldsFunc(pattern, func(r rune) bool { return r == ',' || r == ':' }) {
+		term = strings.TrimSpace(term)
+		if strings.HasPrefix(term, "@") {
+			return nil, fmt.Errorf("pattern %q reads hosts from a file", term)
+		}
+		switch {
+		case strings.HasPrefix(term, "!"):
+			hosts, err := inv.matchTerm(term[1:])
+			if err != nil {
+				return nil, err
+			}
+			exclude = append(exclude, hosts)
+		case strings.HasPrefix(term, "&"):
+			hosts, err := inv.matchTerm(term[1:])
+			if err != nil {
+				return nil, err
+			}
+			intersect = append(intersect, hosts)
+		default:
+			hosts, err := inv.matchTerm(term)
+			if err != nil {
+				return nil, err
+			}
+			for _, host := range hosts {
+				selected[host] = true
+			}
+		}
+	}
+
+	// Intersections and exclusions apply to the union, as in ansible
+	for _, hosts := range intersect {
+		keep := map[string]bool{}
+		for _, host := range hosts {
+			if selected[host] {
+				keep[host] = true
+			}
+		}
+		selected = keep
+	}
+	for _, hosts := range exclude {
+		for _, host := range hosts {
+			delete(selected, host)
+		}
+	}
+
+	matches := make([]string, 0, len(selected))
+	for host := range selected {
+		matches = append(matches, host)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// ✅ Hosts selected by a single pattern term
+func (inv *Inventory) matchTerm(term string) ([]string, error) {
+	if term == "all" || term == "*" {
+		return inv.hostNames(func(HostConfig) bool { return true }), nil
+	}
+
+	var match func(name string) bool
+	switch {
+	case strings.HasPrefix(term, "~"):
+		re, err := regexp.Compile(term[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid host pattern %q: %w", term, err)
+		}
+		match = re.MatchString
+	case strings.ContainsAny(term, "*?["):
+		if _, err := path.Match(term, ""); err != nil {
+			return nil, fmt.Errorf("invalid host pattern %q: %w", term, err)
+		}
+		match = func(name string) bool {
+			ok, _ := path.Match(term, name)
+			return ok
+		}
+	default:
+		match = func(name string) bool { return name == term }
+	}
+
+	// A term may name groups as well as hosts
+	groups := map[string]bool{}
+	for _, group := range append(inv.Groups, "ungrouped") {
+		if match(group) {
+			for _, member := range inv.groupTree(group) {
+				groups[member] = true
+			}
+		}
+	}
+	return inv.hostNames(func(host HostConfig) bool {
+		group := host.Group
+		if group == "" {
+			group = "ungrouped"
+		}
+		return match(host.Host) || groups[group]
+	}), nil
+}
+
+// ✅ The group and all of its descendants
+func (inv *Inventory) groupTree(group string) []string {
+	tree := []string{group}
+	seen := map[string]bool{group: true}
+	for i := 0; i < len(tree); i++ {
+		for _, child := range inv.Children[tree[i]] {
+			if !seen[child] {
+				seen[child] = true
+				tree = append(tree, child)
+			}
+		}
+	}
+	return tree
+}
+
+// ✅ Distinct names of the hosts accepted by keep
+func (inv *Inventory) hostNames(keep func(HostConfig) bool) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, host := range inv.Hosts {
+		if !seen[host.Host] && keep(host) {
+			seen[host.Host] = true
+			names = append(names, host.Host)
+		}
+	}
+	return names
+}
@@ -0,0 +1,62 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ✅ Test host pattern matching against a loaded inventory
+func TestMatchHosts(t *testing.T) {
+	inv, err := ParseInventory([]byte(`
+all:
+  hosts:
+    bastion:
+  children:
+    prod:
+      children:
+        web:
+          hosts:
+            web1:
+            web2:
+        db:
+          hosts:
+            db1:
+    staging:
+      hosts:
+        web3:
+`))
+	if err != nil {
+		t.Fatalf("ParseInventory returned error: %v", err)
+	}
+
+	for pattern, expected := range map[string][]string{
+		"all":              {"bastion", "db1", "web1", "web2", "web3"},
+		"web1":             {"web1"},
+		"prod":             {"db1", "web1", "web2"},
+		"web*":             {"web1", "web2", "web3"},
+		"~web[13]":         {"web1", "web3"},
+		"web:db":           {"db1", "web1", "web2"},
+		"prod,staging":     {"db1", "web1", "web2", "web3"},
+		"prod:!db":         {"web1", "web2"},
+		"web*:&prod":       {"web1", "web2"},
+		"ungrouped":        {"bastion"},
+		"webb":             {},
+		"prod:!prod":       {},
+		"staging:&prod":    {},
+		"missing:!web1":    {},
+		"web1:web1:!other": {"web1"},
+	} {
+		matches, err := inv.MatchHosts(pattern)
+		if err != nil {
+			t.Errorf("MatchHosts(%q) returned error: %v", pattern, err)
+			continue
+		}
+		if !reflect.DeepEqual(matches, expected) {
+			t.Errorf("MatchHosts(%q) = %v, expected %v", pattern, matches, expected)
+		}
+	}
+
+	if _, err := inv.MatchHosts("@site.retry"); err == nil {
+		t.Error("Expected an error for a retry file pattern")
+	}
+}
@@ -0,0 +1,78 @@
+package inventory
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ✅ A host in the sample inventory with the comment written above it
+type sampleHost struct {
+	comment string
+	host    HostConfig
+}
+
+// ✅ Sample hosts, one for each kind of setting gosible writes
+// Host vars are rendered by writeHostVars, like every generated inventory,
+// so the sample always shows what gosible itself would write
+var sampleHosts = []sampleHost{
+	{"The machine gosible runs on, without SSH", HostConfig{Host: "localhost", Connection: ConnectionLocal}},
+	{"A Linux server reached over SSH, the default connection", HostConfig{
+		Host: "web1", Address: "192.0.2.10", Group: "web", SSHUser: "ubuntu", SSHKeyFile: "~/.ssh/id_rsa", Become: true,
+	}},
+	{"SSH on another port, with a host variable for the playbooks", HostConfig{
+		Host: "web2", Address: "192.0.2.11", Group: "web", SSHUser: "ubuntu", SSHKeyFile: "~/.ssh/id_rsa", SSHPort: "2222",
+		Vars: map[string]string{"nginx_worker_processes": "4"},
+	}},
+	{"Reached through a bastion; becomes postgres rather than root", HostConfig{
+		Host: "db1", Address: "192.0.2.20", Group: "db", SSHUser: "admin", Become: true, BecomeUser: "postgres",
+		PythonInterpreter: "/usr/bin/python3", ProxyJump: "admin@bastion.example.com",
+	}},
+	{"A docker container, reached with docker exec", HostConfig{Host: "app", Group: "containers", Connection: ConnectionDocker}},
+	{"A Windows server managed over WinRM; keep the password in ansible-vault", HostConfig{
+		Host: "win1", Address: "192.0.2.30", Group: "windows", SSHUser: "Administrator",
+	}.Windows()},
+}
+
+// ✅ A commented example inventory showing groups, child groups, host vars
+// and every connection type gosible supports
+func SampleInventory() string {
+	var b strings.Builder
+	b.WriteString("# Sample gosible inventory: edit the hosts and groups, then run\n")
+	b.WriteString("#   gosible validate <this file>\n")
+	b.WriteString("# Addresses are from 192.0.2.0/24, reserved for documentation.\n")
+	b.WriteString("---\nall:\n")
+	b.WriteString("  # Hosts directly under all belong to no other group\n  hosts:\n")
+
+	var groupNames []string
+	groups := map[string][]sampleHost{}
+	for _, sample := range sampleHosts {
+		if sample.host.Group == "" {
+			writeSampleHost(&b, sample, "    ")
+			continue
+		}
+		if _, ok := groups[sample.host.Group]; !ok {
+			groupNames = append(groupNames, sample.host.Group)
+		}
+		groups[sample.host.Group] = append(groups[sample.host.Group], sample)
+	}
+
+	b.WriteString("\n  # Every other group is a child of all\n  children:\n")
+	for _, name := range groupNames {
+		b.WriteString(fmt.Sprintf("    %s:\n      hosts:\n", name))
+		for _, sample := range groups[name] {
+			writeSampleHost(&b, sample, "        ")
+		}
+	}
+	b.WriteString("    # A parent group holds the hosts of its child groups, so\n")
+	b.WriteString("    # `--limit prod` targets web1, web2 and db1\n")
+	b.WriteString("    prod:\n      children:\n        web:\n        db:\n")
+	b.WriteString("      # Group vars apply to every host in the group\n")
+	b.WriteString("      vars:\n        environment: production\n")
+	return b.String()
+}
+
+// ✅ Write a commented sample host at the given indent
+func writeSampleHost(b *strings.Builder, sample sampleHost, indent string) {
+	b.WriteString(fmt.Sprintf("%s# %s\n%s%s:\n", indent, sample.comment, indent, sample.host.Host))
+	writeHostVars(b, sample.host, indent+"  ")
+}
@@ -0,0 +1,40 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ✅ Test that the sample inventory loads and validates cleanly
+func TestSampleInventory(t *testing.T) {
+	inv, err := ParseInventory([]byte(SampleInventory()))
+	if err != nil {
+		t.Fatalf("Sample inventory doesn't parse: %v", err)
+	}
+	if problems := Validate(inv); len(problems) != 0 {
+		t.Errorf("Expected no problems, got %+v", problems)
+	}
+
+	hosts := map[string]HostConfig{}
+	for _, host := range inv.Hosts {
+		hosts[host.Host] = host
+	}
+	for _, sample := range sampleHosts {
+		// Hosts in prod's child groups inherit its vars
+		expected := sample.host
+		if expected.Group == "web" || expected.Group == "db" {
+			expected.Vars = map[string]string{"environment": "production"}
+			for key, value := range sample.host.Vars {
+				expected.Vars[key] = value
+			}
+		}
+		if got := hosts[sample.host.Host]; got.Host == "" {
+			t.Errorf("Host %s is missing from the sample", sample.host.Host)
+		} else if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %+v to load back, got %+v", expected, got)
+		}
+	}
+	if children := inv.Children["prod"]; len(children) != 2 || children[0] != "web" || children[1] != "db" {
+		t.Errorf("Expected prod to have web and db as children, got %v", children)
+	}
+}
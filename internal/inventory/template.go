@@ -0,0 +1,74 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ✅ Template holds connection defaults shared by hosts from one provider
+// Empty fields leave the host's own setting (or ansible's default) alone
+type Template struct {
+	SSHUser           string
+	SSHKeyFile        string
+	PythonInterpreter string
+}
+
+// ✅ Built-in templates for common cloud images, keyed by name
+var Templates = map[string]Template{
+	"ec2": {
+		SSHUser:           "ec2-user",
+		SSHKeyFile:        "~/.ssh/ec2.pem",
+		PythonInterpreter: "/usr/bin/python3",
+	},
+	// gcloud stores its key here and logs in as the local user, so no user is set
+	"gcp": {
+		SSHKeyFile:        "~/.ssh/google_compute_engine",
+		PythonInterpreter: "/usr/bin/python3",
+	},
+	"azure": {
+		SSHUser:           "azureuser",
+		SSHKeyFile:        "~/.ssh/id_rsa",
+		PythonInterpreter: "/usr/bin/python3",
+	},
+}
+
+// ✅ Look up a template by name; an empty name is the zero template
+func LookupTemplate(name string) (Template, error) {
+	if name == "" {
+		return Template{}, nil
+	}
+	template, ok := Templates[name]
+	if !ok {
+		return Template{}, fmt.Errorf("unknown inventory template %q (available: %s)", name, strings.Join(TemplateNames(), ", "))
+	}
+	return template, nil
+}
+
+// ✅ Names of the available templates, sorted
+func TemplateNames() []string {
+	names := make([]string, 0, len(Templates))
+	for name := range Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ✅ Fill the host's empty settings from the template
+// Values already set on the host win, so a template can be overridden per host.
+// SSH settings are only applied to hosts reached over SSH.
+func (t Template) Apply(host HostConfig) HostConfig {
+	if host.UsesSSH() {
+		if host.SSHUser == "" {
+			host.SSHUser = t.SSHUser
+		}
+		if host.SSHKeyFile == "" {
+			host.SSHKeyFile = t.SSHKeyFile
+		}
+	}
+	if host.PythonInterpreter == "" {
+		host.PythonInterpreter = t.PythonInterpreter
+	}
+	return host
+}
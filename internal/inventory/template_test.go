@@ -0,0 +1,40 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ✅ Test that a template fills empty settings and keeps per-host values
+func TestTemplateApply(t *testing.T) {
+	template, err := LookupTemplate("ec2")
+	if err != nil {
+		t.Fatalf("LookupTemplate returned error: %v", err)
+	}
+
+	host := template.Apply(HostConfig{Host: "web1"})
+	expected := HostConfig{Host: "web1", SSHUser: "ec2-user", SSHKeyFile: "~/.ssh/ec2.pem", PythonInterpreter: "/usr/bin/python3"}
+	if !reflect.DeepEqual(host, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, host)
+	}
+
+	host = template.Apply(HostConfig{Host: "web2", SSHUser: "admin"})
+	if host.SSHUser != "admin" || host.SSHKeyFile != "~/.ssh/ec2.pem" {
+		t.Errorf("Expected the host's user to win over the template, got %+v", host)
+	}
+
+	host = template.Apply(HostConfig{Host: "container1", Connection: ConnectionDocker})
+	if host.SSHUser != "" || host.SSHKeyFile != "" || host.PythonInterpreter != "/usr/bin/python3" {
+		t.Errorf("Expected only the interpreter for a docker host, got %+v", host)
+	}
+}
+
+// ✅ Test that unknown template names are rejected
+func TestLookupTemplate_Unknown(t *testing.T) {
+	if _, err := LookupTemplate("openstack"); err == nil {
+		t.Error("Expected an error for an unknown template")
+	}
+	if template, err := LookupTemplate(""); err != nil || template != (Template{}) {
+		t.Errorf("Expected the zero template for an empty name, got %+v and %v", template, err)
+	}
+}
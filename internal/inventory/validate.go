@@ -0,0 +1,141 @@
+package inventory
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ✅ Problem severities reported by Validate
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ✅ Problem is a single issue found in an inventory
+type Problem struct {
+	Severity string
+	Message  string
+}
+
+// ✅ Validate checks a loaded inventory for common mistakes
+// Duplicate hosts and invalid ports are errors. Hosts without ansible_user
+// are warnings, as ansible then connects as the local user or whatever the
+// SSH config says, and so are empty groups.
+func Validate(inv *Inventory) []Problem {
+	var problems []Problem
+	addError := func(format string, args ...any) {
+		problems = append(problems, Problem{Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+	}
+	addWarning := func(format string, args ...any) {
+		problems = append(problems, Problem{Severity: SeverityWarning, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// ✅ Duplicate hosts within the same group
+	type definition struct{ host, group string }
+	seen := map[definition]bool{}
+	for _, host := range inv.Hosts {
+		key := definition{host.Host, host.Group}
+		if seen[key] {
+			addError("host %q is defined more than once in %s", host.Host, groupLabel(host.Group))
+		}
+		seen[key] = true
+	}
+
+	// ✅ Connection info and ports; a host's vars may be set in any one of its
+	// definitions or inherited from its groups, which the loader already applied
+	hasUser := map[string]bool{}
+	needsUser := map[string]bool{}
+	var order []string
+	for _, host := range inv.Hosts {
+		if _, ok := hasUser[host.Host]; !ok {
+			order = append(order, host.Host)
+			hasUser[host.Host] = false
+		}
+		if host.SSHUser != "" {
+			hasUser[host.Host] = true
+		}
+		if host.Connection == "" || host.Connection == ConnectionSSH || host.Connection == ConnectionWinRM {
+			needsUser[host.Host] = true
+		}
+		if host.SSHPort != "" {
+			if port, err := strconv.Atoi(host.SSHPort); err != nil || port < 1 || port > 65535 {
+				addError("host %q has an invalid port %q", host.Host, host.SSHPort)
+			}
+		}
+	}
+	for _, host := range order {
+		if needsUser[host] && !hasUser[host] {
+			addWarning("host %q has no ansible_user set, so ansible connects as the local user", host)
+		}
+	}
+
+	// ✅ Hosts defined both under all.hosts and in a group
+	for _, name := range UngroupedDuplicates(inv.Hosts) {
+		addWarning("host %q is defined both ungrouped and in a group", name)
+	}
+
+	// ✅ Groups without hosts or child groups
+	populated := map[string]bool{}
+	for _, host := range inv.Hosts {
+		populated[host.Group] = true
+	}
+	reported := map[string]bool{}
+	for _, group := range inv.Groups {
+		if populated[group] || len(inv.Children[group]) > 0 || reported[group] {
+			continue
+		}
+		reported[group] = true
+		addWarning("group %q has no hosts", group)
+	}
+
+	return problems
+}
+
+// ✅ Names of hosts defined both without a group and in one, in order
+// Ansible merges the definitions into a single host, so vars set on the
+// ungrouped one are easy to miss when editing the grouped one
+func UngroupedDuplicates(hosts []HostConfig) []string {
+	grouped := map[string]bool{}
+	for _, host := range hosts {
+		if len(host.GroupNames()) > 0 {
+			grouped[host.Host] = true
+		}
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	for _, host := range hosts {
+		if len(host.GroupNames()) == 0 && grouped[host.Host] && !seen[host.Host] {
+			seen[host.Host] = true
+			names = append(names, host.Host)
+		}
+	}
+	return names
+}
+
+// ✅ Drop the ungrouped definitions of hosts that are also in a group
+func WithoutUngroupedDuplicates(hosts []HostConfig) []HostConfig {
+	duplicates := map[string]bool{}
+	for _, name := range UngroupedDuplicates(hosts) {
+		duplicates[name] = true
+	}
+
+	kept := make([]HostConfig, 0, len(hosts))
+	for _, host := range hosts {
+		if len(host.GroupNames()) == 0 && duplicates[host.Host] {
+			continue
+		}
+		kept = append(kept, host)
+	}
+	return kept
+}
+
+// ✅ Report whether any problem is an error
+func HasErrors(problems []Problem) bool {
+	for _, problem := range problems {
+		if problem.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package inventory
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ✅ Test that a broken inventory reports each specific problem
+func TestValidate_BrokenInventory(t *testing.T) {
+	inv, err := ParseInventory([]byte(`---
+all:
+  hosts:
+    web1:
+      ansible_user: ubuntu
+      ansible_port: 70000
+    web1:
+      ansible_user: ubuntu
+    nouser:
+      ansible_ssh_private_key_file: ~/.ssh/id_rsa
+    app1:
+      ansible_connection: docker
+  children:
+    empty:
+    db:
+      hosts:
+        db1:
+          ansible_user: root
+          ansible_port: ssh
+`))
+	if err != nil {
+		t.Fatalf("ParseInventory returned error: %v", err)
+	}
+
+	problems := Validate(inv)
+	expected := []Problem{
+		{Severity: SeverityError, Message: `host "web1" is defined more than once in all`},
+		{Severity: SeverityError, Message: `host "web1" has an invalid port "70000"`},
+		{Severity: SeverityError, Message: `host "db1" has an invalid port "ssh"`},
+		{Severity: SeverityWarning, Message: `host "nouser" has no ansible_user set, so ansible connects as the local user`},
+		{Severity: SeverityWarning, Message: `group "empty" has no hosts`},
+	}
+
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %d: %+v", len(expected), len(problems), problems)
+	}
+	for i := range expected {
+		if problems[i] != expected[i] {
+			t.Errorf("Problem %d: expected %+v, got %+v", i, expected[i], problems[i])
+		}
+	}
+	if !HasErrors(problems) {
+		t.Error("Expected HasErrors to be true")
+	}
+}
+
+// ✅ Test that a well-formed inventory has no problems
+func TestValidate_CleanInventory(t *testing.T) {
+	content, err := RenderInventory([]HostConfig{
+		{Host: "web1", Group: "web", SSHUser: "ubuntu", SSHKeyFile: "~/.ssh/id_rsa", SSHPort: "22"},
+		{Host: "container1", Connection: ConnectionDocker},
+	})
+	if err != nil {
+		t.Fatalf("RenderInventory returned error: %v", err)
+	}
+	inv, err := ParseInventory([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseInventory returned error: %v", err)
+	}
+
+	if problems := Validate(inv); len(problems) != 0 {
+		t.Errorf("Expected no problems, got %+v", problems)
+	}
+}
+
+// ✅ Test that connection settings inherited from all or group vars count
+func TestValidate_InheritedVars(t *testing.T) {
+	inv, err := ParseInventory([]byte("all:\n  vars:\n    ansible_user: deploy\n  hosts:\n    web1:\n" +
+		"  children:\n    local:\n      vars:\n        ansible_connection: local\n      hosts:\n        runner:\n"))
+	if err != nil {
+		t.Fatalf("ParseInventory returned error: %v", err)
+	}
+
+	if problems := Validate(inv); len(problems) != 0 {
+		t.Errorf("Expected no problems, got %+v", problems)
+	}
+}
+
+// ✅ Test that a host both ungrouped and grouped is reported and can be dropped
+// from all.hosts
+func TestUngroupedDuplicates(t *testing.T) {
+	hosts := []HostConfig{
+		{Host: "web1", SSHUser: "root"},
+		{Host: "web1", Group: "web", SSHUser: "ubuntu"},
+		{Host: "db1", Groups: []string{"db"}},
+		{Host: "db1"},
+		{Host: "cache1"},
+		{Host: "app1", Group: "apps"},
+	}
+
+	if names := UngroupedDuplicates(hosts); !reflect.DeepEqual(names, []string{"web1", "db1"}) {
+		t.Errorf("Expected web1 and db1, got %v", names)
+	}
+	expected := []HostConfig{hosts[1], hosts[2], hosts[4], hosts[5]}
+	if kept := WithoutUngroupedDuplicates(hosts); !reflect.DeepEqual(kept, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, kept)
+	}
+
+	inv, err := ParseInventory([]byte("all:\n  hosts:\n    web1:\n  children:\n    web:\n      hosts:\n        web1:\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems := Validate(inv)
+	found := false
+	for _, problem := range problems {
+		if problem.Severity == SeverityWarning && strings.Contains(problem.Message, `"web1" is defined both ungrouped and in a group`) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a collision warning, got %+v", problems)
+	}
+}
@@ -0,0 +1,50 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// ✅ Allow overriding the TCP dial for testing
+var dialTimeout = net.DialTimeout
+
+// ✅ How long to wait for an instance's SSH port before calling it unreachable
+const verifyTimeout = 2 * time.Second
+
+// ✅ CheckReachable returns why an instance can't be reached, or nil
+// Containers are checked with `docker inspect`, instances with an address by
+// dialing their SSH port. Instances without either can't be checked and pass.
+func CheckReachable(i Instance) error {
+	switch {
+	case i.ConnectionType == ConnectionDocker:
+		out, err := execCommand("docker", "inspect", "--format", "{{.State.Running}}", i.Name).Output()
+		if err != nil {
+			return fmt.Errorf("docker inspect failed: %w", err)
+		}
+		if strings.TrimSpace(string(out)) != "true" {
+			return errors.New("container is not running")
+		}
+	case i.Address != "":
+		conn, err := dialTimeout("tcp", net.JoinHostPort(i.Address, "22"), verifyTimeout)
+		if err != nil {
+			return err
+		}
+		conn.Close()
+	}
+	return nil
+}
+
+// ✅ Return a copy of instances with Unreachable set for those failing CheckReachable
+func VerifyInstances(instances []Instance) []Instance {
+	verified := make([]Instance, len(instances))
+	for i, instance := range instances {
+		if err := CheckReachable(instance); err != nil {
+			instance.Unreachable = err.Error()
+		}
+		verified[i] = instance
+	}
+	return verified
+}
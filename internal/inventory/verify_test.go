@@ -0,0 +1,50 @@
+package inventory
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ✅ Test that unreachable addresses and stopped containers are marked
+func TestVerifyInstances(t *testing.T) {
+	mockDiscovery(t)
+	t.Setenv("MOCK_DOCKER_OUTPUT", "false\n")
+	var dialed []string
+	oldDial := dialTimeout
+	dialTimeout = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		dialed = append(dialed, address)
+		if address == "10.0.0.6:22" {
+			return nil, errors.New("connection refused")
+		}
+		client, server := net.Pipe()
+		server.Close()
+		return client, nil
+	}
+	defer func() { dialTimeout = oldDial }()
+
+	instances := VerifyInstances([]Instance{
+		{Name: "up", Source: SourceMultipass, Address: "10.0.0.5"},
+		{Name: "down", Source: SourceMultipass, Address: "10.0.0.6"},
+		{Name: "container1", Source: SourceDocker, ConnectionType: ConnectionDocker},
+		{Name: "web", Source: SourceVagrant},
+	})
+
+	if instances[0].Unreachable != "" || instances[3].Unreachable != "" {
+		t.Errorf("Expected reachable and uncheckable instances to pass, got %+v", instances)
+	}
+	if instances[1].Unreachable != "connection refused" {
+		t.Errorf("Expected the refused dial to be reported, got %q", instances[1].Unreachable)
+	}
+	if instances[2].Unreachable != "container is not running" {
+		t.Errorf("Expected the stopped container to be reported, got %q", instances[2].Unreachable)
+	}
+	if !strings.Contains(instances[1].Label(), "unreachable") {
+		t.Errorf("Expected the label to mark the instance, got %q", instances[1].Label())
+	}
+	if len(dialed) != 2 {
+		t.Errorf("Expected only the two addresses to be dialed, got %v", dialed)
+	}
+}
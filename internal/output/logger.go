@@ -0,0 +1,40 @@
+package output
+
+// ✅ Logger receives gosible's log messages; inject one with SetLogger
+// Messages are printf-style formats without a trailing newline
+type Logger interface {
+	Info(format string, args ...any)
+	Warn(format string, args ...any)
+	Error(format string, args ...any)
+}
+
+// ✅ stdLogger is the default Logger, printing to stdout/stderr and honoring
+// plain and quiet modes
+type stdLogger struct{}
+
+func (stdLogger) Info(format string, args ...any)  { Printf(format+"\n", args...) }
+func (stdLogger) Warn(format string, args ...any)  { Warnf(format+"\n", args...) }
+func (stdLogger) Error(format string, args ...any) { Errorf(format+"\n", args...) }
+
+var logger Logger = stdLogger{}
+
+// ✅ Route log messages to l; nil restores the default logger
+func SetLogger(l Logger) {
+	if l == nil {
+		l = stdLogger{}
+	}
+	logger = l
+}
+
+// ✅ Log helpers used by the rest of gosible
+func Info(format string, args ...any) {
+	logger.Info(format, args...)
+}
+
+func Warn(format string, args ...any) {
+	logger.Warn(format, args...)
+}
+
+func Error(format string, args ...any) {
+	logger.Error(format, args...)
+}
@@ -0,0 +1,51 @@
+package output
+
+import (
+	"fmt"
+	"testing"
+)
+
+// ✅ captureLogger records messages by level
+type captureLogger struct {
+	messages []string
+}
+
+func (c *captureLogger) Info(format string, args ...any) {
+	c.messages = append(c.messages, "info: "+fmt.Sprintf(format, args...))
+}
+
+func (c *captureLogger) Warn(format string, args ...any) {
+	c.messages = append(c.messages, "warn: "+fmt.Sprintf(format, args...))
+}
+
+func (c *captureLogger) Error(format string, args ...any) {
+	c.messages = append(c.messages, "error: "+fmt.Sprintf(format, args...))
+}
+
+// ✅ Test that an injected logger receives messages instead of stdout
+func TestSetLogger(t *testing.T) {
+	capture := &captureLogger{}
+	SetLogger(capture)
+	defer SetLogger(nil)
+
+	stdout := captureOutput(func() {
+		Info("running %s", "site.yml")
+		Warn("careful")
+		Error("failed: %d", 2)
+	})
+
+	if stdout != "" {
+		t.Errorf("Expected nothing on stdout, got %q", stdout)
+	}
+	expected := []string{"info: running site.yml", "warn: careful", "error: failed: 2"}
+	if fmt.Sprint(capture.messages) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, capture.messages)
+	}
+}
+
+// ✅ Test that the default logger prints info lines to stdout
+func TestDefaultLogger(t *testing.T) {
+	if output := captureOutput(func() { Info("hello %s", "world") }); output != "hello world\n" {
+		t.Errorf("Expected %q, got %q", "hello world\n", output)
+	}
+}
@@ -0,0 +1,152 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ✅ Plain mode strips emoji and ANSI escapes from gosible's own messages
+var plain bool
+
+// ✅ Quiet mode suppresses informational messages; warnings and errors still print
+var quiet bool
+
+// ✅ Interactive mode shows prompts; it's off when stdin isn't a terminal,
+// so answers piped in by a script don't fill its output with questions
+var interactive = IsTerminal(os.Stdin)
+
+// ✅ Matches ANSI escape sequences such as color codes
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// ✅ Enable or disable plain output
+func SetPlain(enabled bool) {
+	plain = enabled
+}
+
+// ✅ Enable or disable quiet output
+func SetQuiet(enabled bool) {
+	quiet = enabled
+}
+
+// ✅ Report whether quiet output is on
+func Quiet() bool {
+	return quiet
+}
+
+// ✅ Enable or disable prompts, overriding the terminal detection
+func SetInteractive(enabled bool) {
+	interactive = enabled
+}
+
+// ✅ Report whether prompts are shown
+func Interactive() bool {
+	return interactive
+}
+
+// ✅ Report whether f is a terminal rather than a pipe or a file
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ✅ Report whether plain output is on, via SetPlain or the NO_COLOR convention
+func Plain() bool {
+	return plain || os.Getenv("NO_COLOR") != ""
+}
+
+// ✅ Clean returns s with emoji and ANSI escapes removed when plain output is on
+func Clean(s string) string {
+	if !Plain() {
+		return s
+	}
+	return Strip(s)
+}
+
+// ✅ Strip removes emoji and ANSI escapes unconditionally
+// The space that usually follows an emoji is dropped with it, so
+// "✅ Done" becomes "Done" rather than " Done"
+func Strip(s string) string {
+	s = ansiPattern.ReplaceAllString(s, "")
+
+	var b strings.Builder
+	skipSpace := false
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		switch {
+		case IsEmoji(r):
+			skipSpace = true
+		case skipSpace && r == ' ':
+			skipSpace = false
+		default:
+			skipSpace = false
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ✅ Report whether r is an emoji or an emoji modifier
+func IsEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // Emoticons, pictographs, transport, flags, ...
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // Misc symbols and dingbats (✅ ❌ ⚠ ❓)
+		return true
+	case r >= 0x2300 && r <= 0x23FF: // Misc technical (⏭ ⏳)
+		return true
+	case r >= 0x2190 && r <= 0x21FF: // Arrows (↩)
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // Misc symbols and arrows
+		return true
+	case r == 0xFE0F || r == 0x200D: // Variation selector and zero-width joiner
+		return true
+	}
+	return false
+}
+
+// ✅ Print helpers mirroring fmt for informational messages and prompts
+// These write cleaned messages to stdout and are silenced by quiet mode
+func Printf(format string, args ...any) {
+	if quiet {
+		return
+	}
+	fmt.Fprint(os.Stdout, Clean(fmt.Sprintf(format, args...)))
+}
+
+func Println(args ...any) {
+	if quiet {
+		return
+	}
+	fmt.Fprint(os.Stdout, Clean(fmt.Sprintln(args...)))
+}
+
+func Print(args ...any) {
+	if quiet {
+		return
+	}
+	fmt.Fprint(os.Stdout, Clean(fmt.Sprint(args...)))
+}
+
+// ✅ Print a question followed by the `> ` input marker
+// Nothing is printed when not interactive, since nobody is there to read it
+func Prompt(question string) {
+	if !interactive {
+		return
+	}
+	Println(question)
+	Print("> ")
+}
+
+// ✅ Print a warning to stderr, even in quiet mode
+func Warnf(format string, args ...any) {
+	fmt.Fprint(os.Stderr, Clean(fmt.Sprintf(format, args...)))
+}
+
+// ✅ Print an error to stderr, even in quiet mode
+func Errorf(format string, args ...any) {
+	fmt.Fprint(os.Stderr, Clean(fmt.Sprintf(format, args...)))
+}
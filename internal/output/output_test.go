@@ -0,0 +1,108 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// ✅ Capture stdout output
+func captureOutput(f func()) string {
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	f()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+	return buf.String()
+}
+
+// ✅ Test that plain mode strips emoji and ANSI escapes
+func TestPrintf_Plain(t *testing.T) {
+	SetPlain(true)
+	defer SetPlain(false)
+
+	output := captureOutput(func() {
+		Printf("✅ Inventory file created at: %s\n", "inv.yml")
+		Println("\n🖥️ Configuring", "web1")
+		Println("⚠️ \x1b[31mfailed\x1b[0m ↩️ 🔄")
+	})
+
+	expected := "Inventory file created at: inv.yml\n\nConfiguring web1\nfailed \n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+	for _, r := range output {
+		if IsEmoji(r) || r == 0x1b {
+			t.Errorf("Found emoji or escape %q in plain output %q", r, output)
+		}
+	}
+}
+
+// ✅ Test that output is untouched by default and NO_COLOR enables plain mode
+func TestPrintf_NoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	if output := captureOutput(func() { Printf("✅ ok\n") }); output != "✅ ok\n" {
+		t.Errorf("Expected emoji to be kept by default, got %q", output)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if output := captureOutput(func() { Printf("✅ ok\n") }); output != "ok\n" {
+		t.Errorf("Expected NO_COLOR to strip emoji, got %q", output)
+	}
+}
+
+// ✅ Test that quiet mode silences info but not warnings or errors
+func TestQuiet(t *testing.T) {
+	SetQuiet(true)
+	defer SetQuiet(false)
+
+	stdout := captureOutput(func() {
+		Printf("🔄 Executing\n")
+		Println("info")
+		Print("> ")
+	})
+	if stdout != "" {
+		t.Errorf("Expected no informational output, got %q", stdout)
+	}
+
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	Warnf("careful\n")
+	Errorf("broken\n")
+	w.Close()
+	os.Stderr = oldStderr
+
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+	if buf.String() != "careful\nbroken\n" {
+		t.Errorf("Expected warnings and errors on stderr, got %q", buf.String())
+	}
+}
+
+// ✅ Test that prompts are only shown when stdin is a terminal
+func TestPrompt_NonTTY(t *testing.T) {
+	r, w, _ := os.Pipe()
+	defer r.Close()
+	defer w.Close()
+	if IsTerminal(r) {
+		t.Error("Expected a pipe not to be a terminal")
+	}
+
+	defer SetInteractive(Interactive())
+	SetInteractive(false)
+	if output := captureOutput(func() { Prompt("\n📂 Inventory file?") }); output != "" {
+		t.Errorf("Expected no prompt without a terminal, got %q", output)
+	}
+
+	SetInteractive(true)
+	if output := captureOutput(func() { Prompt("\n📂 Inventory file?") }); output != "\n📂 Inventory file?\n> " {
+		t.Errorf("Expected the prompt on a terminal, got %q", output)
+	}
+}
@@ -0,0 +1,100 @@
+package playbook
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ✅ Conventional playbook directory, as created by `gosible init`
+const DefaultDir = "playbooks"
+
+// ✅ Report whether a file name looks like a YAML playbook
+func isPlaybookFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yml" || ext == ".yaml"
+}
+
+// ✅ FromDir returns the playbooks directly inside dir, sorted by name
+// Subdirectories (roles, group_vars, ...) are not searched
+func FromDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading playbook directory: %w", err)
+	}
+
+	var playbooks []string
+	for _, entry := range entries {
+		if entry.IsDir() || !isPlaybookFile(entry.Name()) {
+			continue
+		}
+		playbooks = append(playbooks, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(playbooks)
+
+	if len(playbooks) == 0 {
+		return nil, fmt.Errorf("no playbooks found in %s", dir)
+	}
+	return playbooks, nil
+}
+
+// ✅ FromList reads newline-separated playbook paths from a file
+// Blank lines and lines starting with `#` are ignored
+func FromList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading playbook list: %w", err)
+	}
+	defer file.Close()
+
+	var playbooks []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		playbooks = append(playbooks, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading playbook list: %w", err)
+	}
+	return playbooks, nil
+}
+
+// ✅ Hosts returns the `hosts` pattern of every play in a playbook, in order
+// A list of patterns is joined with commas the way ansible reads it. Plays
+// without hosts, such as `import_playbook` entries, are skipped.
+func Hosts(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading playbook: %w", err)
+	}
+
+	var plays []struct {
+		Hosts yaml.Node `yaml:"hosts"`
+	}
+	if err := yaml.Unmarshal(data, &plays); err != nil {
+		return nil, fmt.Errorf("error parsing playbook %s: %w", path, err)
+	}
+
+	var patterns []string
+	for _, play := range plays {
+		switch play.Hosts.Kind {
+		case yaml.ScalarNode:
+			patterns = append(patterns, play.Hosts.Value)
+		case yaml.SequenceNode:
+			var list []string
+			for _, item := range play.Hosts.Content {
+				list = append(list, item.Value)
+			}
+			patterns = append(patterns, strings.Join(list, ","))
+		}
+	}
+	return patterns, nil
+}
@@ -0,0 +1,87 @@
+package playbook
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// ✅ Test that directory playbooks are returned in sorted order
+func TestFromDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20-app.yml", "10-base.yml", "30-db.yaml", "README.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "roles.yml"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	playbooks, err := FromDir(dir)
+	if err != nil {
+		t.Fatalf("FromDir returned error: %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(dir, "10-base.yml"),
+		filepath.Join(dir, "20-app.yml"),
+		filepath.Join(dir, "30-db.yaml"),
+	}
+	if !reflect.DeepEqual(playbooks, expected) {
+		t.Errorf("Expected %v, got %v", expected, playbooks)
+	}
+}
+
+// ✅ Test that an empty directory is an error rather than a silent no-op
+func TestFromDir_Empty(t *testing.T) {
+	if _, err := FromDir(t.TempDir()); err == nil {
+		t.Error("Expected an error for a directory without playbooks")
+	}
+}
+
+// ✅ Test that blank lines and comments are skipped in list files
+func TestFromList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "playbooks.txt")
+	content := "# staged rollout\nbase.yml\n\n  app.yml  \n# db.yml\ndeploy/web.yml\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	playbooks, err := FromList(path)
+	if err != nil {
+		t.Fatalf("FromList returned error: %v", err)
+	}
+
+	expected := []string{"base.yml", "app.yml", "deploy/web.yml"}
+	if !reflect.DeepEqual(playbooks, expected) {
+		t.Errorf("Expected %v, got %v", expected, playbooks)
+	}
+}
+
+// ✅ Test that each play's hosts pattern is returned, lists joined with commas
+func TestHosts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "site.yml")
+	content := `---
+- hosts: web
+  tasks: []
+- import_playbook: other.yml
+- hosts:
+    - db
+    - localhost
+  tasks: []
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := Hosts(path)
+	if err != nil {
+		t.Fatalf("Hosts returned error: %v", err)
+	}
+	expected := []string{"web", "db,localhost"}
+	if !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("Expected %v, got %v", expected, hosts)
+	}
+}
@@ -0,0 +1,54 @@
+package prompt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ✅ Pick items from a numbered-menu answer
+// `all` selects every item; otherwise the answer is space-separated 1-based
+// numbers, returned in the order given. See ParseIndices for the rules.
+func ParseSelection(input string, items []string) ([]string, error) {
+	indices, err := ParseIndices(input, len(items))
+	if err != nil {
+		return nil, err
+	}
+	selected := make([]string, 0, len(indices))
+	for _, i := range indices {
+		selected = append(selected, items[i])
+	}
+	return selected, nil
+}
+
+// ✅ Turn a numbered-menu answer into zero-based indices into count items
+// An empty answer selects nothing. Non-numeric and out-of-range tokens are
+// errors; a number given twice is only selected once.
+func ParseIndices(input string, count int) ([]int, error) {
+	input = strings.TrimSpace(input)
+	if strings.EqualFold(input, "all") {
+		indices := make([]int, count)
+		for i := range indices {
+			indices[i] = i
+		}
+		return indices, nil
+	}
+
+	var indices []int
+	seen := map[int]bool{}
+	for _, token := range strings.Fields(input) {
+		n, err := strconv.Atoi(token)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number, enter numbers from the list or 'all'", token)
+		}
+		if n < 1 || n > count {
+			return nil, fmt.Errorf("%d is out of range, choose between 1 and %d", n, count)
+		}
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		indices = append(indices, n-1)
+	}
+	return indices, nil
+}
@@ -0,0 +1,52 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ✅ Test valid numbered-menu selections
+func TestParseSelection(t *testing.T) {
+	items := []string{"site.yml", "db.yml", "web.yml"}
+	for _, tc := range []struct {
+		input    string
+		expected []string
+	}{
+		{"all", []string{"site.yml", "db.yml", "web.yml"}},
+		{" ALL ", []string{"site.yml", "db.yml", "web.yml"}},
+		{"1 3", []string{"site.yml", "web.yml"}},
+		{"3 1", []string{"web.yml", "site.yml"}},
+		{"2  2 1", []string{"db.yml", "site.yml"}},
+		{"", []string{}},
+		{"   ", []string{}},
+	} {
+		got, err := ParseSelection(tc.input, items)
+		if err != nil {
+			t.Errorf("ParseSelection(%q) returned error: %v", tc.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("ParseSelection(%q) = %v, expected %v", tc.input, got, tc.expected)
+		}
+	}
+}
+
+// ✅ Test that invalid tokens are reported instead of dropped
+func TestParseSelection_Invalid(t *testing.T) {
+	items := []string{"site.yml", "db.yml", "web.yml"}
+	for _, input := range []string{"0", "4", "-1", "x", "1 two", "1,2", "all 1"} {
+		if got, err := ParseSelection(input, items); err == nil {
+			t.Errorf("Expected an error for %q, got %v", input, got)
+		}
+	}
+}
+
+// ✅ Test selecting from an empty list
+func TestParseIndices_Empty(t *testing.T) {
+	if indices, err := ParseIndices("all", 0); err != nil || len(indices) != 0 {
+		t.Errorf("Expected no indices, got %v (%v)", indices, err)
+	}
+	if _, err := ParseIndices("1", 0); err == nil {
+		t.Error("Expected an error when there is nothing to select")
+	}
+}
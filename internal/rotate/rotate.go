@@ -0,0 +1,144 @@
+// Package rotate keeps long-lived files such as the audit log and the command
+// history from growing without bound, by moving them aside gzipped once they
+// pass a size limit
+package rotate
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ✅ Rotated copies kept when Writer.Backups is unset
+const DefaultBackups = 3
+
+// ✅ Writer writes a file, first rotating it to `<path>.1.gz` when it has
+// grown past MaxBytes
+// Older copies move up to `.2.gz` and so on, and the oldest beyond Backups is
+// dropped. A zero MaxBytes disables rotation.
+type Writer struct {
+	Path     string
+	MaxBytes int64
+	Backups  int
+	Perm     os.FileMode
+}
+
+// ✅ Append data to the file, creating it when missing
+// The file is rotated first when data would take it past MaxBytes, so the
+// fresh file starts with data; a single write larger than the limit is still
+// written whole.
+func (w Writer) Append(data []byte) error {
+	if err := w.rotateIf(int64(len(data))); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(w.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, w.Perm)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ✅ Replace the file's content with data, rotating the old content first
+// when the file has grown past MaxBytes
+func (w Writer) Replace(data []byte) error {
+	if err := w.rotateIf(0); err != nil {
+		return err
+	}
+	return os.WriteFile(w.Path, data, w.Perm)
+}
+
+// ✅ Rotate when the file plus incoming bytes would exceed MaxBytes
+// An empty or missing file is never rotated.
+func (w Writer) rotateIf(incoming int64) error {
+	if w.MaxBytes <= 0 {
+		return nil
+	}
+	info, err := os.Stat(w.Path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if info.Size() == 0 || info.Size()+incoming <= w.MaxBytes {
+		return nil
+	}
+	return w.Rotate()
+}
+
+// ✅ Gzip the file to `<path>.1.gz`, shifting older copies up, and remove it
+func (w Writer) Rotate() error {
+	backups := w.Backups
+	if backups <= 0 {
+		backups = DefaultBackups
+	}
+	os.Remove(w.backupPath(backups))
+	for n := backups - 1; n >= 1; n-- {
+		if err := os.Rename(w.backupPath(n), w.backupPath(n+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error rotating %s: %w", w.Path, err)
+		}
+	}
+	if err := gzipFile(w.Path, w.backupPath(1), w.Perm); err != nil {
+		return fmt.Errorf("error rotating %s: %w", w.Path, err)
+	}
+	return os.Remove(w.Path)
+}
+
+func (w Writer) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d.gz", w.Path, n)
+}
+
+// ✅ Write a gzipped copy of src to dst
+func gzipFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// ✅ Parse a size such as 512K, 10M or 1G (powers of 1024), or a plain byte count
+// Empty and zero sizes are 0, which disables rotation.
+func ParseSize(size string) (int64, error) {
+	number := strings.ToUpper(strings.TrimSpace(size))
+	if number == "" {
+		return 0, nil
+	}
+	number = strings.TrimSuffix(number, "B")
+	multiplier := int64(1)
+	for i, unit := range []string{"K", "M", "G"} {
+		if trimmed, ok := strings.CutSuffix(number, unit); ok {
+			number = trimmed
+			multiplier = 1 << (10 * (i + 1))
+			break
+		}
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(number), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q: expected a byte count or a number with K, M or G", size)
+	}
+	return n * multiplier, nil
+}
@@ -0,0 +1,99 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ✅ Read a gzipped file back
+func readGzip(t *testing.T, path string) string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Expected %s to exist: %v", path, err)
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Expected %s to be gzipped: %v", path, err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Could not read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+// ✅ Test that appending past the limit rotates the old content to a gzipped copy
+func TestWriter_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	w := Writer{Path: path, MaxBytes: 10, Backups: 2, Perm: 0o600}
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n", "six\n"} {
+		if err := w.Append([]byte(line)); err != nil {
+			t.Fatalf("Append returned error: %v", err)
+		}
+	}
+
+	// one+two fit, three starts .1, four+five fit, six starts the current file
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "six\n" {
+		t.Errorf("Expected a fresh file with the last line, got %q, %v", data, err)
+	}
+	if got := readGzip(t, path+".1.gz"); got != "four\nfive\n" {
+		t.Errorf("Expected the newest rotated content in .1.gz, got %q", got)
+	}
+	if got := readGzip(t, path+".2.gz"); got != "three\n" {
+		t.Errorf("Expected older content shifted to .2.gz, got %q", got)
+	}
+	if _, err := os.Stat(path + ".3.gz"); !os.IsNotExist(err) {
+		t.Errorf("Expected only 2 backups to be kept, got %v", err)
+	}
+	if info, err := os.Stat(path + ".1.gz"); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected rotated copies to keep the file's permissions, got %v, %v", info, err)
+	}
+}
+
+// ✅ Test that Replace only rotates a file already past the limit, and that
+// a zero limit never rotates
+func TestWriter_Replace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	w := Writer{Path: path, MaxBytes: 8, Perm: 0o644}
+
+	for _, content := range []string{"first", "second line", "third"} {
+		if err := w.Replace([]byte(content)); err != nil {
+			t.Fatalf("Replace returned error: %v", err)
+		}
+	}
+	if got := readGzip(t, path+".1.gz"); got != "second line" {
+		t.Errorf("Expected the oversized content to be rotated, got %q", got)
+	}
+	if _, err := os.Stat(path + ".2.gz"); !os.IsNotExist(err) {
+		t.Errorf("Expected a single rotation, got %v", err)
+	}
+
+	unlimited := Writer{Path: filepath.Join(t.TempDir(), "log"), Perm: 0o644}
+	for i := 0; i < 3; i++ {
+		unlimited.Append([]byte("a long line that is written again and again\n"))
+	}
+	if _, err := os.Stat(unlimited.Path + ".1.gz"); !os.IsNotExist(err) {
+		t.Errorf("Expected no rotation without a limit, got %v", err)
+	}
+}
+
+// ✅ Test size parsing with and without units
+func TestParseSize(t *testing.T) {
+	for size, expected := range map[string]int64{"": 0, "0": 0, "512": 512, "512K": 512 << 10, "10M": 10 << 20, "10mb": 10 << 20, "1G": 1 << 30} {
+		if got, err := ParseSize(size); err != nil || got != expected {
+			t.Errorf("ParseSize(%q) = %d, %v; expected %d", size, got, err, expected)
+		}
+	}
+	for _, size := range []string{"abc", "-1", "10T", "M"} {
+		if _, err := ParseSize(size); err == nil {
+			t.Errorf("Expected an error for %q", size)
+		}
+	}
+}
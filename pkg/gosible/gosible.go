@@ -0,0 +1,111 @@
+// Package gosible lets Go programs drive gosible without the CLI.
+//
+// It is the supported public surface; the packages under internal/ may change
+// at any time.
+package gosible
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bxtal-lsn/gosible/internal/executor"
+	"github.com/bxtal-lsn/gosible/internal/inventory"
+	"github.com/bxtal-lsn/gosible/internal/output"
+)
+
+// ✅ Inventory host settings, as written by CreateInventory
+type Host = inventory.HostConfig
+
+// ✅ A machine or container found by Discover
+type Instance = inventory.Instance
+
+// ✅ Receives gosible's own messages, such as each command it runs and why a
+// run failed; formats are printf-style without a trailing newline
+type Logger = output.Logger
+
+// ✅ Send gosible's messages to l instead of stdout and stderr
+// nil restores the default. Ansible's own output isn't affected.
+func SetLogger(l Logger) {
+	output.SetLogger(l)
+}
+
+// ✅ Supported values for Host.Connection
+const (
+	ConnectionSSH    = inventory.ConnectionSSH
+	ConnectionLocal  = inventory.ConnectionLocal
+	ConnectionDocker = inventory.ConnectionDocker
+	ConnectionWinRM  = inventory.ConnectionWinRM
+)
+
+// ✅ RunOptions configures Run
+// Zero values leave ansible's defaults in place
+type RunOptions struct {
+	Inventory string
+	Playbooks []string // run in order
+	DryRun    bool     // --check
+
+	Tags      string
+	Limit     string
+	ExtraVars []string // key=value, @file or a JSON object
+	Verbosity int
+
+	Become       bool
+	BecomeMethod string
+	BecomeUser   string
+
+	Binary            string // ansible-playbook by default
+	Forks             int
+	VaultPasswordFile string
+}
+
+// ✅ Allow overriding the playbook runner for testing
+var executePlaybook = executor.Run
+
+// ✅ Run the playbooks in order, stopping at the first one that fails
+// Ansible's output goes to the process's stdout and stderr
+func Run(opts RunOptions) error {
+	if opts.Inventory == "" {
+		return errors.New("an inventory is required")
+	}
+	if len(opts.Playbooks) == 0 {
+		return errors.New("at least one playbook is required")
+	}
+	if err := executor.ValidateExtraVars(opts.ExtraVars); err != nil {
+		return err
+	}
+
+	for _, playbook := range opts.Playbooks {
+		err := executePlaybook(executor.Options{
+			Binary:            opts.Binary,
+			Inventory:         opts.Inventory,
+			Playbook:          playbook,
+			ExtraVars:         opts.ExtraVars,
+			DryRun:            opts.DryRun,
+			Tags:              opts.Tags,
+			Limit:             opts.Limit,
+			Verbosity:         opts.Verbosity,
+			Forks:             opts.Forks,
+			VaultPasswordFile: opts.VaultPasswordFile,
+			Become:            opts.Become,
+			BecomeMethod:      opts.BecomeMethod,
+			BecomeUser:        opts.BecomeUser,
+		})
+		if err != nil {
+			return fmt.Errorf("playbook %s: %w", playbook, err)
+		}
+	}
+	return nil
+}
+
+// ✅ Write an inventory for hosts into directory and return its path
+// The file is named inv.yml, numbered when that name is taken; `~` in SSH
+// key paths is expanded
+func CreateInventory(directory string, hosts []Host) (string, error) {
+	return inventory.CreateInventoryFile(directory, hosts)
+}
+
+// ✅ Find running instances from every installed provider without prompting
+// Instances from working providers are returned even when another fails
+func Discover() ([]Instance, error) {
+	return inventory.DiscoverAllInstances()
+}
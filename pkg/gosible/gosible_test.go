@@ -0,0 +1,128 @@
+package gosible
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bxtal-lsn/gosible/internal/executor"
+)
+
+// ✅ Replace the playbook runner, recording every call
+func mockExecutor(t *testing.T, result func(executor.Options) error) *[]executor.Options {
+	t.Helper()
+	var calls []executor.Options
+	old := executePlaybook
+	executePlaybook = func(opts executor.Options) error {
+		calls = append(calls, opts)
+		return result(opts)
+	}
+	t.Cleanup(func() { executePlaybook = old })
+	return &calls
+}
+
+// ✅ Test that Run executes each playbook with the given options
+func TestRun(t *testing.T) {
+	calls := mockExecutor(t, func(executor.Options) error { return nil })
+
+	err := Run(RunOptions{
+		Inventory: "inv.yml",
+		Playbooks: []string{"site.yml", "db.yml"},
+		DryRun:    true,
+		Limit:     "web",
+		ExtraVars: []string{"version=2"},
+		Become:    true,
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	expected := []executor.Options{
+		{Inventory: "inv.yml", Playbook: "site.yml", DryRun: true, Limit: "web", ExtraVars: []string{"version=2"}, Become: true},
+		{Inventory: "inv.yml", Playbook: "db.yml", DryRun: true, Limit: "web", ExtraVars: []string{"version=2"}, Become: true},
+	}
+	if !reflect.DeepEqual(*calls, expected) {
+		t.Errorf("Expected calls %+v, got %+v", expected, *calls)
+	}
+}
+
+// ✅ Test that Run stops at the first failing playbook and rejects bad input
+func TestRun_Errors(t *testing.T) {
+	failure := errors.New("exit status 2")
+	calls := mockExecutor(t, func(executor.Options) error { return failure })
+
+	err := Run(RunOptions{Inventory: "inv.yml", Playbooks: []string{"site.yml", "db.yml"}})
+	if !errors.Is(err, failure) || len(*calls) != 1 {
+		t.Errorf("Expected to stop after the first failure, got %v and calls %+v", err, *calls)
+	}
+
+	for _, opts := range []RunOptions{
+		{Playbooks: []string{"site.yml"}},
+		{Inventory: "inv.yml"},
+		{Inventory: "inv.yml", Playbooks: []string{"site.yml"}, ExtraVars: []string{"novalue"}},
+	} {
+		if err := Run(opts); err == nil {
+			t.Errorf("Expected an error for %+v", opts)
+		}
+	}
+	if len(*calls) != 1 {
+		t.Errorf("Expected invalid options not to run anything, got %+v", *calls)
+	}
+}
+
+// ✅ Test that CreateInventory writes the hosts
+func TestCreateInventory(t *testing.T) {
+	path, err := CreateInventory(t.TempDir(), []Host{{Host: "web1", Group: "web", SSHUser: "ubuntu"}})
+	if err != nil {
+		t.Fatalf("CreateInventory returned error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "---\nall:\n  hosts:\n\n  children:\n    web:\n      hosts:\n        web1:\n          ansible_user: ubuntu\n"
+	if filepath.Base(path) != "inv.yml" || string(data) != expected {
+		t.Errorf("Expected inv.yml with:\n%s\ngot %s with:\n%s", expected, path, data)
+	}
+}
+
+// ✅ captureLogger records every message it receives
+type captureLogger struct {
+	messages []string
+}
+
+func (c *captureLogger) Info(format string, args ...any) {
+	c.messages = append(c.messages, fmt.Sprintf(format, args...))
+}
+
+func (c *captureLogger) Warn(format string, args ...any) {
+	c.messages = append(c.messages, fmt.Sprintf(format, args...))
+}
+
+func (c *captureLogger) Error(format string, args ...any) {
+	c.messages = append(c.messages, fmt.Sprintf(format, args...))
+}
+
+// ✅ Test that a logger set through the public API receives Run's messages
+func TestSetLogger(t *testing.T) {
+	binary, err := exec.LookPath("true")
+	if err != nil {
+		t.Skip("true is not available")
+	}
+	var capture captureLogger
+	var logger Logger = &capture
+	SetLogger(logger)
+	defer SetLogger(nil)
+
+	if err := Run(RunOptions{Inventory: "inv.yml", Playbooks: []string{"site.yml"}, Binary: binary}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if len(capture.messages) == 0 || !strings.Contains(capture.messages[0], "site.yml") {
+		t.Errorf("Expected the command to be logged, got %q", capture.messages)
+	}
+}